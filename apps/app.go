@@ -5,6 +5,7 @@ package apps
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/mattermost/mattermost/server/public/model"
 )
@@ -29,6 +30,21 @@ type App struct {
 	// WebhookSecret is used to validate an incoming webhook secret.
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 
+	// PreviousWebhookSecret is the webhook secret replaced by the most recent
+	// rotation. It is still accepted until PreviousWebhookSecretExpiresAt (Unix
+	// time in milliseconds), so that remote systems can be reconfigured
+	// without dropping events.
+	PreviousWebhookSecret          string `json:"previous_webhook_secret,omitempty"`
+	PreviousWebhookSecretExpiresAt int64  `json:"previous_webhook_secret_expires_at,omitempty"`
+
+	// WebhookAllowlist restricts the source IP addresses of the remote webhook
+	// requests to the App.
+	WebhookAllowlist *RemoteWebhookAllowlist `json:"webhook_allowlist,omitempty"`
+
+	// TLS configures the connections to the App accessed over HTTP, e.g. for
+	// mutual TLS. It does not include the client key.
+	TLS *TLSConfig `json:"tls,omitempty"`
+
 	// App's Mattermost Bot User credentials. An Mattermost server Bot Account
 	// is created (or updated) when a Mattermost App is installed on the
 	// instance.
@@ -70,6 +86,20 @@ func DecodeCompatibleApp(data []byte) (app *App, err error) {
 	return app, nil
 }
 
+// ValidWebhookSecrets returns the webhook secrets that are accepted at the
+// time: the current one, and the previous one if it is still within its grace
+// period.
+func (app *App) ValidWebhookSecrets(now time.Time) []string {
+	var secrets []string
+	if app.WebhookSecret != "" {
+		secrets = append(secrets, app.WebhookSecret)
+	}
+	if app.PreviousWebhookSecret != "" && now.Before(time.UnixMilli(app.PreviousWebhookSecretExpiresAt)) {
+		secrets = append(secrets, app.PreviousWebhookSecret)
+	}
+	return secrets
+}
+
 func (app *App) Strip(level ExpandLevel) *App {
 	switch level {
 	case ExpandSummary:
@@ -0,0 +1,27 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidWebhookSecrets(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	app := App{
+		WebhookSecret:                  "new",
+		PreviousWebhookSecret:          "old",
+		PreviousWebhookSecretExpiresAt: now.Add(time.Hour).UnixMilli(),
+	}
+	require.Equal(t, []string{"new", "old"}, app.ValidWebhookSecrets(now))
+	require.Equal(t, []string{"new"}, app.ValidWebhookSecrets(now.Add(2*time.Hour)))
+
+	app.PreviousWebhookSecret = ""
+	require.Equal(t, []string{"new"}, app.ValidWebhookSecrets(now))
+
+	require.Empty(t, (&App{}).ValidWebhookSecrets(now))
+}
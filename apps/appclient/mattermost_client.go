@@ -97,6 +97,19 @@ func (c *Client) Subscribe(sub *apps.Subscription) error {
 	return nil
 }
 
+func (c *Client) InvalidateBindings(userID string) error {
+	res, err := c.ClientPP.InvalidateBindings(userID)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return errors.Errorf("returned with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
 func (c *Client) GetSubscriptions() ([]apps.Subscription, error) {
 	subs, res, err := c.ClientPP.GetSubscriptions()
 	if err != nil {
@@ -1,6 +1,7 @@
 package appclient
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -197,6 +198,29 @@ func (c *ClientPP) GetOAuth2User(ref interface{}) (*model.Response, error) {
 	return model.BuildResponse(r), nil
 }
 
+// InvalidateBindingsRequest is the input of the bindings invalidate API.
+type InvalidateBindingsRequest struct {
+	// UserID is the user whose cached bindings are invalidated, all users if
+	// empty.
+	UserID string `json:"user_id,omitempty"`
+}
+
+// InvalidateBindings removes the App's cached bindings for a user, or for all
+// users if userID is empty, and refreshes them in the user agents.
+func (c *ClientPP) InvalidateBindings(userID string) (*model.Response, error) {
+	data, err := json.Marshal(InvalidateBindingsRequest{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.DoAPIPOST(c.apipath(appspath.InvalidateBindings), string(data)) // nolint:bodyclose
+	if err != nil {
+		return model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	return model.BuildResponse(r), nil
+}
+
 type UpdateAppListingRequest struct {
 	// Manifest is the new app manifest to list.
 	apps.Manifest
@@ -261,6 +285,139 @@ func (c *ClientPP) InstallApp(appID apps.AppID, deployType apps.DeployType) (*ap
 	return &app, model.BuildResponse(r), nil
 }
 
+type RotateWebhookSecretRequest struct {
+	AppID apps.AppID `json:"app_id"`
+
+	// GracePeriodSeconds is how long the previous webhook secret remains
+	// valid. If not set, the server default (24 hours) is used; 0 revokes the
+	// previous secret immediately.
+	GracePeriodSeconds *int `json:"grace_period_seconds,omitempty"`
+}
+
+// RotateWebhookSecret generates a new webhook secret for an App, and returns
+// the updated App record.
+func (c *ClientPP) RotateWebhookSecret(req RotateWebhookSecretRequest) (*apps.App, *model.Response, error) {
+	b, err := json.Marshal(&req)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := c.DoAPIPOST(c.apipath(appspath.RotateWebhookSecret), string(b)) // nolint:bodyclose
+	if err != nil {
+		return nil, model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	var app apps.App
+	err = json.NewDecoder(r.Body).Decode(&app)
+	if err != nil {
+		return nil, model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+	return &app, model.BuildResponse(r), nil
+}
+
+type RotateJWTSigningKeyRequest struct {
+	// Algorithm is the signing method of the key, apps.JWTSigningMethodRS256
+	// or apps.JWTSigningMethodEdDSA.
+	Algorithm string `json:"algorithm"`
+}
+
+type RotateJWTSigningKeyResponse struct {
+	KeyID string `json:"kid"`
+}
+
+// RotateJWTSigningKey generates a new key for signing the outgoing JWTs, and
+// returns its ID.
+func (c *ClientPP) RotateJWTSigningKey(alg string) (string, *model.Response, error) {
+	b, err := json.Marshal(RotateJWTSigningKeyRequest{Algorithm: alg})
+	if err != nil {
+		return "", nil, err
+	}
+	r, err := c.DoAPIPOST(c.apipath(appspath.RotateJWTSigningKey), string(b)) // nolint:bodyclose
+	if err != nil {
+		return "", model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	var resp RotateJWTSigningKeyResponse
+	err = json.NewDecoder(r.Body).Decode(&resp)
+	if err != nil {
+		return "", model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+	return resp.KeyID, model.BuildResponse(r), nil
+}
+
+// GetJWKS returns the public keys that the outgoing JWTs are signed with.
+func (c *ClientPP) GetJWKS() (*apps.JSONWebKeySet, *model.Response, error) {
+	r, err := c.DoAPIGET(c.apipath(appspath.JWKS), "") // nolint:bodyclose
+	if err != nil {
+		return nil, model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	var set apps.JSONWebKeySet
+	err = json.NewDecoder(r.Body).Decode(&set)
+	if err != nil {
+		return nil, model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+	return &set, model.BuildResponse(r), nil
+}
+
+// SetAppTLS replaces the TLS configuration for the connections to an App, e.g.
+// the client certificate for mutual TLS. An empty configuration removes it.
+func (c *ClientPP) SetAppTLS(appID apps.AppID, tlsConfig apps.TLSConfig) (*model.Response, error) {
+	b, err := json.Marshal(apps.App{
+		Manifest: apps.Manifest{
+			AppID: appID,
+		},
+		TLS: &tlsConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.DoAPIPOST(c.apipath(appspath.AppTLS), string(b)) // nolint:bodyclose
+	if err != nil {
+		return model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	return model.BuildResponse(r), nil
+}
+
+// SetWebhookAllowlist replaces the source IP allowlist for an App's remote
+// webhooks. An empty allowlist removes the restriction.
+func (c *ClientPP) SetWebhookAllowlist(appID apps.AppID, allowlist apps.RemoteWebhookAllowlist) (*model.Response, error) {
+	b, err := json.Marshal(apps.App{
+		Manifest: apps.Manifest{
+			AppID: appID,
+		},
+		WebhookAllowlist: &allowlist,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.DoAPIPOST(c.apipath(appspath.WebhookAllowlist), string(b)) // nolint:bodyclose
+	if err != nil {
+		return model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	return model.BuildResponse(r), nil
+}
+
+// StoreWasmModule uploads the WebAssembly module for a version of a wasm App.
+func (c *ClientPP) StoreWasmModule(appID apps.AppID, version apps.AppVersion, data []byte) (*model.Response, error) {
+	v := url.Values{}
+	v.Add("app_id", string(appID))
+	v.Add("version", string(version))
+	r, err := c.doAPIRequestReader(http.MethodPost, c.URL+c.apipath(appspath.WasmModule)+"?"+v.Encode(), bytes.NewReader(data), "") // nolint:bodyclose
+	if err != nil {
+		return model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	return model.BuildResponse(r), nil
+}
+
 func (c *ClientPP) UninstallApp(appID apps.AppID) (*model.Response, error) {
 	b, err := json.Marshal(apps.Manifest{
 		AppID: appID,
@@ -325,6 +482,42 @@ func (c *ClientPP) DisableApp(appID apps.AppID) (*model.Response, error) {
 	return model.BuildResponse(r), nil
 }
 
+// GetWebhookDeliveries returns the most recent remote webhook deliveries for
+// an App, most recent first.
+func (c *ClientPP) GetWebhookDeliveries(appID apps.AppID) ([]apps.WebhookDelivery, *model.Response, error) {
+	v := url.Values{}
+	v.Add("app_id", string(appID))
+	r, err := c.DoAPIGET(c.apipath(appspath.WebhookDeliveries)+"?"+v.Encode(), "") // nolint:bodyclose
+	if err != nil {
+		return nil, model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	deliveries := []apps.WebhookDelivery{}
+	err = json.NewDecoder(r.Body).Decode(&deliveries)
+	if err != nil {
+		return nil, model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+	return deliveries, model.BuildResponse(r), nil
+}
+
+// GetAuditLog returns the audit log records matching the query, most recent
+// first.
+func (c *ClientPP) GetAuditLog(q apps.AuditQuery) ([]apps.AuditRecord, *model.Response, error) {
+	r, err := c.DoAPIGET(c.apipath(appspath.Audit)+"?"+q.Values().Encode(), "") // nolint:bodyclose
+	if err != nil {
+		return nil, model.BuildResponse(r), err
+	}
+	defer c.closeBody(r)
+
+	records := []apps.AuditRecord{}
+	err = json.NewDecoder(r.Body).Decode(&records)
+	if err != nil {
+		return nil, model.BuildResponse(r), errors.Wrap(err, "failed to decode response")
+	}
+	return records, model.BuildResponse(r), nil
+}
+
 func (c *ClientPP) GetListedApps(filter string, includePlugins bool) ([]apps.ListedApp, *model.Response, error) {
 	v := url.Values{}
 	v.Add("filter", filter)
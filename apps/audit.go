@@ -0,0 +1,147 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// AuditAction is the type of an administrative or privileged action recorded
+// in the audit log.
+type AuditAction string
+
+const (
+	AuditAppInstalled            AuditAction = "app_installed"
+	AuditAppUninstalled          AuditAction = "app_uninstalled"
+	AuditAppEnabled              AuditAction = "app_enabled"
+	AuditAppDisabled             AuditAction = "app_disabled"
+	AuditAppListingUpdated       AuditAction = "app_listing_updated"
+	AuditAppTLSUpdated           AuditAction = "app_tls_updated"
+	AuditPermissionsGranted      AuditAction = "permissions_granted"
+	AuditSessionCreated          AuditAction = "session_created"
+	AuditActingUserTokenExpanded AuditAction = "acting_user_token_expanded"
+	AuditJWTSigningKeyRotated    AuditAction = "jwt_signing_key_rotated"
+	AuditWebhookSecretRotated    AuditAction = "webhook_secret_rotated"
+	AuditWebhookAllowlistUpdated AuditAction = "webhook_allowlist_updated"
+	AuditWasmModuleStored        AuditAction = "wasm_module_stored"
+)
+
+// AuditRecord is an entry in the audit log: who did what to which App, and
+// when. Records are append-only, they are never modified once stored.
+type AuditRecord struct {
+	ID     string      `json:"id"`
+	Time   time.Time   `json:"time"`
+	Action AuditAction `json:"action"`
+	AppID  AppID       `json:"app_id,omitempty"`
+
+	// UserID is the acting user, PluginID the plugin that made the request on
+	// the user's behalf, if any.
+	UserID   string `json:"user_id,omitempty"`
+	PluginID string `json:"plugin_id,omitempty"`
+
+	// Details contains action-specific information, e.g. the granted
+	// permissions. It never contains secrets.
+	Details map[string]string `json:"details,omitempty"`
+}
+
+const (
+	DefaultAuditQueryLimit = 100
+	MaxAuditQueryLimit     = 10000
+)
+
+// AuditQuery selects records from the audit log. Empty fields match all
+// records. The results are returned most recent first.
+type AuditQuery struct {
+	AppID  AppID       `json:"app_id,omitempty"`
+	UserID string      `json:"user_id,omitempty"`
+	Action AuditAction `json:"action,omitempty"`
+	Since  time.Time   `json:"since,omitempty"`
+	Until  time.Time   `json:"until,omitempty"`
+
+	// Limit is the maximum number of records to return, DefaultAuditQueryLimit
+	// if 0, and no more than MaxAuditQueryLimit.
+	Limit int `json:"limit,omitempty"`
+}
+
+// Matches returns true if the record satisfies the query, ignoring the limit.
+func (q AuditQuery) Matches(rec AuditRecord) bool {
+	switch {
+	case q.AppID != "" && rec.AppID != q.AppID,
+		q.UserID != "" && rec.UserID != q.UserID,
+		q.Action != "" && rec.Action != q.Action,
+		!q.Since.IsZero() && rec.Time.Before(q.Since),
+		!q.Until.IsZero() && !rec.Time.Before(q.Until):
+		return false
+	}
+	return true
+}
+
+// EffectiveLimit returns the number of records the query is limited to.
+func (q AuditQuery) EffectiveLimit() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultAuditQueryLimit
+	case q.Limit > MaxAuditQueryLimit:
+		return MaxAuditQueryLimit
+	}
+	return q.Limit
+}
+
+// Values encodes the query as URL query parameters, with the times in RFC3339
+// format.
+func (q AuditQuery) Values() url.Values {
+	v := url.Values{}
+	if q.AppID != "" {
+		v.Set("app_id", string(q.AppID))
+	}
+	if q.UserID != "" {
+		v.Set("user_id", q.UserID)
+	}
+	if q.Action != "" {
+		v.Set("action", string(q.Action))
+	}
+	if !q.Since.IsZero() {
+		v.Set("since", q.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if !q.Until.IsZero() {
+		v.Set("until", q.Until.UTC().Format(time.RFC3339Nano))
+	}
+	if q.Limit != 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	return v
+}
+
+// ParseAuditQuery decodes a query from URL query parameters, see Values.
+func ParseAuditQuery(v url.Values) (AuditQuery, error) {
+	q := AuditQuery{
+		AppID:  AppID(v.Get("app_id")),
+		UserID: v.Get("user_id"),
+		Action: AuditAction(v.Get("action")),
+	}
+	var err error
+	if s := v.Get("since"); s != "" {
+		q.Since, err = time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return q, utils.NewInvalidError(err, "invalid since")
+		}
+	}
+	if s := v.Get("until"); s != "" {
+		q.Until, err = time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return q, utils.NewInvalidError(err, "invalid until")
+		}
+	}
+	if s := v.Get("limit"); s != "" {
+		q.Limit, err = strconv.Atoi(s)
+		if err != nil || q.Limit < 0 {
+			return q, utils.NewInvalidError("invalid limit %q", s)
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditQuery(t *testing.T) {
+	q := AuditQuery{
+		AppID:  "app1",
+		UserID: "user1",
+		Action: AuditAppInstalled,
+		Since:  time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		Until:  time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC),
+		Limit:  5,
+	}
+	parsed, err := ParseAuditQuery(q.Values())
+	require.NoError(t, err)
+	require.Equal(t, q, parsed)
+
+	parsed, err = ParseAuditQuery(url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, AuditQuery{}, parsed)
+	require.Equal(t, DefaultAuditQueryLimit, parsed.EffectiveLimit())
+	require.Equal(t, MaxAuditQueryLimit, AuditQuery{Limit: MaxAuditQueryLimit + 1}.EffectiveLimit())
+
+	for _, v := range []url.Values{
+		{"since": {"yesterday"}},
+		{"until": {"2023-07-01"}},
+		{"limit": {"-1"}},
+		{"limit": {"many"}},
+	} {
+		_, err = ParseAuditQuery(v)
+		require.Error(t, err, v.Encode())
+	}
+
+	rec := AuditRecord{
+		Time:   q.Since.Add(time.Hour),
+		Action: AuditAppInstalled,
+		AppID:  "app1",
+		UserID: "user1",
+	}
+	require.True(t, q.Matches(rec))
+	require.False(t, AuditQuery{AppID: "app2"}.Matches(rec))
+	require.False(t, AuditQuery{Since: rec.Time.Add(time.Second)}.Matches(rec))
+	require.False(t, AuditQuery{Until: rec.Time}.Matches(rec))
+}
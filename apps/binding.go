@@ -3,6 +3,10 @@
 
 package apps
 
+import (
+	"time"
+)
+
 // Binding is the principal way for an App to attach its functionality to the
 // Mattermost UI.  An App can bind to top-level UI elements by implementing the
 // (mandatory) "bindings" call. It can also add bindings to messages (posts) in
@@ -206,3 +210,39 @@ type Binding struct {
 	// Bindings specifies sub-location bindings.
 	Bindings []Binding `json:"bindings,omitempty"`
 }
+
+// BindingsStatus indicates how the App's bindings in a bindings response were
+// obtained.
+type BindingsStatus string
+
+const (
+	// BindingsStatusOK indicates that the bindings are current.
+	BindingsStatusOK BindingsStatus = "ok"
+
+	// BindingsStatusStale indicates that the bindings could not be fetched in
+	// time, and that the last good bindings were used instead.
+	BindingsStatusStale BindingsStatus = "stale"
+
+	// BindingsStatusFailed indicates that the bindings could not be fetched in
+	// time, and that none are available.
+	BindingsStatusFailed BindingsStatus = "failed"
+)
+
+// AppBindingsStatus reports the status of an App's bindings, so that the user
+// agent can indicate that the App is degraded.
+type AppBindingsStatus struct {
+	Status BindingsStatus `json:"status"`
+
+	// Error is the reason the bindings could not be fetched, if any.
+	Error string `json:"error,omitempty"`
+
+	// FetchedAt is when the stale bindings were last fetched from the App.
+	FetchedAt time.Time `json:"fetched_at,omitempty"`
+}
+
+// BindingsResponse contains the combined bindings of all Apps, and the status
+// of each App's bindings.
+type BindingsResponse struct {
+	Bindings []Binding                   `json:"bindings"`
+	Status   map[AppID]AppBindingsStatus `json:"status,omitempty"`
+}
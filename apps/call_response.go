@@ -23,8 +23,9 @@ const (
 	// modal.
 	CallResponseTypeForm CallResponseType = "form"
 
-	// CallResponseTypeCall indicates that another Call that should be executed
-	// (all the way from the user-agent). Call is returned. NOT YET IMPLEMENTED.
+	// CallResponseTypeCall indicates that another Call of the same App should
+	// be executed, Call is returned. The proxy follows the call, up to
+	// MaxChainedCalls times, and returns the final response to the user-agent.
 	CallResponseTypeCall CallResponseType = "call"
 
 	// CallResponseTypeNavigate indicates that the user should be forcefully
@@ -33,17 +34,28 @@ const (
 	CallResponseTypeNavigate CallResponseType = "navigate"
 )
 
+// MaxChainedCalls is the maximum number of "call" responses that are followed
+// in response to a single call from the user-agent.
+const MaxChainedCalls = 5
+
 // CallResponse is general envelope for all Call responses.
 //
 // Submit requests expect ok, error, form, call, or navigate response types.
 // Returning a "form" type in response to a submission from the user-agent
 // triggers displaying a Modal. Returning a "call" type in response to a
-// submission causes the call to be executed from the user-agent (NOT
-// IMPLEMENTED YET)
+// submission causes the proxy to make the returned call, and to return its
+// response instead. The chained call is made with the submitted values, the
+// Context is expanded again according to the chained call's Expand, and its
+// State is merged over the previous call's State (JSON object keys are merged,
+// any other State is replaced). Calls to the paths reserved for the proxy,
+// e.g. on_install, are rejected.
 //
-// Form requests expect form or error.
+// Form requests expect form, call, or error.
 //
-// Lookup requests expect ok or error.
+// Lookup requests expect ok, call, or error. The chained call of a lookup
+// receives the same SelectedField and Query, so an App can delegate a lookup
+// to a different path. Its response is returned as the lookup's result, and
+// is expected to be ok or error.
 //
 // In case of an error, the returned response type is "error", ErrorText
 // contains the overall error text. Data contains optional, field-level errors.
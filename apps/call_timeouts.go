@@ -0,0 +1,61 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// CallTimeouts declares how long Mattermost waits for the App to respond to
+// its calls, e.g. longer for a slow report submit, and shorter for bindings.
+// The timeouts are capped by the maximum configured by the system
+// administrator, 30 seconds by default. Calls with no declared timeout use the
+// default timeout of the App's deploy type.
+type CallTimeouts struct {
+	// DefaultSeconds applies to all calls that don't have a timeout in Paths.
+	DefaultSeconds int `json:"default_seconds,omitempty"`
+
+	// Paths maps call paths, e.g. "/bindings" or "/report/submit", to their
+	// timeouts in seconds. The paths must match exactly.
+	Paths map[string]int `json:"paths,omitempty"`
+}
+
+func (t *CallTimeouts) Validate() error {
+	if t == nil {
+		return nil
+	}
+	var result error
+	if t.DefaultSeconds < 0 {
+		result = multierror.Append(result,
+			utils.NewInvalidError("call_timeouts.default_seconds must not be negative"))
+	}
+	for path, seconds := range t.Paths {
+		if !strings.HasPrefix(path, "/") {
+			result = multierror.Append(result,
+				utils.NewInvalidError("call_timeouts.paths: path %q must start with a /", path))
+		}
+		if seconds <= 0 {
+			result = multierror.Append(result,
+				utils.NewInvalidError("call_timeouts.paths: timeout for %q must be positive", path))
+		}
+	}
+	return result
+}
+
+// Timeout returns the declared timeout for a call path, or 0 if none is
+// declared.
+func (t *CallTimeouts) Timeout(path string) time.Duration {
+	if t == nil {
+		return 0
+	}
+	if seconds, ok := t.Paths[path]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Duration(t.DefaultSeconds) * time.Second
+}
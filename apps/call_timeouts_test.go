@@ -0,0 +1,70 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestCallTimeoutsValidate(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		timeouts      *apps.CallTimeouts
+		expectedError string
+	}{
+		"nil": {},
+		"empty": {
+			timeouts: &apps.CallTimeouts{},
+		},
+		"valid": {
+			timeouts: &apps.CallTimeouts{DefaultSeconds: 10, Paths: map[string]int{"/bindings": 2}},
+		},
+		"negative default": {
+			timeouts:      &apps.CallTimeouts{DefaultSeconds: -1},
+			expectedError: "call_timeouts.default_seconds must not be negative",
+		},
+		"relative path": {
+			timeouts:      &apps.CallTimeouts{Paths: map[string]int{"bindings": 2}},
+			expectedError: `call_timeouts.paths: path "bindings" must start with a /`,
+		},
+		"zero path timeout": {
+			timeouts:      &apps.CallTimeouts{Paths: map[string]int{"/bindings": 0}},
+			expectedError: `call_timeouts.paths: timeout for "/bindings" must be positive`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.timeouts.Validate()
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestCallTimeoutsTimeout(t *testing.T) {
+	t.Parallel()
+
+	var none *apps.CallTimeouts
+	require.Equal(t, time.Duration(0), none.Timeout("/bindings"))
+
+	timeouts := &apps.CallTimeouts{
+		Paths: map[string]int{"/bindings": 2, "/report/submit": 25},
+	}
+	require.Equal(t, 2*time.Second, timeouts.Timeout("/bindings"))
+	require.Equal(t, 25*time.Second, timeouts.Timeout("/report/submit"))
+	require.Equal(t, time.Duration(0), timeouts.Timeout("/report"))
+
+	timeouts.DefaultSeconds = 10
+	require.Equal(t, 10*time.Second, timeouts.Timeout("/report"))
+	require.Equal(t, 2*time.Second, timeouts.Timeout("/bindings"))
+}
@@ -26,15 +26,43 @@ const (
 	// needed.
 	DeployBuiltin DeployType = "builtin"
 
+	// Local executable app, managed by the Apps plugin. All communications
+	// are done as JSON messages over the process' stdin and stdout. Only
+	// available if the server administrator has configured a directory for
+	// the executables. No authentication is needed.
+	DeployExec DeployType = "exec"
+
+	// gRPC-deployable app. All communications are done via the
+	// mattermost.apps.v1.App gRPC service, see upstream/upgrpc/app.proto.
+	// Mattermost authenticates to the App with an optional shared secret based
+	// JWT, passed in the request metadata.
+	DeployGRPC DeployType = "grpc"
+
 	// HTTP-deployable app. All communications are done via HTTP requests. Paths
 	// for both functions and static assets are appended to RootURL "as is".
 	// Mattermost authenticates to the App with an optional shared secret based
 	// JWT.
 	DeployHTTP DeployType = "http"
 
+	// Kubernetes-deployable app. Each function is a Kubernetes service in the
+	// cluster Mattermost runs in, accessed over HTTP using the path mapping
+	// provided in the app's manifest. Mattermost authenticates to the App with
+	// an optional Kubernetes service account token.
+	DeployKubernetes DeployType = "kubernetes"
+
 	// OpenFaaS-deployable app.
 	DeployOpenFAAS DeployType = "open_faas"
 
+	// WebAssembly module, run in-process by the Apps plugin. The module is
+	// instantiated for each request, and exchanges JSON messages with
+	// Mattermost over its stdin and stdout. No authentication is needed.
+	DeployWasm DeployType = "wasm"
+
+	// An App that connects to Mattermost over a persistent WebSocket. All
+	// communications are done over the connection, as JSON messages. The App
+	// authenticates to Mattermost with a JWT signed with the shared secret.
+	DeployWebSocket DeployType = "websocket"
+
 	// An App running as a plugin. All communications are done via inter-plugin HTTP requests.
 	// Authentication is done via the plugin.Context.SourcePluginId field.
 	DeployPlugin DeployType = "plugin"
@@ -43,9 +71,14 @@ const (
 var KnownDeployTypes = DeployTypes{
 	DeployAWSLambda,
 	DeployBuiltin,
+	DeployExec,
+	DeployGRPC,
 	DeployHTTP,
+	DeployKubernetes,
 	DeployOpenFAAS,
 	DeployPlugin,
+	DeployWasm,
+	DeployWebSocket,
 }
 
 // Deploy contains App's deployment data, only the fields supported by the App
@@ -56,25 +89,51 @@ type Deploy struct {
 	// `aws_lambda` must match the type.
 	AWSLambda *AWSLambda `json:"aws_lambda,omitempty"`
 
+	// Exec contains metadata for an app that runs as a local executable,
+	// managed by the plugin. The JSON name `exec` must match the type.
+	Exec *Exec `json:"exec,omitempty"`
+
+	// GRPC contains metadata for an app that is already deployed externally,
+	// and is accessed over gRPC. The JSON name `grpc` must match the type.
+	GRPC *GRPC `json:"grpc,omitempty"`
+
 	// HTTP contains metadata for an app that is already, deployed externally
 	// and us accessed over HTTP. The JSON name `http` must match the type.
 	HTTP *HTTP `json:"http,omitempty"`
 
+	// Kubernetes contains metadata for an app that is deployed as services in
+	// the Kubernetes cluster Mattermost runs in. The JSON name `kubernetes`
+	// must match the type.
+	Kubernetes *Kubernetes `json:"kubernetes,omitempty"`
+
 	OpenFAAS *OpenFAAS `json:"open_faas,omitempty"`
 
 	// Plugin contains metadata for an app that is implemented and is deployed
 	// and accessed as a local Plugin. The JSON name `plugin` must match the
 	// type.
 	Plugin *Plugin `json:"plugin,omitempty"`
+
+	// Wasm contains metadata for an app that is a WebAssembly module, run
+	// in-process by the plugin. The JSON name `wasm` must match the type.
+	Wasm *Wasm `json:"wasm,omitempty"`
+
+	// WebSocket contains metadata for an app that connects to Mattermost over
+	// a persistent WebSocket. The JSON name `websocket` must match the type.
+	WebSocket *WebSocket `json:"websocket,omitempty"`
 }
 
 func (t DeployType) Validate() error {
 	switch t {
 	case DeployAWSLambda,
 		DeployBuiltin,
+		DeployExec,
+		DeployGRPC,
 		DeployHTTP,
+		DeployKubernetes,
 		DeployOpenFAAS,
-		DeployPlugin:
+		DeployPlugin,
+		DeployWasm,
+		DeployWebSocket:
 		return nil
 	default:
 		return utils.NewInvalidError("%s is not a valid app type", t)
@@ -87,12 +146,22 @@ func (t DeployType) String() string {
 		return "AWS Lambda"
 	case DeployBuiltin:
 		return "Built-in"
+	case DeployExec:
+		return "Local executable"
+	case DeployGRPC:
+		return "gRPC"
 	case DeployHTTP:
 		return "HTTP"
+	case DeployKubernetes:
+		return "Kubernetes"
 	case DeployOpenFAAS:
 		return "OpenFaaS"
 	case DeployPlugin:
 		return "Mattermost Plugin"
+	case DeployWasm:
+		return "WebAssembly"
+	case DeployWebSocket:
+		return "WebSocket"
 	default:
 		return string(t)
 	}
@@ -111,18 +180,28 @@ func (d Deploy) Validate() error {
 	var result error
 
 	if d.AWSLambda == nil &&
+		d.Exec == nil &&
+		d.GRPC == nil &&
 		d.HTTP == nil &&
+		d.Kubernetes == nil &&
 		d.OpenFAAS == nil &&
-		d.Plugin == nil {
+		d.Plugin == nil &&
+		d.Wasm == nil &&
+		d.WebSocket == nil {
 		result = multierror.Append(result,
-			utils.NewInvalidError("manifest has no deployment information (http, aws_lambda, open_faas, etc.)"))
+			utils.NewInvalidError("manifest has no deployment information (http, grpc, aws_lambda, kubernetes, open_faas, etc.)"))
 	}
 
 	for _, v := range []validator{
 		d.AWSLambda,
+		d.Exec,
+		d.GRPC,
 		d.HTTP,
+		d.Kubernetes,
 		d.OpenFAAS,
 		d.Plugin,
+		d.Wasm,
+		d.WebSocket,
 	} {
 		// Validate must ignore nil pointer in its implementation, v is never
 		// nil (interface wrapper).
@@ -145,15 +224,30 @@ func (d Deploy) DeployTypes() (out []DeployType) {
 	if d.AWSLambda != nil {
 		out = append(out, DeployAWSLambda)
 	}
+	if d.Exec != nil {
+		out = append(out, DeployExec)
+	}
+	if d.GRPC != nil {
+		out = append(out, DeployGRPC)
+	}
 	if d.HTTP != nil {
 		out = append(out, DeployHTTP)
 	}
+	if d.Kubernetes != nil {
+		out = append(out, DeployKubernetes)
+	}
 	if d.OpenFAAS != nil {
 		out = append(out, DeployOpenFAAS)
 	}
 	if d.Plugin != nil {
 		out = append(out, DeployPlugin)
 	}
+	if d.Wasm != nil {
+		out = append(out, DeployWasm)
+	}
+	if d.WebSocket != nil {
+		out = append(out, DeployWebSocket)
+	}
 	return out
 }
 
@@ -161,12 +255,22 @@ func (d Deploy) Contains(dtype DeployType) bool {
 	switch dtype {
 	case DeployAWSLambda:
 		return d.AWSLambda != nil
+	case DeployExec:
+		return d.Exec != nil
+	case DeployGRPC:
+		return d.GRPC != nil
 	case DeployHTTP:
 		return d.HTTP != nil
+	case DeployKubernetes:
+		return d.Kubernetes != nil
 	case DeployOpenFAAS:
 		return d.OpenFAAS != nil
 	case DeployPlugin:
 		return d.Plugin != nil
+	case DeployWasm:
+		return d.Wasm != nil
+	case DeployWebSocket:
+		return d.WebSocket != nil
 	}
 	return false
 }
@@ -175,11 +279,21 @@ func (d *Deploy) CopyType(src Deploy, typ DeployType) {
 	switch typ {
 	case DeployAWSLambda:
 		d.AWSLambda = src.AWSLambda
+	case DeployExec:
+		d.Exec = src.Exec
+	case DeployGRPC:
+		d.GRPC = src.GRPC
 	case DeployHTTP:
 		d.HTTP = src.HTTP
+	case DeployKubernetes:
+		d.Kubernetes = src.Kubernetes
 	case DeployOpenFAAS:
 		d.OpenFAAS = src.OpenFAAS
 	case DeployPlugin:
 		d.Plugin = src.Plugin
+	case DeployWasm:
+		d.Wasm = src.Wasm
+	case DeployWebSocket:
+		d.WebSocket = src.WebSocket
 	}
 }
@@ -4,6 +4,8 @@
 package apps
 
 import (
+	"regexp"
+
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
@@ -15,8 +17,18 @@ import (
 // `aws_lambda` must match the type.
 type AWSLambda struct {
 	Functions []AWSLambdaFunction `json:"functions,omitempty"`
+
+	// Alias is the name of the AWS Lambda alias to invoke the functions with,
+	// pinning the published function versions that serve this version of the
+	// app. If empty, the unpublished ($LATEST) functions are invoked.
+	// `appsctl aws deploy` publishes a new version of each function and moves
+	// the alias to it, `appsctl aws rollback` moves it back.
+	Alias string `json:"alias,omitempty"`
 }
 
+var lambdaAliasRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,128}$`)
+var lambdaVersionRegexp = regexp.MustCompile(`^[0-9]+$`)
+
 func (a *AWSLambda) Validate() error {
 	if a == nil {
 		return nil
@@ -26,6 +38,10 @@ func (a *AWSLambda) Validate() error {
 		result = multierror.Append(result,
 			utils.NewInvalidError("must provide at least 1 function in aws_lambda.Functions"))
 	}
+	if a.Alias != "" && (!lambdaAliasRegexp.MatchString(a.Alias) || lambdaVersionRegexp.MatchString(a.Alias)) {
+		result = multierror.Append(result,
+			utils.NewInvalidError("invalid aws_lambda alias %q: must be up to 128 letters, digits, '-' or '_', and not a number", a.Alias))
+	}
 	for _, f := range a.Functions {
 		err := f.Validate()
 		if err != nil {
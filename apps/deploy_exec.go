@@ -0,0 +1,58 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const DefaultExecTimeout = 30 * time.Second
+
+// Exec contains metadata for an app that runs as a local executable, managed by
+// the Apps plugin. The executable is spawned on first use, and exchanges
+// CallRequest and CallResponse messages with Mattermost as JSON over its stdin
+// and stdout, see upstream/upexec for the protocol. The JSON name `exec` must
+// match the type.
+//
+// Exec apps are only available if the server administrator has configured a
+// directory for their executables, see upexec.EnvExecDir.
+type Exec struct {
+	// Command is the file name of the executable, in the configured exec apps
+	// directory. It must not contain path separators.
+	Command string `json:"command"`
+
+	// Args are the command line arguments to pass to the executable.
+	Args []string `json:"args,omitempty"`
+
+	// TimeoutSeconds is the maximum time to wait for a response from the app.
+	// A request that times out fails on its own. The app is then pinged, and
+	// restarted if it does not respond to the ping. The default is 30 seconds.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+func (e *Exec) Validate() error {
+	if e == nil {
+		return nil
+	}
+	if e.Command == "" {
+		return utils.NewInvalidError("command must be set for exec apps")
+	}
+	if strings.ContainsAny(e.Command, `/\`) || e.Command == "." || e.Command == ".." {
+		return utils.NewInvalidError("invalid command %q: must be a file name, with no path", e.Command)
+	}
+	if e.TimeoutSeconds < 0 {
+		return utils.NewInvalidError("timeout_seconds must not be negative")
+	}
+	return nil
+}
+
+func (e Exec) Timeout() time.Duration {
+	if e.TimeoutSeconds == 0 {
+		return DefaultExecTimeout
+	}
+	return time.Duration(e.TimeoutSeconds) * time.Second
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"net"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// GRPC contains metadata for an app that is deployed externally, and is
+// accessed over gRPC. The app must implement the mattermost.apps.v1.App
+// service, see upstream/upgrpc/app.proto. The JSON name `grpc` must match the
+// type.
+type GRPC struct {
+	// Address is the "host:port" of the app's gRPC server.
+	Address string `json:"address,omitempty"`
+
+	// TLS instructs the proxy to connect to the app using TLS, verifying its
+	// certificate against the system's root CAs.
+	TLS bool `json:"tls,omitempty"`
+
+	// TLSServerName overrides the server name used to verify the app's
+	// certificate, it defaults to the host part of Address.
+	TLSServerName string `json:"tls_server_name,omitempty"`
+
+	// UseToken instructs the proxy to authenticate outgoing requests with a JWT
+	// signed with the app's secret, the same way as for HTTP apps. The token is
+	// sent in the "mattermost-app-authorization" request metadata.
+	UseToken bool `json:"use_token,omitempty"`
+}
+
+func (g *GRPC) Validate() error {
+	if g == nil {
+		return nil
+	}
+	if g.Address == "" {
+		return utils.NewInvalidError("address must be set for gRPC apps")
+	}
+	if _, _, err := net.SplitHostPort(g.Address); err != nil {
+		return utils.NewInvalidError("invalid address: %q: %v", g.Address, err)
+	}
+	if g.TLSServerName != "" && !g.TLS {
+		return utils.NewInvalidError("tls_server_name requires tls to be set")
+	}
+	return nil
+}
@@ -16,8 +16,48 @@ type HTTP struct {
 	// All call and static paths are relative to the RootURL.
 	RootURL string `json:"root_url,omitempty"`
 
+	// RootURLs are the additional root URLs of the App, e.g. of its replicas.
+	// Each request is sent to one of RootURL and RootURLs, selected with
+	// RootURLStrategy. The root URLs that fail are skipped for a while.
+	RootURLs []string `json:"root_urls,omitempty"`
+
+	// RootURLStrategy is RootURLStrategyRoundRobin (the default), or
+	// RootURLStrategyPrimary.
+	RootURLStrategy string `json:"root_url_strategy,omitempty"`
+
 	// UseJWT instructs the proxy to authenticate outgoing requests with a JWT.
 	UseJWT bool `json:"use_jwt,omitempty"`
+
+	// JWTSigningMethod is the algorithm the JWTs are signed with, if UseJWT
+	// is set. The default, HS256, uses the app's secret. With RS256 or EdDSA,
+	// the JWTs are signed with a key held by the Mattermost server, and the
+	// app needs no secret.
+	JWTSigningMethod string `json:"jwt_signing_method,omitempty"`
+}
+
+const (
+	// RootURLStrategyRoundRobin distributes the requests between all the
+	// healthy root URLs.
+	RootURLStrategyRoundRobin = "round_robin"
+
+	// RootURLStrategyPrimary sends the requests to the first healthy root URL,
+	// in the order listed: RootURL, then RootURLs.
+	RootURLStrategyPrimary = "primary"
+)
+
+// AllRootURLs returns RootURL, followed by RootURLs.
+func (h *HTTP) AllRootURLs() []string {
+	if h == nil {
+		return nil
+	}
+	return append([]string{h.RootURL}, h.RootURLs...)
+}
+
+// UsesAppSecret returns true if the outgoing JWTs are signed with the app's
+// secret.
+func (h *HTTP) UsesAppSecret() bool {
+	return h != nil && h.UseJWT &&
+		(h.JWTSigningMethod == "" || h.JWTSigningMethod == JWTSigningMethodHS256)
 }
 
 func (h *HTTP) Validate() error {
@@ -31,12 +71,54 @@ func (h *HTTP) Validate() error {
 	if err != nil {
 		return utils.NewInvalidError("invalid root_url: %q: %v", h.RootURL, err)
 	}
+	seen := map[string]bool{h.RootURL: true}
+	for _, rootURL := range h.RootURLs {
+		if err = httputils.IsValidURL(rootURL); err != nil {
+			return utils.NewInvalidError("invalid root_urls: %q: %v", rootURL, err)
+		}
+		if seen[rootURL] {
+			return utils.NewInvalidError("duplicate root URL: %q", rootURL)
+		}
+		seen[rootURL] = true
+	}
+	switch h.RootURLStrategy {
+	case "", RootURLStrategyRoundRobin, RootURLStrategyPrimary:
+	default:
+		return utils.NewInvalidError("invalid root_url_strategy: %q, must be %s or %s",
+			h.RootURLStrategy, RootURLStrategyRoundRobin, RootURLStrategyPrimary)
+	}
+	switch h.JWTSigningMethod {
+	case "", JWTSigningMethodHS256, JWTSigningMethodRS256, JWTSigningMethodEdDSA:
+	default:
+		return utils.NewInvalidError("invalid jwt_signing_method: %q, must be one of %s, %s, %s",
+			h.JWTSigningMethod, JWTSigningMethodHS256, JWTSigningMethodRS256, JWTSigningMethodEdDSA)
+	}
+	if h.JWTSigningMethod != "" && !h.UseJWT {
+		return utils.NewInvalidError("jwt_signing_method requires use_jwt")
+	}
 	return nil
 }
 
 const OutgoingAuthHeader = "Mattermost-App-Authorization"
 
+// JWTClaims are the claims of the JWTs sent to the HTTP apps, see
+// HTTP.UseJWT. The audience ("aud") is the AppID, and the issuer ("iss") of
+// the JWTs signed with the server-held keys is the Mattermost site URL.
 type JWTClaims struct {
 	jwt.StandardClaims
 	ActingUserID string `json:"acting_user_id,omitempty"`
 }
+
+// Verify checks that the JWT was issued by the Mattermost server at siteURL,
+// for the app. The apps that verify JWTs signed with the server-held keys must
+// call it after the signature is verified, a JWT sent to another app is
+// otherwise accepted.
+func (c JWTClaims) Verify(appID AppID, siteURL string) error {
+	if !c.VerifyAudience(string(appID), true) {
+		return utils.NewUnauthorizedError("JWT audience %q does not match %s", c.Audience, appID)
+	}
+	if !c.VerifyIssuer(siteURL, true) {
+		return utils.NewUnauthorizedError("JWT issuer %q does not match %s", c.Issuer, siteURL)
+	}
+	return nil
+}
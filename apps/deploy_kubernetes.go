@@ -0,0 +1,98 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const DefaultKubernetesPort = 8080
+
+// kubernetesNamespaceRegexp matches a valid Kubernetes namespace (RFC 1123
+// label).
+var kubernetesNamespaceRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// Kubernetes contains metadata for an app that is deployed as services in the
+// Kubernetes cluster Mattermost runs in, and is accessed over HTTP. Each
+// function is a separate Kubernetes service, calls are routed to them by path
+// the same way as for OpenFaaS. The JSON name `kubernetes` must match the
+// type.
+//
+// cmd/appsctl generates the Kubernetes manifests (a Deployment and a Service
+// for each function), and applies them to the cluster.
+type Kubernetes struct {
+	// Namespace is the Kubernetes namespace of the app's services. The default
+	// is the namespace Mattermost runs in.
+	Namespace string `json:"namespace,omitempty"`
+
+	// UseServiceAccountToken causes Mattermost to send a token of its
+	// Kubernetes service account with every request, in the Authorization
+	// header. The token is bound to the "mattermost-app:<app_id>" audience,
+	// the app must authenticate Mattermost with the TokenReview API, and check
+	// the audience.
+	UseServiceAccountToken bool `json:"use_service_account_token,omitempty"`
+
+	Functions []KubernetesFunction `json:"functions,omitempty"`
+}
+
+func (k *Kubernetes) Validate() error {
+	if k == nil {
+		return nil
+	}
+	if k.Namespace != "" && !kubernetesNamespaceRegexp.MatchString(k.Namespace) {
+		return utils.NewInvalidError("invalid namespace %q", k.Namespace)
+	}
+	if len(k.Functions) == 0 {
+		return utils.NewInvalidError("must provide at least 1 function")
+	}
+	for _, kf := range k.Functions {
+		err := kf.Validate()
+		if err != nil {
+			return errors.Wrapf(err, "invalid function %q", kf.Name)
+		}
+	}
+	return nil
+}
+
+// KubernetesFunction defines a mapping of call paths to a Kubernetes service.
+type KubernetesFunction struct {
+	// Path is used to match/map incoming Call requests.
+	Path string `json:"path"`
+
+	// Name is the "short" name of the function, it is combined with the app's
+	// ID+Version to make the Kubernetes service name, see
+	// upkubernetes.ServiceName.
+	Name string `json:"name"`
+
+	// Image is the container image that implements the function. It is only
+	// used by appsctl to generate the Kubernetes manifests.
+	Image string `json:"image,omitempty"`
+
+	// Port is the port the function's service listens on, default 8080.
+	Port int32 `json:"port,omitempty"`
+}
+
+func (kf KubernetesFunction) Validate() error {
+	if kf.Path == "" {
+		return utils.NewInvalidError("invalid Kubernetes function: path must not be empty")
+	}
+	if kf.Name == "" {
+		return utils.NewInvalidError("invalid Kubernetes function: name must not be empty")
+	}
+	if kf.Port < 0 || kf.Port > 65535 {
+		return utils.NewInvalidError("invalid Kubernetes function: port must be between 0 and 65535")
+	}
+	return nil
+}
+
+func (kf KubernetesFunction) ServicePort() int32 {
+	if kf.Port == 0 {
+		return DefaultKubernetesPort
+	}
+	return kf.Port
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const (
+	DefaultWasmTimeout       = 5 * time.Second
+	DefaultWasmMemoryLimitMB = 64
+	MaxWasmMemoryLimitMB     = 1024
+)
+
+// Wasm contains metadata for an app that is a WebAssembly module, run
+// in-process by the Apps plugin in a sandbox. The module must be a WASI
+// command, it is instantiated for each request, reads the request as JSON from
+// its stdin and writes the response as JSON to its stdout, see upstream/upwasm
+// for the protocol. The JSON name `wasm` must match the type.
+//
+// The module has no access to the network or the file system.
+type Wasm struct {
+	// Module is the path of the WebAssembly module in the app bundle, e.g.
+	// "app.wasm". It is uploaded to Mattermost when the app is deployed.
+	Module string `json:"module"`
+
+	// MemoryLimitMB is the maximum memory the module can use while processing
+	// a request. The default is 64MB. It is capped by the administrator's
+	// limit, also 64MB by default.
+	MemoryLimitMB int `json:"memory_limit_mb,omitempty"`
+
+	// TimeoutSeconds is the maximum (wall-clock) time the module can run
+	// while processing a request. The default is 5 seconds. It is capped by
+	// the administrator's maximum call timeout.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+func (w *Wasm) Validate() error {
+	if w == nil {
+		return nil
+	}
+	if w.Module == "" {
+		return utils.NewInvalidError("module must be set for wasm apps")
+	}
+	if path.IsAbs(w.Module) || strings.HasPrefix(path.Clean(w.Module), "..") {
+		return utils.NewInvalidError("invalid module %q: must be a relative path in the app bundle", w.Module)
+	}
+	if w.MemoryLimitMB < 0 || w.MemoryLimitMB > MaxWasmMemoryLimitMB {
+		return utils.NewInvalidError("memory_limit_mb must be between 0 and %v", MaxWasmMemoryLimitMB)
+	}
+	if w.TimeoutSeconds < 0 {
+		return utils.NewInvalidError("timeout_seconds must not be negative")
+	}
+	return nil
+}
+
+func (w Wasm) Timeout() time.Duration {
+	if w.TimeoutSeconds == 0 {
+		return DefaultWasmTimeout
+	}
+	return time.Duration(w.TimeoutSeconds) * time.Second
+}
+
+func (w Wasm) MemoryLimit() int {
+	if w.MemoryLimitMB == 0 {
+		return DefaultWasmMemoryLimitMB
+	}
+	return w.MemoryLimitMB
+}
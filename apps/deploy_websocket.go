@@ -0,0 +1,51 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const DefaultWebSocketTimeout = 30 * time.Second
+
+// WebSocket contains metadata for an app that opens a persistent WebSocket
+// connection to Mattermost, rather than accepting connections from it. It is
+// intended for apps in networks that only allow outbound connections. The JSON
+// name `websocket` must match the type.
+//
+// The app connects to "{PluginURL}/apps/{AppID}/connect", authenticating with
+// a JWT signed (HS256) with the app's secret, in the
+// "Mattermost-App-Authorization" header as "Bearer {token}". The token must have
+// the app's ID as the issuer ("iss"), and an expiration time ("exp") no more
+// than 10 minutes in the future. Once connected, Mattermost sends requests to
+// the app over the connection, see upstream.StreamRequest. If the app
+// reconnects, the new connection replaces the old one.
+//
+// The connection is local to the Mattermost server the app connected to. In a
+// cluster, the requests to the app made on the other servers are forwarded to
+// that server with plugin cluster events.
+type WebSocket struct {
+	// TimeoutSeconds is the maximum time to wait for a response from the app.
+	// The default is 30 seconds.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+func (ws *WebSocket) Validate() error {
+	if ws == nil {
+		return nil
+	}
+	if ws.TimeoutSeconds < 0 {
+		return utils.NewInvalidError("timeout_seconds must not be negative")
+	}
+	return nil
+}
+
+func (ws WebSocket) Timeout() time.Duration {
+	if ws.TimeoutSeconds == 0 {
+		return DefaultWebSocketTimeout
+	}
+	return time.Duration(ws.TimeoutSeconds) * time.Second
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// JWT signing methods for the outgoing requests to HTTP apps, see
+// HTTP.JWTSigningMethod.
+const (
+	// JWTSigningMethodHS256 signs with the app's secret, shared by Mattermost
+	// and the app. It is the default.
+	JWTSigningMethodHS256 = "HS256"
+
+	// JWTSigningMethodRS256 and JWTSigningMethodEdDSA sign with a private key
+	// held by the Mattermost server. The apps verify the JWTs with the public
+	// keys published at {PluginURL}/api/v1/jwks.json, selected by the "kid"
+	// JWT header. The keys are shared by all apps, so an app must also check
+	// that the "aud" claim is its AppID, and the "iss" claim is the Mattermost
+	// site URL, see JWTClaims.Verify.
+	JWTSigningMethodRS256 = "RS256"
+	JWTSigningMethodEdDSA = "EdDSA"
+)
+
+// SigningMethodEdDSA implements the Ed25519 EdDSA signing method for
+// github.com/dgrijalva/jwt-go, that does not support it natively. Importing
+// the apps package registers it.
+var SigningMethodEdDSA jwt.SigningMethod = signingMethodEdDSA{}
+
+type signingMethodEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(JWTSigningMethodEdDSA, func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (signingMethodEdDSA) Alg() string {
+	return JWTSigningMethodEdDSA
+}
+
+func (signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
+
+func (signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+// JSONWebKey is a public key in the JWK format (RFC 7517).
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use,omitempty"`
+
+	// RSA public key.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519 public key.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is the set of the public keys that the Mattermost server signs
+// the outgoing JWTs with, served at {PluginURL}/api/v1/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewJSONWebKey returns the JWK for an RSA or an Ed25519 public key.
+func NewJSONWebKey(kid string, publicKey crypto.PublicKey) (*JSONWebKey, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return &JSONWebKey{
+			KeyType:   "RSA",
+			KeyID:     kid,
+			Algorithm: JWTSigningMethodRS256,
+			Use:       "sig",
+			N:         base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return &JSONWebKey{
+			KeyType:   "OKP",
+			KeyID:     kid,
+			Algorithm: JWTSigningMethodEdDSA,
+			Use:       "sig",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(k),
+		}, nil
+	default:
+		return nil, utils.NewInvalidError("unsupported public key type %T", publicKey)
+	}
+}
+
+// PublicKey returns the key, *rsa.PublicKey or ed25519.PublicKey, to verify
+// the JWTs with.
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid RSA modulus")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid RSA exponent")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, utils.NewInvalidError("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid Ed25519 key")
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, utils.NewInvalidError("invalid Ed25519 key size %v", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, utils.NewInvalidError("unsupported key type %q", k.KeyType)
+	}
+}
+
+// Keyfunc returns a jwt.Keyfunc that selects the key to verify a JWT with by
+// its "kid" header.
+func (s JSONWebKeySet) Keyfunc() jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, k := range s.Keys {
+			if k.KeyID != kid {
+				continue
+			}
+			if token.Method.Alg() != k.Algorithm {
+				return nil, errors.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+			}
+			return k.PublicKey()
+		}
+		return nil, utils.NewNotFoundError("no key %q in the key set", kid)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestJSONWebKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPublicKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaJWK, err := apps.NewJSONWebKey("rsa1", &rsaKey.PublicKey)
+	require.NoError(t, err)
+	edJWK, err := apps.NewJSONWebKey("ed1", edPublicKey)
+	require.NoError(t, err)
+	require.Equal(t, "OKP", edJWK.KeyType)
+	require.Equal(t, "Ed25519", edJWK.Curve)
+
+	// Round-trip the key set through JSON, as the apps would receive it.
+	data, err := json.Marshal(apps.JSONWebKeySet{Keys: []apps.JSONWebKey{*rsaJWK, *edJWK}})
+	require.NoError(t, err)
+	set := apps.JSONWebKeySet{}
+	require.NoError(t, json.Unmarshal(data, &set))
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, apps.JWTClaims{
+			StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()},
+			ActingUserID:   "user1",
+		})
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+
+	for name, tc := range map[string]struct {
+		token         string
+		expectedError string
+	}{
+		"RS256": {
+			token: sign(jwt.SigningMethodRS256, "rsa1", rsaKey),
+		},
+		"EdDSA": {
+			token: sign(apps.SigningMethodEdDSA, "ed1", edKey),
+		},
+		"unknown kid": {
+			token:         sign(apps.SigningMethodEdDSA, "ed2", edKey),
+			expectedError: `no key "ed2" in the key set: not found`,
+		},
+		"algorithm mismatch": {
+			token:         sign(apps.SigningMethodEdDSA, "rsa1", edKey),
+			expectedError: "unexpected signing method EdDSA for key rsa1",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			claims := apps.JWTClaims{}
+			_, err := jwt.ParseWithClaims(tc.token, &claims, set.Keyfunc())
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "user1", claims.ActingUserID)
+		})
+	}
+}
@@ -77,6 +77,13 @@ type Manifest struct {
 	//  "path":"/bindings",
 	Bindings *Call `json:"bindings,omitempty"`
 
+	// BindingsCacheSeconds allows Mattermost to cache the App's bindings for
+	// each user and context (team, channel, location, user agent), for up to
+	// this many seconds. 0 (default) disables caching. The cache is
+	// invalidated when a call response has "refresh_bindings" set, and when
+	// the App calls the bindings invalidate API.
+	BindingsCacheSeconds int `json:"bindings_cache_seconds,omitempty"`
+
 	// OnInstall gets invoked when a sysadmin installs the App with a `/apps
 	// install` command. It may return another call to the app, or a form to
 	// display. It is not called unless explicitly provided in the manifest.
@@ -130,11 +137,28 @@ type Manifest struct {
 	// systems should be authenticated by Mattermost.
 	RemoteWebhookAuthType RemoteWebhookAuthType `json:"remote_webhook_auth_type,omitempty"`
 
+	// RemoteWebhookReplayProtection, if set, requires incoming webhook
+	// messages from remote systems to be signed and timestamped, and rejects
+	// replayed messages. It requires the "secret" RemoteWebhookAuthType.
+	RemoteWebhookReplayProtection *RemoteWebhookReplayProtection `json:"remote_webhook_replay_protection,omitempty"`
+
+	// RemoteWebhookSynchronous, if set, makes Mattermost wait for the App to
+	// respond to OnRemoteWebhook, and send the response back to the remote
+	// system, e.g. to complete URL verification handshakes. To control the
+	// HTTP status code, headers, and body the App should return an "ok"
+	// response with an HTTPCallResponse in Data. Otherwise, Data is sent as
+	// JSON, or Text as plain text.
+	RemoteWebhookSynchronous bool `json:"remote_webhook_synchronous,omitempty"`
+
 	// RequestedLocations is the list of top-level locations that the
 	// application intends to bind to, e.g. `{"/post_menu", "/channel_header",
 	// "/command/apptrigger"}``.
 	RequestedLocations Locations `json:"requested_locations,omitempty"`
 
+	// CallTimeouts optionally declares the timeouts of the App's calls, by
+	// default and per path.
+	CallTimeouts *CallTimeouts `json:"call_timeouts,omitempty"`
+
 	// Deployment information
 	Deploy
 }
@@ -186,10 +210,26 @@ func (m Manifest) Validate() error {
 		}
 	}
 
+	if m.BindingsCacheSeconds < 0 {
+		result = multierror.Append(result,
+			utils.NewInvalidError("bindings_cache_seconds must not be negative"))
+	}
+
+	if m.RemoteWebhookReplayProtection != nil {
+		if m.RemoteWebhookAuthType != "" && m.RemoteWebhookAuthType != SecretAuth {
+			result = multierror.Append(result,
+				utils.NewInvalidError("remote_webhook_replay_protection requires remote_webhook_auth_type %q", SecretAuth))
+		}
+		if err := m.RemoteWebhookReplayProtection.Validate(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
 	for _, v := range []validator{
 		m.AppID,
 		m.Version,
 		m.RequestedPermissions,
+		m.CallTimeouts,
 		m.Deploy,
 	} {
 		if v != nil {
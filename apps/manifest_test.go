@@ -134,6 +134,141 @@ func TestValidateManifest(t *testing.T) {
 			},
 			ExpectedError: false,
 		},
+		"HTTP app with server-signed JWTs": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL:          "https://example.org/root",
+						UseJWT:           true,
+						JWTSigningMethod: apps.JWTSigningMethodEdDSA,
+					},
+				},
+			},
+			ExpectedError: false,
+		},
+		"call timeouts": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				CallTimeouts: &apps.CallTimeouts{
+					DefaultSeconds: 10,
+					Paths:          map[string]int{"/bindings": 2, "/report/submit": 25},
+				},
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+			},
+			ExpectedError: false,
+		},
+		"negative bindings cache seconds": {
+			Manifest: apps.Manifest{
+				AppID:                "abc",
+				DisplayName:          "some display name",
+				HomepageURL:          "https://example.org",
+				BindingsCacheSeconds: -1,
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"call timeouts invalid": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				CallTimeouts: &apps.CallTimeouts{
+					Paths: map[string]int{"bindings": 2},
+				},
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL: "https://example.org/root",
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"HTTP JWTSigningMethod invalid": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL:          "https://example.org/root",
+						UseJWT:           true,
+						JWTSigningMethod: "ES256",
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"HTTP JWTSigningMethod without UseJWT": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL:          "https://example.org/root",
+						JWTSigningMethod: apps.JWTSigningMethodRS256,
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"HTTP RootURLs": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL:         "https://a.example.org/root",
+						RootURLs:        []string{"https://b.example.org/root"},
+						RootURLStrategy: apps.RootURLStrategyPrimary,
+					},
+				},
+			},
+			ExpectedError: false,
+		},
+		"HTTP RootURLs duplicate": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL:  "https://a.example.org/root",
+						RootURLs: []string{"https://a.example.org/root"},
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"HTTP RootURLStrategy invalid": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					HTTP: &apps.HTTP{
+						RootURL:         "https://a.example.org/root",
+						RootURLs:        []string{"https://b.example.org/root"},
+						RootURLStrategy: "random",
+					},
+				},
+			},
+			ExpectedError: true,
+		},
 		"invalid Icon": {
 			Manifest: apps.Manifest{
 				AppID:       "abc",
@@ -236,6 +371,63 @@ func TestValidateManifest(t *testing.T) {
 			},
 			ExpectedError: true,
 		},
+		"AWS app with alias": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					AWSLambda: &apps.AWSLambda{
+						Functions: []apps.AWSLambdaFunction{{
+							Path:    "/",
+							Name:    "go-funcion",
+							Handler: "hello-lambda",
+							Runtime: "go1.x",
+						}},
+						Alias: "live",
+					},
+				},
+			},
+			ExpectedError: false,
+		},
+		"invalid alias for AWS app": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					AWSLambda: &apps.AWSLambda{
+						Functions: []apps.AWSLambdaFunction{{
+							Path:    "/",
+							Name:    "go-funcion",
+							Handler: "hello-lambda",
+							Runtime: "go1.x",
+						}},
+						Alias: "live.1",
+					},
+				},
+			},
+			ExpectedError: true,
+		},
+		"numeric alias for AWS app": {
+			Manifest: apps.Manifest{
+				AppID:       "abc",
+				DisplayName: "some display name",
+				HomepageURL: "https://example.org",
+				Deploy: apps.Deploy{
+					AWSLambda: &apps.AWSLambda{
+						Functions: []apps.AWSLambdaFunction{{
+							Path:    "/",
+							Name:    "go-funcion",
+							Handler: "hello-lambda",
+							Runtime: "go1.x",
+						}},
+						Alias: "12",
+					},
+				},
+			},
+			ExpectedError: true,
+		},
 		"missing runtime for AWS app": {
 			Manifest: apps.Manifest{
 				AppID:       "abc",
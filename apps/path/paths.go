@@ -9,14 +9,19 @@ const (
 	// User-agent ping
 	Ping = "/ping"
 
+	// JWKS is the public key set for verifying the JWTs in the outgoing
+	// requests to apps, see apps.HTTP.JWTSigningMethod.
+	JWKS = "/jwks.json"
+
 	// Services for Apps.
-	KV                = "/kv"
-	OAuth2App         = "/oauth2/app"
-	OAuth2CreateState = "/oauth2/create-state"
-	OAuth2User        = "/oauth2/user"
-	Subscribe         = "/subscribe"
-	Unsubscribe       = "/unsubscribe"
-	TimerCreate       = "/timer"
+	KV                 = "/kv"
+	OAuth2App          = "/oauth2/app"
+	OAuth2CreateState  = "/oauth2/create-state"
+	OAuth2User         = "/oauth2/user"
+	Subscribe          = "/subscribe"
+	Unsubscribe        = "/unsubscribe"
+	TimerCreate        = "/timer"
+	InvalidateBindings = "/bindings/invalidate"
 
 	// Invoke.
 	Call = "/call"
@@ -28,6 +33,17 @@ const (
 	UninstallApp     = "/uninstall-app"
 	UpdateAppListing = "/update-app-listing"
 
+	RotateJWTSigningKey = "/rotate-jwt-signing-key"
+	RotateWebhookSecret = "/rotate-webhook-secret"
+	WebhookAllowlist    = "/webhook-allowlist"
+	WasmModule          = "/wasm-module"
+	AppTLS              = "/app-tls"
+
+	// Troubleshooting.
+	Audit             = "/audit"
+	WebhookDeliveries = "/webhook-deliveries"
+	Metrics           = "/metrics"
+
 	// Marketplace and local manifest store.
 	Marketplace = "/marketplace"
 
@@ -54,6 +70,10 @@ const (
 
 	Bindings = "/bindings"
 
+	// Apps deployed as type "websocket" connect to
+	// "{PluginURL}/apps/{AppID}/connect".
+	Connect = "/connect"
+
 	// Static assets are served from {PluginURL}/static/...
 	StaticFolder = "static"
 	Static       = "/" + StaticFolder
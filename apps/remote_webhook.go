@@ -0,0 +1,177 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+const (
+	DefaultRemoteWebhookTimestampHeader = "Mattermost-Webhook-Timestamp"
+	DefaultRemoteWebhookSignatureHeader = "Mattermost-Webhook-Signature"
+	DefaultRemoteWebhookReplayWindow    = 5 * time.Minute
+)
+
+// RemoteWebhookReplayProtection configures the protection of the App's remote
+// webhook endpoint against replayed requests. When it is set, each incoming
+// webhook request must carry a timestamp, and a signature of the timestamp and
+// the body, keyed with the App's WebhookSecret. Requests with a timestamp
+// outside of the window, or with a signature (or ID) already seen within the
+// window are rejected.
+//
+// The signature is the hex-encoded HMAC-SHA256 of "{timestamp}.{body}", see
+// SignRemoteWebhook.
+type RemoteWebhookReplayProtection struct {
+	// TimestampHeader is the name of the header that contains the time the
+	// request was sent at, as a Unix timestamp in seconds. The default is
+	// "Mattermost-Webhook-Timestamp".
+	TimestampHeader string `json:"timestamp_header,omitempty"`
+
+	// SignatureHeader is the name of the header that contains the signature
+	// of the request. The default is "Mattermost-Webhook-Signature".
+	SignatureHeader string `json:"signature_header,omitempty"`
+
+	// IDHeader is the optional name of the header that contains a unique ID of
+	// the request. If set, the ID is used to detect duplicate requests, in
+	// addition to the signature since the ID is not signed.
+	IDHeader string `json:"id_header,omitempty"`
+
+	// WindowSeconds is the maximum allowed difference between the request
+	// timestamp and the server time. The default is 300 seconds.
+	WindowSeconds int `json:"window_seconds,omitempty"`
+}
+
+func (rp RemoteWebhookReplayProtection) Validate() error {
+	var result error
+	if rp.WindowSeconds < 0 {
+		result = multierror.Append(result,
+			utils.NewInvalidError("remote_webhook_replay_protection: window_seconds must not be negative"))
+	}
+	if rp.TimestampHeader != "" && rp.TimestampHeader == rp.SignatureHeader {
+		result = multierror.Append(result,
+			utils.NewInvalidError("remote_webhook_replay_protection: timestamp and signature headers must be different"))
+	}
+	return result
+}
+
+func (rp RemoteWebhookReplayProtection) GetTimestampHeader() string {
+	if rp.TimestampHeader == "" {
+		return DefaultRemoteWebhookTimestampHeader
+	}
+	return http.CanonicalHeaderKey(rp.TimestampHeader)
+}
+
+func (rp RemoteWebhookReplayProtection) GetSignatureHeader() string {
+	if rp.SignatureHeader == "" {
+		return DefaultRemoteWebhookSignatureHeader
+	}
+	return http.CanonicalHeaderKey(rp.SignatureHeader)
+}
+
+func (rp RemoteWebhookReplayProtection) GetIDHeader() string {
+	if rp.IDHeader == "" {
+		return ""
+	}
+	return http.CanonicalHeaderKey(rp.IDHeader)
+}
+
+func (rp RemoteWebhookReplayProtection) Window() time.Duration {
+	if rp.WindowSeconds == 0 {
+		return DefaultRemoteWebhookReplayWindow
+	}
+	return time.Duration(rp.WindowSeconds) * time.Second
+}
+
+// SignRemoteWebhook returns the signature expected in the SignatureHeader of a
+// remote webhook request, for apps that use RemoteWebhookReplayProtection.
+func SignRemoteWebhook(secret string, timestamp int64, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "." + body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDelivery is a record of an incoming remote webhook request, kept by
+// Mattermost for troubleshooting. Secret values are redacted from the headers
+// and the query.
+type WebhookDelivery struct {
+	Time     time.Time         `json:"time"`
+	AppID    AppID             `json:"app_id"`
+	Path     string            `json:"path"`
+	Method   string            `json:"method"`
+	Query    string            `json:"query,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	BodySize int               `json:"body_size"`
+
+	// Authenticated is set if the request passed the webhook authentication,
+	// and replay protection checks. AuthError is set if it failed them.
+	Authenticated bool   `json:"authenticated"`
+	AuthError     string `json:"auth_error,omitempty"`
+
+	// StatusCode is the HTTP status that was sent back to the remote system.
+	// Error is set if the request failed for reasons other than
+	// authentication, e.g. the App is disabled, or did not respond.
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+	Elapsed    string `json:"elapsed"`
+}
+
+// RemoteWebhookAllowlist restricts the source IP addresses that are allowed to
+// send remote webhook requests to an App. It is configured by the system
+// administrator.
+type RemoteWebhookAllowlist struct {
+	// CIDRs is the list of IP ranges (or individual addresses) allowed to send
+	// webhook requests to the App. An empty list allows any address.
+	CIDRs []string `json:"cidrs,omitempty"`
+
+	// Paths overrides CIDRs for specific webhook paths, relative to
+	// "/webhook", e.g. "github" for "{PluginURL}/apps/{AppID}/webhook/github".
+	// An empty list allows any address to send to the path.
+	Paths map[string][]string `json:"paths,omitempty"`
+}
+
+func (l RemoteWebhookAllowlist) Validate() error {
+	var result error
+	if _, err := httputils.ParseCIDRs(l.CIDRs); err != nil {
+		result = multierror.Append(result, utils.NewInvalidError(err))
+	}
+	for p, cidrs := range l.Paths {
+		if _, err := httputils.ParseCIDRs(cidrs); err != nil {
+			result = multierror.Append(result, utils.NewInvalidError(err, "path %q", p))
+		}
+	}
+	return result
+}
+
+// IsEmpty returns true if the allowlist does not restrict any path.
+func (l RemoteWebhookAllowlist) IsEmpty() bool {
+	return len(l.CIDRs) == 0 && len(l.Paths) == 0
+}
+
+// Allows returns true if ip is allowed to send webhook requests to the path.
+func (l RemoteWebhookAllowlist) Allows(webhookPath string, ip net.IP) (bool, error) {
+	cidrs := l.CIDRs
+	if pathCIDRs, ok := l.Paths[strings.Trim(webhookPath, "/")]; ok {
+		cidrs = pathCIDRs
+	}
+	if len(cidrs) == 0 {
+		return true, nil
+	}
+	ranges, err := httputils.ParseCIDRs(cidrs)
+	if err != nil {
+		return false, err
+	}
+	return httputils.ContainsIP(ranges, ip), nil
+}
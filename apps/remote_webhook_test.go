@@ -0,0 +1,42 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteWebhookAllowlist(t *testing.T) {
+	l := RemoteWebhookAllowlist{
+		CIDRs: []string{"192.30.252.0/22"},
+		Paths: map[string][]string{
+			"internal": {"10.0.0.1"},
+			"open":     {},
+		},
+	}
+	require.NoError(t, l.Validate())
+
+	for _, tc := range []struct {
+		path     string
+		ip       string
+		expected bool
+	}{
+		{"", "192.30.253.1", true},
+		{"", "10.0.0.1", false},
+		{"github", "192.30.253.1", true},
+		{"/internal/", "10.0.0.1", true},
+		{"internal", "192.30.253.1", false},
+		{"open", "203.0.113.1", true},
+	} {
+		allowed, err := l.Allows(tc.path, net.ParseIP(tc.ip))
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, allowed, "%s from %s", tc.path, tc.ip)
+	}
+
+	require.Error(t, RemoteWebhookAllowlist{CIDRs: []string{"bad"}}.Validate())
+	require.Error(t, RemoteWebhookAllowlist{Paths: map[string][]string{"x": {"10.0.0.0/99"}}}.Validate())
+}
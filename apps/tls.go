@@ -0,0 +1,70 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package apps
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// TLSConfig configures the TLS connections to an App accessed over HTTP, e.g.
+// for mutual TLS. It is set by the system administrators, not in the
+// manifest.
+type TLSConfig struct {
+	// ClientCertificate and ClientKey are the PEM-encoded certificate chain and
+	// private key that Mattermost presents to the App. The key is stored
+	// separately from the App record, it is never set in App.TLS.
+	ClientCertificate string `json:"client_certificate,omitempty"`
+	ClientKey         string `json:"client_key,omitempty"`
+
+	// RootCAs is the PEM-encoded bundle of the CA certificates that the App's
+	// server certificate is verified with, instead of the system ones.
+	RootCAs string `json:"root_cas,omitempty"`
+
+	// ServerName is the name expected in the App's server certificate, if it
+	// differs from the host in the App's URL.
+	ServerName string `json:"server_name,omitempty"`
+}
+
+func (c *TLSConfig) IsEmpty() bool {
+	return c == nil || *c == TLSConfig{}
+}
+
+func (c *TLSConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	_, err := c.ClientTLSConfig()
+	return err
+}
+
+// ClientTLSConfig returns the tls.Config to connect to the App with.
+func (c *TLSConfig) ClientTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+
+	switch {
+	case c.ClientCertificate != "" && c.ClientKey != "":
+		cert, err := tls.X509KeyPair([]byte(c.ClientCertificate), []byte(c.ClientKey))
+		if err != nil {
+			return nil, utils.NewInvalidError(err, "invalid client certificate or key")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case c.ClientCertificate != "" || c.ClientKey != "":
+		return nil, utils.NewInvalidError("client_certificate and client_key must be set together")
+	}
+
+	if c.RootCAs != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.RootCAs)) {
+			return nil, utils.NewInvalidError("root_cas contains no valid PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
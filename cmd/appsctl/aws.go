@@ -16,8 +16,15 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
+var awsEndpoints upaws.Endpoints
+
 func init() {
 	rootCmd.AddCommand(awsCmd)
+	envEndpoints := upaws.EndpointsFromEnv()
+	awsCmd.PersistentFlags().StringVar(&awsEndpoints.S3, "s3-endpoint", envEndpoints.S3, "S3 endpoint URL, to use an S3-compatible service such as MinIO or LocalStack. Defaults to "+upaws.S3EndpointEnvVar+".")
+	awsCmd.PersistentFlags().BoolVar(&awsEndpoints.S3ForcePathStyle, "s3-path-style", envEndpoints.S3ForcePathStyle, "Use path-style S3 addressing (endpoint/bucket/key). Defaults to "+upaws.S3ForcePathStyleEnvVar+".")
+	awsCmd.PersistentFlags().StringVar(&awsEndpoints.Lambda, "lambda-endpoint", envEndpoints.Lambda, "Lambda endpoint URL, to use a Lambda-compatible service such as LocalStack. Defaults to "+upaws.LambdaEndpointEnvVar+".")
+	awsCmd.PersistentFlags().StringVar(&awsEndpoints.IAM, "iam-endpoint", envEndpoints.IAM, "IAM endpoint URL, to use an IAM-compatible service such as LocalStack. Defaults to "+upaws.IAMEndpointEnvVar+".")
 
 	// init
 	awsCmd.AddCommand(awsInitCmd)
@@ -34,6 +41,12 @@ func init() {
 	awsDeployCmd.Flags().StringVar(&invokePolicyName, "policy", upaws.DefaultPolicyName, "name of the policy used to invoke Apps on AWS.")
 	awsDeployCmd.Flags().StringVar(&executeRoleName, "execute-role", upaws.DefaultExecuteRoleName, "name of the role to be assumed by running Lambdas.")
 	awsDeployCmd.Flags().StringToStringVar(&environment, "env", nil, "environment variables to pass to the App")
+	awsDeployCmd.Flags().StringVar(&lambdaAlias, "alias", "", "Lambda alias to move to the newly published function versions, defaults to the alias in manifest.json, or \""+upaws.DefaultLambdaAlias+"\".")
+
+	// rollback
+	awsCmd.AddCommand(awsRollbackCmd)
+	awsRollbackCmd.Flags().StringVar(&lambdaAlias, "alias", "", "Lambda alias to move back, defaults to the alias in the deployed manifest.json.")
+	awsRollbackCmd.Flags().StringVar(&lambdaToVersion, "to-version", "", "Lambda function version to point the alias at, defaults to the one published before the current.")
 
 	// clean
 	awsCmd.AddCommand(awsCleanCmd)
@@ -149,6 +162,7 @@ var awsDeployCmd = &cobra.Command{
 			ExecuteRoleName:  upaws.Name(executeRoleName),
 			ShouldUpdate:     shouldUpdate,
 			Environment:      environment,
+			Alias:            lambdaAlias,
 		})
 		if err != nil {
 			return err
@@ -162,6 +176,12 @@ var awsDeployCmd = &cobra.Command{
 		fmt.Printf("Created/updated %v functions in AWS Lambda, %v static assets in S3\n\n",
 			len(out.LambdaARNs), len(out.StaticARNs))
 
+		fmt.Printf("Published versions, alias %q:\n", out.LambdaAlias)
+		for name, version := range out.LambdaVersions {
+			fmt.Printf("\t%s:\t%s\n", name, version)
+		}
+		fmt.Printf("\n")
+
 		fmt.Printf("Execute role:\t%s\n", out.ExecuteRoleARN)
 		fmt.Printf("Execute policy:\t%s\n", out.ExecutePolicyARN)
 		fmt.Printf("Invoke policy:\t%s\n\n", out.InvokePolicyARN)
@@ -176,6 +196,35 @@ var awsDeployCmd = &cobra.Command{
 	},
 }
 
+var awsRollbackCmd = &cobra.Command{
+	Use:   "rollback app_id version",
+	Short: "Point the Lambda alias of an app back at the previous function versions",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asDeploy, err := makeDeployAWSClient()
+		if err != nil {
+			return err
+		}
+
+		out, err := upaws.RollbackApp(asDeploy, log, upaws.RollbackAppParams{
+			Bucket:    upaws.S3BucketName(),
+			AppID:     apps.AppID(args[0]),
+			Version:   apps.AppVersion(args[1]),
+			Alias:     lambdaAlias,
+			ToVersion: lambdaToVersion,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Rolled back alias %q:\n", out.Alias)
+		for name, f := range out.Functions {
+			fmt.Printf("\t%s:\t%s -> %s\n", name, f.From, f.To)
+		}
+		return nil
+	},
+}
+
 var awsTestCmd = &cobra.Command{
 	Use:   "test",
 	Short: "test accessing a deployed resource",
@@ -349,7 +398,7 @@ func makeTestAWSUpstream() (*upaws.Upstream, error) {
 		return nil, errors.Errorf("no AWS secret key was provided. Please set %s", upaws.SecretEnvVar)
 	}
 
-	return upaws.MakeUpstream(accessKey, secretKey, region, upaws.S3BucketName(), log)
+	return upaws.MakeUpstream(accessKey, secretKey, region, upaws.S3BucketName(), awsEndpoints, log)
 }
 
 func makeDeployAWSClient() (upaws.Client, error) {
@@ -366,6 +415,6 @@ func makeDeployAWSClient() (upaws.Client, error) {
 		return nil, errors.Errorf("no AWS secret key was provided. Please set %s", upaws.DeploySecretEnvVar)
 	}
 
-	return upaws.MakeClient(accessKey, secretKey, region,
+	return upaws.MakeClient(accessKey, secretKey, region, awsEndpoints,
 		log.With("purpose", "appsctl deploy"))
 }
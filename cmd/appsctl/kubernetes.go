@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upkubernetes"
+)
+
+var (
+	kubernetesNamespace string
+	kubernetesOutput    string
+)
+
+func init() {
+	rootCmd.AddCommand(kubernetesCmd)
+
+	// deploy
+	kubernetesCmd.AddCommand(kubernetesDeployCmd)
+	kubernetesDeployCmd.Flags().BoolVar(&install, "install", false, "Install the deployed App to Mattermost")
+	kubernetesDeployCmd.Flags().StringVar(&dockerRegistry, "docker-registry", "", "Docker image prefix, usually the docker registry to use for the functions' images.")
+	kubernetesDeployCmd.Flags().StringVar(&kubernetesNamespace, "namespace", "", "Kubernetes namespace to deploy to, overrides the one in manifest.json.")
+
+	// generate
+	kubernetesCmd.AddCommand(kubernetesGenerateCmd)
+	kubernetesGenerateCmd.Flags().StringVar(&dockerRegistry, "docker-registry", "", "Docker image prefix, usually the docker registry to use for the functions' images.")
+	kubernetesGenerateCmd.Flags().StringVar(&kubernetesNamespace, "namespace", "", "Kubernetes namespace to deploy to, overrides the one in manifest.json.")
+	kubernetesGenerateCmd.Flags().StringVarP(&kubernetesOutput, "output", "o", "", "File to write the manifests to, default stdout.")
+}
+
+var kubernetesCmd = &cobra.Command{
+	Use:   "kubernetes",
+	Short: "Deploy Mattermost Apps to the Kubernetes cluster Mattermost runs in",
+}
+
+var kubernetesDeployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy a Mattermost app to Kubernetes, using kubectl",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bundlePath := args[0]
+
+		appClient, err := getMattermostClient()
+		if err != nil {
+			return err
+		}
+
+		m, err := upkubernetes.DeployApp(bundlePath, log, kubernetesNamespace, dockerRegistry)
+		if err != nil {
+			return err
+		}
+		if kubernetesNamespace != "" {
+			m.Kubernetes.Namespace = kubernetesNamespace
+		}
+
+		if err = updateMattermost(appClient, *m, apps.DeployKubernetes, install); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nDeployed '%s' to Kubernetes.\n", m.DisplayName)
+
+		if !install {
+			fmt.Printf("You can now install it in Mattermost using:\n")
+			fmt.Printf("  /apps install listed %s\n\n", m.AppID)
+		}
+		return nil
+	},
+}
+
+var kubernetesGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate the Kubernetes manifests for a Mattermost app",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, dir, err := upstream.GetAppBundle(args[0], log)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		data, err := upkubernetes.GenerateManifests(*m, kubernetesNamespace, dockerRegistry)
+		if err != nil {
+			return err
+		}
+
+		if kubernetesOutput == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err = os.WriteFile(kubernetesOutput, data, 0600); err != nil {
+			return errors.Wrap(err, "failed to write the manifests")
+		}
+		log.Infof("Wrote the Kubernetes manifests for %s to %s.", m.AppID, kubernetesOutput)
+		return nil
+	},
+}
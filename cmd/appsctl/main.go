@@ -27,6 +27,8 @@ var (
 	groupName             string
 	install               bool
 	invokePolicyName      string
+	lambdaAlias           string
+	lambdaToVersion       string
 	policyName            string
 	shouldCreate          bool
 	shouldCreateAccessKey bool
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+var (
+	tlsClientCertFile string
+	tlsClientKeyFile  string
+	tlsRootCAsFile    string
+	tlsServerName     string
+)
+
+func init() {
+	rootCmd.AddCommand(tlsCmd)
+
+	// set
+	tlsCmd.AddCommand(tlsSetCmd)
+	tlsSetCmd.Flags().StringVar(&tlsClientCertFile, "client-cert", "", "PEM file with the client certificate chain to present to the App")
+	tlsSetCmd.Flags().StringVar(&tlsClientKeyFile, "client-key", "", "PEM file with the private key of the client certificate")
+	tlsSetCmd.Flags().StringVar(&tlsRootCAsFile, "ca", "", "PEM file with the CA certificates to verify the App's server certificate with")
+	tlsSetCmd.Flags().StringVar(&tlsServerName, "server-name", "", "Name expected in the App's server certificate")
+
+	// clear
+	tlsCmd.AddCommand(tlsClearCmd)
+}
+
+var tlsCmd = &cobra.Command{
+	Use:   "tls",
+	Short: "Configure TLS, e.g. mutual TLS, for the connections to HTTP Apps",
+}
+
+var tlsSetCmd = &cobra.Command{
+	Use:   "set <app_id>",
+	Short: "Set the TLS configuration for an installed App",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tlsConfig := apps.TLSConfig{
+			ServerName: tlsServerName,
+		}
+		for _, f := range []struct {
+			path string
+			to   *string
+		}{
+			{tlsClientCertFile, &tlsConfig.ClientCertificate},
+			{tlsClientKeyFile, &tlsConfig.ClientKey},
+			{tlsRootCAsFile, &tlsConfig.RootCAs},
+		} {
+			if f.path == "" {
+				continue
+			}
+			data, err := os.ReadFile(f.path)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read %s", f.path)
+			}
+			*f.to = string(data)
+		}
+		if tlsConfig.IsEmpty() {
+			return errors.New("nothing to set, use `appsctl tls clear` to remove the TLS configuration")
+		}
+		if err := tlsConfig.Validate(); err != nil {
+			return err
+		}
+		return setAppTLS(apps.AppID(args[0]), tlsConfig)
+	},
+}
+
+var tlsClearCmd = &cobra.Command{
+	Use:   "clear <app_id>",
+	Short: "Remove the TLS configuration for an installed App",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setAppTLS(apps.AppID(args[0]), apps.TLSConfig{})
+	},
+}
+
+func setAppTLS(appID apps.AppID, tlsConfig apps.TLSConfig) error {
+	appClient, err := getMattermostClient()
+	if err != nil {
+		return err
+	}
+	_, err = appClient.SetAppTLS(appID, tlsConfig)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the TLS configuration for %s", appID)
+	}
+	fmt.Printf("Updated the TLS configuration for %s.\n", appID)
+	return nil
+}
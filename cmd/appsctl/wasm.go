@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+)
+
+func init() {
+	rootCmd.AddCommand(wasmCmd)
+
+	// deploy
+	wasmCmd.AddCommand(wasmDeployCmd)
+	wasmDeployCmd.Flags().BoolVar(&install, "install", false, "Install the deployed App to Mattermost")
+}
+
+var wasmCmd = &cobra.Command{
+	Use:   "wasm",
+	Short: "Deploy Mattermost Apps as WebAssembly modules, run by the Apps plugin",
+}
+
+var wasmDeployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Upload a Mattermost app's WebAssembly module to Mattermost",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bundlePath := args[0]
+
+		appClient, err := getMattermostClient()
+		if err != nil {
+			return err
+		}
+
+		m, dir, err := upstream.GetAppBundle(bundlePath, log)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		if !m.Contains(apps.DeployWasm) {
+			return errors.New("manifest.json contains no wasm data")
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(m.Wasm.Module)))
+		if err != nil {
+			return errors.Wrap(err, "failed to read the WebAssembly module")
+		}
+		if _, err = appClient.StoreWasmModule(m.AppID, m.Version, data); err != nil {
+			return errors.Wrap(err, "failed to upload the WebAssembly module to Mattermost")
+		}
+		log.Debugw("uploaded WebAssembly module", "app_id", m.AppID, "version", m.Version, "size", len(data))
+
+		if err = updateMattermost(appClient, *m, apps.DeployWasm, install); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nDeployed '%s' as a WebAssembly module.\n", m.DisplayName)
+
+		if !install {
+			fmt.Printf("You can now install it in Mattermost using:\n")
+			fmt.Printf("  /apps install listed %s\n\n", m.AppID)
+		}
+		return nil
+	},
+}
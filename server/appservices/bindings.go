@@ -0,0 +1,38 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package appservices
+
+import (
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// InvalidateBindings removes the source app's cached bindings for the user, or
+// for all users if userID is empty, and tells the affected user agents to
+// refresh their bindings. Only the app's bot may invalidate the bindings of
+// other users.
+func (a *AppServices) InvalidateBindings(r *incoming.Request, userID string) error {
+	if err := r.Check(
+		r.RequireActingUser,
+		r.RequireSourceApp,
+	); err != nil {
+		return err
+	}
+
+	appID := r.SourceAppID()
+	app, err := a.store.App.Get(appID)
+	if err != nil {
+		return err
+	}
+	if userID != r.ActingUserID() && r.ActingUserID() != app.BotUserID {
+		return utils.NewForbiddenError("%s may only invalidate the acting user's bindings", appID)
+	}
+
+	a.caller.InvalidateCachedBindings(appID, userID)
+	r.Config().MattermostAPI().Frontend.PublishWebSocketEvent(config.WebSocketEventRefreshBindings, map[string]interface{}{}, &model.WebsocketBroadcast{UserId: userID})
+	return nil
+}
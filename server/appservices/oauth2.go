@@ -49,6 +49,7 @@ func (a *AppServices) StoreOAuth2App(r *incoming.Request, data []byte) error {
 		return err
 	}
 
+	a.caller.InvalidateCachedBindings(appID, "")
 	r.Config().MattermostAPI().Frontend.PublishWebSocketEvent(config.WebSocketEventRefreshBindings, map[string]interface{}{}, &model.WebsocketBroadcast{})
 
 	return nil
@@ -89,6 +90,7 @@ func (a *AppServices) StoreOAuth2User(r *incoming.Request, data []byte) error {
 		return err
 	}
 
+	a.caller.InvalidateCachedBindings(appID, actingUserID)
 	r.Config().MattermostAPI().Frontend.PublishWebSocketEvent(config.WebSocketEventRefreshBindings, map[string]interface{}{}, &model.WebsocketBroadcast{UserId: actingUserID})
 	return nil
 }
@@ -41,11 +41,16 @@ type Service interface {
 	StoreOAuth2App(_ *incoming.Request, data []byte) error
 	StoreOAuth2User(_ *incoming.Request, data []byte) error
 	GetOAuth2User(_ *incoming.Request) ([]byte, error)
+
+	// Bindings
+
+	InvalidateBindings(_ *incoming.Request, userID string) error
 }
 
 type Caller interface {
 	InvokeCall(*incoming.Request, apps.CallRequest) (*apps.App, apps.CallResponse)
 	NewIncomingRequest() *incoming.Request
+	InvalidateCachedBindings(_ apps.AppID, userID string)
 }
 
 type AppServices struct {
@@ -11,7 +11,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
-	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 )
 
@@ -76,7 +75,7 @@ func (a *AppServices) ExecuteTimer(key string, props interface{}) {
 
 	r.Log = r.Log.With(t)
 
-	ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), a.conf.Get().IncomingRequestTimeout())
 	defer cancel()
 	r = r.WithCtx(ctx)
 
@@ -37,10 +37,12 @@ const (
 	FieldNamespace = "namespace"
 
 	fAction             = "action"
+	fAlgorithm          = "algorithm"
 	fAllowHTTPApps      = "allow_http_apps"
 	fBase64             = "base64"
 	fBase64Key          = "base64_key"
 	fChannel            = "channel"
+	fCIDRs              = "cidrs"
 	fChannelDisplayName = "channel_display_name"
 	fChannelName        = "channel_name"
 	fConsent            = "consent"
@@ -50,18 +52,22 @@ const (
 	fDeployType         = "deploy_type"
 	fDeveloperMode      = "developer_mode"
 	fForce              = "force"
+	fGracePeriod        = "grace_period"
 	fHashkeys           = "hashkeys"
 	fID                 = "id"
 	fIncludePlugins     = "include_plugins"
 	fJSON               = "json"
+	fJSONL              = "jsonl"
 	fLevel              = "level"
 	fLog                = "log"
 	fNewValue           = "new_value"
 	fOverrides          = "overrides"
 	fPage               = "page"
+	fPath               = "path"
 	fSecret             = "secret"
 	fSessionID          = "session_id"
 	fURL                = "url"
+	fUser               = "user"
 )
 
 const (
@@ -71,6 +77,7 @@ const (
 	PathDebugStoreList    = "/debug/store/list"
 	PathDebugStorePollute = "/debug/store/pollute"
 	PathDebugSessionsList = "/debug/session/list"
+	pAudit                = "/audit"
 	pDebugBindings        = "/debug/bindings"
 	pDebugKVClean         = "/debug/kv/clean"
 	pDebugKVCreate        = "/debug/kv/create"
@@ -80,6 +87,7 @@ const (
 	pDebugOAuthConfigView = "/debug/oauth/config/view"
 	pDebugSessionsRevoke  = "/debug/session/delete"
 	pDebugSessionsView    = "/debug/session/view"
+	pDebugWebhooks        = "/debug/webhooks"
 	pDisable              = "/disable"
 	pEnable               = "/enable"
 	pInfo                 = "/info"
@@ -88,9 +96,12 @@ const (
 	pInstallHTTP          = "/install-http"
 	pInstallListed        = "/install-listed"
 	pList                 = "/list"
+	pRotateJWTKey         = "/rotate-jwt-key"
+	pRotateWebhookSecret  = "/rotate-webhook-secret"
 	pSettingsModalSave    = "/settings/save"
 	pSettingsModalSource  = "/settings/form"
 	pUninstall            = "/uninstall"
+	pWebhookAllowlist     = "/webhook-allowlist"
 )
 
 const (
@@ -136,6 +147,7 @@ func NewBuiltinApp(conf config.Service, proxy proxy.Service, appservices appserv
 		PathDebugStoreList:    requireAdmin(a.debugStoreList),
 		PathDebugStorePollute: requireAdmin(a.debugStorePollute),
 
+		pAudit:                requireAdmin(a.audit),
 		pDebugBindings:        requireAdmin(a.debugBindings),
 		pDebugKVClean:         requireAdmin(a.debugKVClean),
 		pDebugKVCreate:        requireAdmin(a.debugKVCreate),
@@ -144,6 +156,7 @@ func NewBuiltinApp(conf config.Service, proxy proxy.Service, appservices appserv
 		pDebugOAuthConfigView: requireAdmin(a.debugOAuthConfigView),
 		pDebugSessionsRevoke:  requireAdmin(a.debugSessionsRevoke),
 		pDebugSessionsView:    requireAdmin(a.debugSessionsView),
+		pDebugWebhooks:        requireAdmin(a.debugWebhooks),
 		pDisable:              requireAdmin(a.disable),
 		pEnable:               requireAdmin(a.enable),
 		pInstallConsentModal:  requireAdmin(a.installConsent),
@@ -151,9 +164,12 @@ func NewBuiltinApp(conf config.Service, proxy proxy.Service, appservices appserv
 		pInstallHTTP:          requireAdmin(a.installHTTP),
 		pInstallListed:        requireAdmin(a.installListed),
 		pList:                 requireAdmin(a.list),
+		pRotateJWTKey:         requireAdmin(a.rotateJWTKey),
+		pRotateWebhookSecret:  requireAdmin(a.rotateWebhookSecret),
 		pSettingsModalSave:    requireAdmin(a.settingsSave),
 		pSettingsModalSource:  requireAdmin(a.settingsForm),
 		pUninstall:            requireAdmin(a.uninstall),
+		pWebhookAllowlist:     requireAdmin(a.webhookAllowlist),
 
 		// Lookups.
 		pLookupAppID:     requireAdmin(a.lookupAppID),
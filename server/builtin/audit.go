@@ -0,0 +1,219 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+var auditActions = []apps.AuditAction{
+	apps.AuditAppInstalled,
+	apps.AuditAppUninstalled,
+	apps.AuditAppEnabled,
+	apps.AuditAppDisabled,
+	apps.AuditAppListingUpdated,
+	apps.AuditAppTLSUpdated,
+	apps.AuditPermissionsGranted,
+	apps.AuditSessionCreated,
+	apps.AuditActingUserTokenExpanded,
+	apps.AuditJWTSigningKeyRotated,
+	apps.AuditWebhookSecretRotated,
+	apps.AuditWebhookAllowlistUpdated,
+	apps.AuditWasmModuleStored,
+}
+
+func (a *builtinApp) auditCommandBinding(loc *i18n.Localizer) apps.Binding {
+	actionOptions := []apps.SelectOption{}
+	for _, action := range auditActions {
+		actionOptions = append(actionOptions, apps.SelectOption{
+			Label: string(action),
+			Value: string(action),
+		})
+	}
+
+	return apps.Binding{
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.audit.label",
+			Other: "audit",
+		}),
+		Location: "audit",
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.audit.description",
+			Other: "Display the audit log of administrative and privileged App actions",
+		}),
+
+		Form: &apps.Form{
+			Submit: newUserCall(pAudit),
+			Fields: []apps.Field{
+				{
+					Name: FieldAppID,
+					Type: apps.FieldTypeText,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.app.description",
+						Other: "Only include the records for this App ID.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.app.label",
+						Other: "app",
+					}),
+				},
+				{
+					Name: fUser,
+					Type: apps.FieldTypeUser,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.user.description",
+						Other: "Only include the actions performed by this user.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.user.label",
+						Other: "user",
+					}),
+				},
+				{
+					Name:                fAction,
+					Type:                apps.FieldTypeStaticSelect,
+					SelectStaticOptions: actionOptions,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.action.description",
+						Other: "Only include the records of this action.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.action.label",
+						Other: "action",
+					}),
+				},
+				{
+					Name: fCount,
+					Type: apps.FieldTypeText,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.count.description",
+						Other: "Maximum number of records to display, 100 by default.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.count.label",
+						Other: "count",
+					}),
+				},
+				{
+					Name: fJSONL,
+					Type: apps.FieldTypeBool,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.jsonl.description",
+						Other: "Export the records as JSON lines.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.audit.jsonl.label",
+						Other: "jsonl",
+					}),
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) audit(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	q := apps.AuditQuery{
+		AppID:  apps.AppID(creq.GetValue(FieldAppID, "")),
+		UserID: creq.GetValue(fUser, ""),
+		Action: apps.AuditAction(creq.GetValue(fAction, "")),
+	}
+	if countStr := creq.GetValue(fCount, ""); countStr != "" {
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count <= 0 {
+			return apps.NewErrorResponse(utils.NewInvalidError("invalid count %q", countStr))
+		}
+		q.Limit = count
+	}
+
+	records, err := a.proxy.GetAuditLog(r, q)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	loc := a.newLocalizer(creq)
+	if len(records) == 0 {
+		return apps.NewTextResponse(a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.audit.submit.empty",
+			Other: "No matching audit records.",
+		}))
+	}
+
+	if creq.BoolValue(fJSONL) {
+		lines := []string{}
+		for _, rec := range records {
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return apps.NewErrorResponse(err)
+			}
+			lines = append(lines, string(data))
+		}
+		return apps.CallResponse{
+			Type: apps.CallResponseTypeOK,
+			Text: "```\n" + strings.Join(lines, "\n") + "\n```\n",
+			Data: records,
+		}
+	}
+
+	usernames := map[string]string{}
+	username := func(userID string) string {
+		if userID == "" {
+			return ""
+		}
+		if name, ok := usernames[userID]; ok {
+			return name
+		}
+		name := userID
+		if user, err := a.conf.MattermostAPI().User.Get(userID); err == nil {
+			name = "@" + user.Username
+		}
+		usernames[userID] = name
+		return name
+	}
+
+	txt := a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+		ID:    "command.audit.submit.header",
+		Other: "| Time | Action | App | User | Details |",
+	})
+	txt += "\n| :-- | :-- | :-- | :-- | :-- |\n"
+	for _, rec := range records {
+		actor := username(rec.UserID)
+		if rec.PluginID != "" {
+			actor = strings.TrimSpace(actor + " (" + rec.PluginID + ")")
+		}
+		txt += fmt.Sprintf("|%s|%s|%s|%s|%s|\n",
+			rec.Time.Format(time.RFC3339), rec.Action, rec.AppID, actor, auditDetails(rec.Details))
+	}
+
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeOK,
+		Text: txt,
+		Data: records,
+	}
+}
+
+func auditDetails(details map[string]string) string {
+	keys := []string{}
+	for k := range details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := []string{}
+	for _, k := range keys {
+		if details[k] != "" {
+			out = append(out, fmt.Sprintf("%s: `%s`", k, details[k]))
+		}
+	}
+	return strings.Join(out, ", ")
+}
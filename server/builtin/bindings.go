@@ -22,12 +22,16 @@ func (a *builtinApp) getBindings(creq apps.CallRequest, loc *i18n.Localizer) []a
 			commands = append(commands, a.debugCommandBinding(loc))
 		}
 		commands = append(commands,
+			a.auditCommandBinding(loc),
 			a.disableCommandBinding(loc),
 			a.enableCommandBinding(loc),
 			a.installCommandBinding(loc),
 			a.listCommandBinding(loc),
+			a.rotateJWTKeyCommandBinding(loc),
+			a.rotateWebhookSecretCommandBinding(loc),
 			a.uninstallCommandBinding(loc),
 			a.settingsCommandBinding(loc),
+			a.webhookAllowlistCommandBinding(loc),
 		)
 	}
 
@@ -82,6 +82,7 @@ func (a *builtinApp) debugCommandBinding(loc *i18n.Localizer) apps.Binding {
 					a.debugOAuthConfigViewBinding(loc),
 				},
 			},
+			a.debugWebhooksCommandBinding(loc),
 		},
 	}
 }
@@ -33,7 +33,7 @@ func (a *builtinApp) debugBindingsCommandBinding(loc *i18n.Localizer) apps.Bindi
 
 func (a *builtinApp) debugBindings(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
 	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
-	var bindings []apps.Binding
+	var bindings interface{}
 	out := ""
 	var err error
 	if appID == "" {
@@ -0,0 +1,95 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func (a *builtinApp) debugWebhooksCommandBinding(loc *i18n.Localizer) apps.Binding {
+	return apps.Binding{
+		Location: "webhooks",
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.webhooks.label",
+			Other: "webhooks",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.debug.webhooks.description",
+			Other: "Display the recent remote webhook deliveries to an App.",
+		}),
+		Form: &apps.Form{
+			Submit: newUserCall(pDebugWebhooks),
+			Fields: []apps.Field{
+				a.appIDField(LookupInstalledApps, 1, true, loc),
+				{
+					Name: fJSON,
+					Type: apps.FieldTypeBool,
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.webhooks.json.label",
+						Other: "json",
+					}),
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.webhooks.json.description",
+						Other: "Include the full delivery records, with the (redacted) headers.",
+					}),
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) debugWebhooks(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+	deliveries, err := a.proxy.GetWebhookDeliveries(r, appID)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	loc := a.newLocalizer(creq)
+	if len(deliveries) == 0 {
+		return apps.NewTextResponse(a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID:    "command.debug.webhooks.submit.empty",
+				Other: "No remote webhook deliveries to `{{.AppID}}` have been recorded on this server.",
+			},
+			TemplateData: map[string]string{
+				"AppID": string(appID),
+			},
+		}))
+	}
+
+	txt := a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+		ID:    "command.debug.webhooks.submit.header",
+		Other: "| Time | Path | Size | Auth | Status | Elapsed | Error |",
+	})
+	txt += "\n| :-- | :-- | --: | :-- | --: | --: | :-- |\n"
+	for _, d := range deliveries {
+		auth := "OK"
+		switch {
+		case d.AuthError != "":
+			auth = d.AuthError
+		case !d.Authenticated:
+			auth = "-"
+		}
+		txt += fmt.Sprintf("|%s|`/%s`|%v|%s|%v|%s|%s|\n",
+			d.Time.Format(time.RFC3339), d.Path, d.BodySize, auth, d.StatusCode, d.Elapsed, d.Error)
+	}
+
+	if creq.BoolValue(fJSON) {
+		txt += "\n" + utils.JSONBlock(deliveries)
+	}
+
+	return apps.CallResponse{
+		Type: apps.CallResponseTypeOK,
+		Text: txt,
+		Data: deliveries,
+	}
+}
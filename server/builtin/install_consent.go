@@ -151,7 +151,8 @@ func (a *builtinApp) newInstallConsentForm(m apps.Manifest, creq apps.CallReques
 	fields = append(fields, deployTypeField)
 
 	// JWT secret
-	if deployType == apps.DeployHTTP && m.Contains(apps.DeployHTTP) && m.HTTP.UseJWT {
+	if (deployType == apps.DeployHTTP && m.Contains(apps.DeployHTTP) && m.HTTP.UsesAppSecret()) ||
+		(deployType == apps.DeployGRPC && m.Contains(apps.DeployGRPC) && m.GRPC.UseToken) {
 		fields = append(fields, apps.Field{
 			Name: fSecret,
 			Type: apps.FieldTypeText,
@@ -173,6 +174,29 @@ func (a *builtinApp) newInstallConsentForm(m apps.Manifest, creq apps.CallReques
 		})
 	}
 
+	// Connection secret
+	if deployType == apps.DeployWebSocket && m.Contains(apps.DeployWebSocket) {
+		fields = append(fields, apps.Field{
+			Name: fSecret,
+			Type: apps.FieldTypeText,
+			ModalLabel: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+				ID:    "field.secret.modal_label.websocket",
+				Other: "Connection Secret",
+			}),
+
+			Description: a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+				DefaultMessage: &i18n.Message{
+					ID:    "field.secret.description.websocket",
+					Other: "The app uses the secret to authenticate its connection to Mattermost. Usually, it should be obtained from the App's web site, {{.HomepageURL}}",
+				},
+				TemplateData: map[string]string{
+					"HomepageURL": m.HomepageURL,
+				},
+			}),
+			IsRequired: true,
+		})
+	}
+
 	// TODO: figure out a way to access the static assets before the app is installed
 	// var iconURL string
 	// if m.Icon != "" {
@@ -5,11 +5,15 @@ package builtin
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upws"
 )
 
 func (a *builtinApp) listCommandBinding(loc *i18n.Localizer) apps.Binding {
@@ -56,7 +60,7 @@ func (a *builtinApp) list(r *incoming.Request, creq apps.CallRequest) apps.CallR
 	includePluginApps := creq.BoolValue("plugin-apps")
 
 	listed := a.proxy.GetListedApps("", includePluginApps)
-	installed, reachable := a.proxy.PingInstalledApps(r.Ctx())
+	installed, reachable, endpoints, connections := a.proxy.PingInstalledApps(r.Ctx())
 
 	// All of this information is non sensitive.
 	// Checks for the user's permissions might be needed in the future.
@@ -91,6 +95,18 @@ func (a *builtinApp) list(r *incoming.Request, creq apps.CallRequest) apps.CallR
 				Other: "Installed, **Unreachable**",
 			})
 		}
+		if breaker := a.proxy.GetCircuitBreakerStatus(app.AppID); breaker.State != upstream.BreakerClosed {
+			status += a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+				DefaultMessage: &i18n.Message{
+					ID:    "command.list.submit.status.breaker",
+					Other: ", circuit breaker {{.State}} after {{.Failures}} failures",
+				},
+				TemplateData: map[string]string{
+					"State":    string(breaker.State),
+					"Failures": fmt.Sprint(breaker.Failures),
+				},
+			})
+		}
 
 		version := string(app.Version)
 		if string(m.Version) != version {
@@ -126,7 +142,23 @@ func (a *builtinApp) list(r *incoming.Request, creq apps.CallRequest) apps.CallR
 
 		deployType := app.DeployType.String()
 		if app.DeployType == apps.DeployHTTP && app.HTTP != nil {
-			deployType += " (" + app.HTTP.RootURL + ")"
+			if statuses := endpoints[app.AppID]; len(statuses) > 0 {
+				deployType += " (" + a.listEndpoints(loc, statuses) + ")"
+			} else {
+				deployType += " (" + app.HTTP.RootURL + ")"
+			}
+		}
+		if app.DeployType == apps.DeployGRPC && app.GRPC != nil {
+			deployType += " (" + app.GRPC.Address + ")"
+		}
+		if app.DeployType == apps.DeployExec && app.Exec != nil {
+			deployType += " (" + app.Exec.Command + ")"
+		}
+		if app.DeployType == apps.DeployWasm && app.Wasm != nil {
+			deployType += " (" + app.Wasm.Module + ")"
+		}
+		if app.DeployType == apps.DeployWebSocket {
+			deployType += " (" + a.listConnection(loc, connections[app.AppID]) + ")"
 		}
 
 		txt += fmt.Sprintf("|%s|%s|%s|%s|%s|%s|%s|\n",
@@ -155,3 +187,56 @@ func (a *builtinApp) list(r *incoming.Request, creq apps.CallRequest) apps.CallR
 	}
 	return apps.NewTextResponse(txt)
 }
+
+func (a *builtinApp) listEndpoints(loc *i18n.Localizer, statuses []upstream.EndpointStatus) string {
+	var out []string
+	for _, s := range statuses {
+		if s.Healthy {
+			out = append(out, s.URL)
+			continue
+		}
+		out = append(out, a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID:    "command.list.submit.endpoint.unhealthy",
+				Other: "{{.URL}} **unhealthy**",
+			},
+			TemplateData: map[string]string{
+				"URL": s.URL,
+			},
+		}))
+	}
+	return strings.Join(out, ", ")
+}
+
+func (a *builtinApp) listConnection(loc *i18n.Localizer, s *upws.Status) string {
+	switch {
+	case s == nil:
+		return a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.list.submit.connection.never",
+			Other: "never connected",
+		})
+	case s.Connected:
+		return a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID:    "command.list.submit.connection.connected",
+				Other: "connected to server `{{.Node}}` from {{.RemoteAddr}} since {{.Since}}",
+			},
+			TemplateData: map[string]string{
+				"Node":       s.Node,
+				"RemoteAddr": s.RemoteAddr,
+				"Since":      s.Since.Format(time.RFC3339),
+			},
+		})
+	default:
+		return a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{
+				ID:    "command.list.submit.connection.disconnected",
+				Other: "**disconnected** since {{.Since}}: {{.LastError}}",
+			},
+			TemplateData: map[string]string{
+				"Since":     s.Since.Format(time.RFC3339),
+				"LastError": s.LastError,
+			},
+		})
+	}
+}
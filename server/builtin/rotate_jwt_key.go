@@ -0,0 +1,75 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	appspath "github.com/mattermost/mattermost-plugin-apps/apps/path"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+func (a *builtinApp) rotateJWTKeyCommandBinding(loc *i18n.Localizer) apps.Binding {
+	return apps.Binding{
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.rotate_jwt_key.label",
+			Other: "rotate-jwt-key",
+		}),
+		Location: "rotate-jwt-key",
+		Hint: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.rotate_jwt_key.hint",
+			Other: "[ RS256 | EdDSA ]",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.rotate_jwt_key.description",
+			Other: "Generate a new server key for signing the JWTs sent to HTTP Apps",
+		}),
+
+		Form: &apps.Form{
+			Submit: newUserCall(pRotateJWTKey),
+			Fields: []apps.Field{
+				{
+					Name:                 fAlgorithm,
+					Type:                 apps.FieldTypeStaticSelect,
+					IsRequired:           true,
+					AutocompletePosition: 1,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.algorithm.description",
+						Other: "The JWT signing method of the key to rotate.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.algorithm.label",
+						Other: "algorithm",
+					}),
+					SelectStaticOptions: []apps.SelectOption{
+						{Label: apps.JWTSigningMethodRS256, Value: apps.JWTSigningMethodRS256},
+						{Label: apps.JWTSigningMethodEdDSA, Value: apps.JWTSigningMethodEdDSA},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) rotateJWTKey(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	alg := creq.GetValue(fAlgorithm, "")
+	kid, err := a.proxy.RotateJWTSigningKey(r, alg)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	loc := a.newLocalizer(creq)
+	return apps.NewTextResponse(a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.rotate_jwt_key.submit.ok",
+			Other: "Rotated the {{.Algorithm}} JWT signing key, the new key ID is `{{.KeyID}}`. The previous key remains published at `{{.URL}}` for 24 hours.",
+		},
+		TemplateData: map[string]string{
+			"Algorithm": alg,
+			"KeyID":     kid,
+			"URL":       a.conf.Get().PluginURL + appspath.API + appspath.JWKS,
+		},
+	}))
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	appspath "github.com/mattermost/mattermost-plugin-apps/apps/path"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/proxy"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func (a *builtinApp) rotateWebhookSecretCommandBinding(loc *i18n.Localizer) apps.Binding {
+	return apps.Binding{
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.rotate_webhook_secret.label",
+			Other: "rotate-webhook-secret",
+		}),
+		Location: "rotate-webhook-secret",
+		Hint: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.rotate_webhook_secret.hint",
+			Other: "[ App ID ]",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.rotate_webhook_secret.description",
+			Other: "Generate a new secret for an App's remote webhooks",
+		}),
+
+		Form: &apps.Form{
+			Submit: newUserCall(pRotateWebhookSecret),
+			Fields: []apps.Field{
+				a.appIDField(LookupInstalledApps, 1, true, loc),
+				{
+					Name: fGracePeriod,
+					Type: apps.FieldTypeText,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.grace_period.description",
+						Other: "How long the previous secret is still accepted, e.g. `1h`. Use `0` to revoke it immediately. The default is `24h`.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.grace_period.label",
+						Other: "grace_period",
+					}),
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) rotateWebhookSecret(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+	gracePeriod := proxy.DefaultWebhookSecretGracePeriod
+	if v := creq.GetValue(fGracePeriod, ""); v != "" {
+		if v == "0" {
+			gracePeriod = 0
+		} else {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return apps.NewErrorResponse(utils.NewInvalidError(err, "invalid grace period"))
+			}
+			gracePeriod = d
+		}
+	}
+
+	app, out, err := a.proxy.RotateWebhookSecret(r, appID, gracePeriod)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	loc := a.newLocalizer(creq)
+	out += "\n\n" + a.conf.I18N().LocalizeWithConfig(loc, &i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{
+			ID:    "command.rotate_webhook_secret.submit.url",
+			Other: "The new webhook URL is: `{{.URL}}`",
+		},
+		TemplateData: map[string]string{
+			"URL": a.conf.Get().AppURL(app.AppID) + appspath.Webhook + "?secret=" + app.WebhookSecret,
+		},
+	})
+	return apps.NewTextResponse(out)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package builtin
+
+import (
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func (a *builtinApp) webhookAllowlistCommandBinding(loc *i18n.Localizer) apps.Binding {
+	return apps.Binding{
+		Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.webhook_allowlist.label",
+			Other: "webhook-allowlist",
+		}),
+		Location: "webhook-allowlist",
+		Hint: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.webhook_allowlist.hint",
+			Other: "[ App ID ]",
+		}),
+		Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+			ID:    "command.webhook_allowlist.description",
+			Other: "Restrict the source IP addresses of an App's remote webhooks",
+		}),
+
+		Form: &apps.Form{
+			Submit: newUserCall(pWebhookAllowlist),
+			Fields: []apps.Field{
+				a.appIDField(LookupInstalledApps, 1, true, loc),
+				{
+					Name: fCIDRs,
+					Type: apps.FieldTypeText,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.cidrs.description",
+						Other: "Comma-separated list of allowed IP ranges, e.g. `192.30.252.0/22,140.82.112.0/20`. Leave empty to remove the restriction.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.cidrs.label",
+						Other: "cidrs",
+					}),
+				},
+				{
+					Name: fPath,
+					Type: apps.FieldTypeText,
+					Description: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.webhook_path.description",
+						Other: "Optional webhook path, relative to `/webhook`, to set the ranges for. By default the ranges apply to all paths.",
+					}),
+					Label: a.conf.I18N().LocalizeDefaultMessage(loc, &i18n.Message{
+						ID:    "field.webhook_path.label",
+						Other: "path",
+					}),
+				},
+			},
+		},
+	}
+}
+
+func (a *builtinApp) webhookAllowlist(r *incoming.Request, creq apps.CallRequest) apps.CallResponse {
+	appID := apps.AppID(creq.GetValue(FieldAppID, ""))
+	app, err := a.proxy.GetInstalledApp(appID, false)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+
+	var cidrs []string
+	for _, cidr := range strings.Split(creq.GetValue(fCIDRs, ""), ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+
+	allowlist := apps.RemoteWebhookAllowlist{}
+	if app.WebhookAllowlist != nil {
+		allowlist.CIDRs = app.WebhookAllowlist.CIDRs
+		allowlist.Paths = map[string][]string{}
+		for p, pathCIDRs := range app.WebhookAllowlist.Paths {
+			allowlist.Paths[p] = pathCIDRs
+		}
+	}
+
+	if webhookPath := strings.Trim(creq.GetValue(fPath, ""), "/"); webhookPath != "" {
+		if allowlist.Paths == nil {
+			allowlist.Paths = map[string][]string{}
+		}
+		if len(cidrs) == 0 {
+			delete(allowlist.Paths, webhookPath)
+		} else {
+			allowlist.Paths[webhookPath] = cidrs
+		}
+	} else {
+		allowlist.CIDRs = cidrs
+	}
+	if len(allowlist.Paths) == 0 {
+		allowlist.Paths = nil
+	}
+
+	out, err := a.proxy.SetWebhookAllowlist(r, appID, allowlist)
+	if err != nil {
+		return apps.NewErrorResponse(err)
+	}
+	if !allowlist.IsEmpty() {
+		out += "\n" + utils.JSONBlock(allowlist)
+	}
+	return apps.NewTextResponse(out)
+}
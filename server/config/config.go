@@ -2,12 +2,16 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"path"
+	"time"
 
 	"github.com/mattermost/mattermost/server/public/model"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	appspath "github.com/mattermost/mattermost-plugin-apps/apps/path"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upaws"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 // StoredConfig represents the data stored in and managed with the Mattermost
@@ -37,6 +41,71 @@ type StoredConfig struct {
 	LogChannelID    string `json:"log_channel_id,omitempty"`
 	LogChannelLevel int    `json:"log_channel_level,omitempty"`
 	LogChannelJSON  bool   `json:"log_channel_json,omitempty"`
+
+	// TrustedProxies is the list of IP ranges of the reverse proxies in front
+	// of Mattermost. For requests coming from them, the client IP address is
+	// taken from ClientIPHeader (X-Forwarded-For by default). It is used to
+	// enforce the apps' remote webhook allowlists.
+	TrustedProxies []string `json:"trusted_proxies,omitempty"`
+	ClientIPHeader string   `json:"client_ip_header,omitempty"`
+
+	// UpstreamMaxRetries is the number of times the idempotent requests to
+	// the apps (bindings, lookups, static assets) are retried after a failure.
+	// The default is 2, -1 disables the retries.
+	UpstreamMaxRetries int `json:"upstream_max_retries,omitempty"`
+
+	// UpstreamBreakerThreshold is the number of consecutive failed requests to
+	// an app after which its requests fail fast, without reaching the app, for
+	// UpstreamBreakerCooldownSeconds. The default is 5, -1 disables the circuit
+	// breakers.
+	UpstreamBreakerThreshold       int `json:"upstream_breaker_threshold,omitempty"`
+	UpstreamBreakerCooldownSeconds int `json:"upstream_breaker_cooldown_seconds,omitempty"`
+
+	// StaticCacheSizeMB is the size of the in-memory cache of the apps' static
+	// assets, on each Mattermost server. The default is 64, -1 disables the
+	// cache. Cached assets are revalidated with the app after
+	// StaticCacheTTLSeconds, 300 by default.
+	StaticCacheSizeMB     int `json:"static_cache_size_mb,omitempty"`
+	StaticCacheTTLSeconds int `json:"static_cache_ttl_seconds,omitempty"`
+
+	// BindingsTimeoutSeconds is how long to wait for each app's bindings
+	// before using the last good ones, 5 by default. It does not apply to the
+	// apps that declare a timeout for their bindings call.
+	BindingsTimeoutSeconds int `json:"bindings_timeout_seconds,omitempty"`
+
+	// BindingsCacheSize is the maximum number of cached per-user app bindings,
+	// 10000 by default, -1 disables the cache.
+	BindingsCacheSize int `json:"bindings_cache_size,omitempty"`
+
+	// AuditRetentionDays is how long the audit log records are kept, 90 days
+	// by default.
+	AuditRetentionDays int `json:"audit_retention_days,omitempty"`
+
+	// MaxCallTimeoutSeconds caps the call timeouts that apps declare in their
+	// manifests (see apps.CallTimeouts). The default is 30.
+	MaxCallTimeoutSeconds int `json:"max_call_timeout_seconds,omitempty"`
+
+	// WasmMaxMemoryMB caps the memory limit that wasm apps declare in their
+	// manifests, 64 by default. WasmMaxConcurrentInstances limits how many
+	// instances of each wasm app's module can run at the same time, on each
+	// Mattermost server, 4 by default. The timeouts of the wasm apps are
+	// capped by MaxCallTimeoutSeconds.
+	WasmMaxMemoryMB            int `json:"wasm_max_memory_mb,omitempty"`
+	WasmMaxConcurrentInstances int `json:"wasm_max_concurrent_instances,omitempty"`
+
+	// AWSEndpointsOverride overrides the AWS service endpoints, to run the
+	// aws_lambda upstream and the S3 manifest store against S3 and
+	// Lambda-compatible services, such as MinIO or LocalStack. If not set, the
+	// MM_APPS_AWS_*_ENDPOINT environment variables are used.
+	AWSEndpointsOverride *upaws.Endpoints `json:"aws_endpoints,omitempty"`
+
+	// TracingExporter enables the OpenTelemetry tracing of the requests and
+	// the calls to the apps: "otlp" exports the spans to TracingOTLPEndpoint
+	// (e.g. http://localhost:4318), "file" appends them as JSON to
+	// TracingFile. Empty disables tracing.
+	TracingExporter     string `json:"tracing_exporter,omitempty"`
+	TracingOTLPEndpoint string `json:"tracing_otlp_endpoint,omitempty"`
+	TracingFile         string `json:"tracing_file,omitempty"`
 }
 
 var BuildDate string
@@ -68,10 +137,14 @@ type Config struct {
 	// Maximum size of incoming remote webhook messages
 	MaxWebhookSize int
 
+	// TrustedProxyRanges is the parsed StoredConfig.TrustedProxies.
+	TrustedProxyRanges []*net.IPNet
+
 	AWSRegion    string
 	AWSAccessKey string
 	AWSSecretKey string
 	AWSS3Bucket  string
+	AWSEndpoints upaws.Endpoints
 }
 
 func (conf Config) AppURL(appID apps.AppID) string {
@@ -83,6 +156,55 @@ func (conf Config) StaticURL(appID apps.AppID, name string) string {
 	return conf.AppURL(appID) + "/" + path.Join(appspath.StaticFolder, name)
 }
 
+// MaxCallTimeout returns the maximum call timeout that an app may declare.
+func (conf Config) MaxCallTimeout() time.Duration {
+	if conf.MaxCallTimeoutSeconds > 0 {
+		return time.Duration(conf.MaxCallTimeoutSeconds) * time.Second
+	}
+	return RequestTimeout
+}
+
+// CallTimeout returns the timeout declared by an app, capped by
+// MaxCallTimeout. It returns 0 if the app declared none.
+func (conf Config) CallTimeout(declared time.Duration) time.Duration {
+	if max := conf.MaxCallTimeout(); declared > max {
+		return max
+	}
+	return declared
+}
+
+// BindingsTimeout returns how long to wait for an app's bindings, given the
+// timeout that the app declared for its bindings call, if any.
+func (conf Config) BindingsTimeout(declared time.Duration) time.Duration {
+	switch {
+	case declared > 0:
+		return conf.CallTimeout(declared)
+	case conf.BindingsTimeoutSeconds > 0:
+		return time.Duration(conf.BindingsTimeoutSeconds) * time.Second
+	}
+	return DefaultBindingsTimeout
+}
+
+// IncomingRequestTimeout returns the timeout of the requests that may call
+// apps, long enough for the longest allowed call.
+func (conf Config) IncomingRequestTimeout() time.Duration {
+	if max := conf.MaxCallTimeout(); max > RequestTimeout {
+		return max
+	}
+	return RequestTimeout
+}
+
+// TracingOptions returns the configuration of the OpenTelemetry tracing.
+func (conf Config) TracingOptions() tracing.Options {
+	return tracing.Options{
+		Exporter:       conf.TracingExporter,
+		OTLPEndpoint:   conf.TracingOTLPEndpoint,
+		File:           conf.TracingFile,
+		ServiceName:    conf.PluginManifest.Id,
+		ServiceVersion: conf.PluginManifest.Version,
+	}
+}
+
 func (conf Config) GetPluginVersionInfo() map[string]interface{} {
 	return map[string]interface{}{
 		"version": conf.PluginManifest.Version,
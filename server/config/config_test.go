@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,3 +12,31 @@ func TestBuildConfig(t *testing.T) {
 	assert.NotEmpty(t, BuildHash)
 	assert.NotEmpty(t, BuildHashShort)
 }
+
+func TestCallTimeout(t *testing.T) {
+	conf := Config{}
+	assert.Equal(t, RequestTimeout, conf.MaxCallTimeout())
+	assert.Equal(t, RequestTimeout, conf.IncomingRequestTimeout())
+	assert.Equal(t, 2*time.Second, conf.CallTimeout(2*time.Second))
+	assert.Equal(t, RequestTimeout, conf.CallTimeout(time.Minute))
+
+	conf.MaxCallTimeoutSeconds = 45
+	assert.Equal(t, 45*time.Second, conf.IncomingRequestTimeout())
+	assert.Equal(t, 25*time.Second, conf.CallTimeout(25*time.Second))
+	assert.Equal(t, 45*time.Second, conf.CallTimeout(time.Minute))
+
+	conf.MaxCallTimeoutSeconds = 10
+	assert.Equal(t, RequestTimeout, conf.IncomingRequestTimeout())
+	assert.Equal(t, 10*time.Second, conf.CallTimeout(25*time.Second))
+}
+
+func TestBindingsTimeout(t *testing.T) {
+	conf := Config{}
+	assert.Equal(t, DefaultBindingsTimeout, conf.BindingsTimeout(0))
+	assert.Equal(t, 2*time.Second, conf.BindingsTimeout(2*time.Second))
+	assert.Equal(t, RequestTimeout, conf.BindingsTimeout(time.Minute))
+
+	conf.BindingsTimeoutSeconds = 10
+	assert.Equal(t, 10*time.Second, conf.BindingsTimeout(0))
+	assert.Equal(t, 2*time.Second, conf.BindingsTimeout(2*time.Second))
+}
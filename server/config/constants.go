@@ -35,6 +35,10 @@ const (
 
 const (
 	RequestTimeout = time.Second * 30
+
+	// DefaultBindingsTimeout is how long GetBindings waits for each App's
+	// bindings, unless the App declares a timeout for its bindings call.
+	DefaultBindingsTimeout = time.Second * 5
 )
 
 const (
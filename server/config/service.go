@@ -19,6 +19,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/telemetry"
 	"github.com/mattermost/mattermost-plugin-apps/upstream/upaws"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
 )
 
 type Configurable interface {
@@ -142,10 +143,20 @@ func (s *service) newInitializedConfig(newStoredConfig StoredConfig, log utils.L
 		conf.MaxWebhookSize = int(*newMattermostConfig.FileSettings.MaxFileSize)
 	}
 
+	conf.TrustedProxyRanges, err = httputils.ParseCIDRs(conf.TrustedProxies)
+	if err != nil {
+		log.WithError(err).Warnf("Ignored invalid trusted proxies configuration")
+		conf.TrustedProxyRanges = nil
+	}
+
 	conf.AWSAccessKey = os.Getenv(upaws.AccessEnvVar)
 	conf.AWSSecretKey = os.Getenv(upaws.SecretEnvVar)
 	conf.AWSRegion = upaws.Region()
 	conf.AWSS3Bucket = upaws.S3BucketName()
+	conf.AWSEndpoints = upaws.EndpointsFromEnv()
+	if conf.AWSEndpointsOverride != nil {
+		conf.AWSEndpoints = *conf.AWSEndpointsOverride
+	}
 
 	license := s.getMattermostLicense(log)
 	conf.MattermostCloudMode = license != nil &&
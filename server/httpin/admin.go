@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/apps/appclient"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/proxy"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
 )
@@ -136,6 +139,137 @@ func (s *Service) UninstallApp(r *incoming.Request, w http.ResponseWriter, req *
 	}
 }
 
+// RotateWebhookSecret generates a new webhook secret for an App. The previous
+// secret remains valid for the grace period.
+//
+//	Path: /api/v1/rotate-webhook-secret
+//	Method: POST
+//	Input: JSON {app_id, grace_period_seconds}
+//	Output: JSON, unsanitized App record
+func (s *Service) RotateWebhookSecret(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	var input appclient.RotateWebhookSecretRequest
+	if err = json.NewDecoder(req.Body).Decode(&input); err != nil {
+		err = utils.NewInvalidError(err, "failed to unmarshal incoming request")
+		return
+	}
+	gracePeriod := proxy.DefaultWebhookSecretGracePeriod
+	if input.GracePeriodSeconds != nil {
+		gracePeriod = time.Duration(*input.GracePeriodSeconds) * time.Second
+	}
+	app, _, err := s.Proxy.RotateWebhookSecret(r, input.AppID, gracePeriod)
+	if err != nil {
+		return
+	}
+	_ = httputils.WriteJSON(w, app)
+}
+
+// RotateJWTSigningKey generates a new key for signing the outgoing JWTs with
+// an algorithm. The previous key remains published for 24 hours.
+//
+//	Path: /api/v1/rotate-jwt-signing-key
+//	Method: POST
+//	Input: JSON {algorithm}
+//	Output: JSON {kid}
+func (s *Service) RotateJWTSigningKey(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	var input appclient.RotateJWTSigningKeyRequest
+	if err = json.NewDecoder(req.Body).Decode(&input); err != nil {
+		err = utils.NewInvalidError(err, "failed to unmarshal incoming request")
+		return
+	}
+	kid, err := s.Proxy.RotateJWTSigningKey(r, input.Algorithm)
+	if err != nil {
+		return
+	}
+	_ = httputils.WriteJSON(w, appclient.RotateJWTSigningKeyResponse{KeyID: kid})
+}
+
+// SetAppTLS replaces the TLS configuration for the connections to an App, e.g.
+// the client certificate for mutual TLS.
+//
+//	Path: /api/v1/app-tls
+//	Method: POST
+//	Input: JSON {app_id, tls: {client_certificate, client_key, root_cas, server_name}}
+//	Output: text message of operation's success.
+func (s *Service) SetAppTLS(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	var input apps.App
+	if err = json.NewDecoder(req.Body).Decode(&input); err != nil {
+		err = utils.NewInvalidError(err, "failed to unmarshal incoming request")
+		return
+	}
+	tlsConfig := apps.TLSConfig{}
+	if input.TLS != nil {
+		tlsConfig = *input.TLS
+	}
+	text, err := s.Proxy.SetAppTLS(r, input.AppID, tlsConfig)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write([]byte(text))
+}
+
+// SetWebhookAllowlist replaces the source IP allowlist for an App's remote
+// webhooks.
+//
+//	Path: /api/v1/webhook-allowlist
+//	Method: POST
+//	Input: JSON {app_id, webhook_allowlist: {cidrs, paths}}
+//	Output: text message of operation's success.
+func (s *Service) SetWebhookAllowlist(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	var input apps.App
+	if err = json.NewDecoder(req.Body).Decode(&input); err != nil {
+		err = utils.NewInvalidError(err, "failed to unmarshal incoming request")
+		return
+	}
+	allowlist := apps.RemoteWebhookAllowlist{}
+	if input.WebhookAllowlist != nil {
+		allowlist = *input.WebhookAllowlist
+	}
+	text, err := s.Proxy.SetWebhookAllowlist(r, input.AppID, allowlist)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write([]byte(text))
+}
+
+// StoreWasmModule uploads the WebAssembly module for a version of a wasm App.
+//
+//	Path: /api/v1/wasm-module?app_id={AppID}&version={Version}
+//	Method: POST
+//	Input: the binary module, up to 8MB
+//	Output: text message of operation's success.
+func (s *Service) StoreWasmModule(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	appID := apps.AppID(req.URL.Query().Get("app_id"))
+	version := apps.AppVersion(req.URL.Query().Get("version"))
+	if appID == "" || version == "" {
+		err = utils.NewInvalidError("app_id and version are required")
+		return
+	}
+	data, err := httputils.LimitReadAll(req.Body, store.MaxWasmModuleSize)
+	if err != nil {
+		return
+	}
+	text, err := s.Proxy.StoreWasmModule(r, appID, version, data)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write([]byte(text))
+}
+
 // GetApp returns the App's record. If requestor is a system administrator, the
 // raw record with secrets is returned, otherwise the output is sanitized.
 //
@@ -154,6 +288,81 @@ func (s *Service) GetApp(r *incoming.Request, w http.ResponseWriter, req *http.R
 	_ = httputils.WriteJSON(w, app)
 }
 
+// GetWebhookDeliveries returns the most recent remote webhook deliveries for an
+// App, as recorded by this Mattermost server.
+//
+//	Path: /api/v1/webhook-deliveries?app_id={AppID}
+//	Method: GET
+//	Input: none
+//	Output: JSON []WebhookDelivery, most recent first
+func (s *Service) GetWebhookDeliveries(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	appID := apps.AppID(req.URL.Query().Get("app_id"))
+	if appID == "" {
+		err = utils.NewInvalidError("app_id is required")
+		return
+	}
+	deliveries, err := s.Proxy.GetWebhookDeliveries(r, appID)
+	if err != nil {
+		return
+	}
+	_ = httputils.WriteJSON(w, deliveries)
+}
+
+// GetAuditLog returns the audit log records of administrative and privileged
+// actions, most recent first. With format=jsonl the records are exported as
+// JSON lines, one record per line.
+//
+//	Path: /api/v1/audit?app_id={AppID}&user_id={UserID}&action={Action}&since={RFC3339}&until={RFC3339}&limit={N}&format={json|jsonl}
+//	Method: GET
+//	Input: none
+//	Output: JSON []AuditRecord, or JSON lines
+func (s *Service) GetAuditLog(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var err error
+	defer func() { httputils.WriteErrorIfNeeded(w, err) }()
+
+	q, err := apps.ParseAuditQuery(req.URL.Query())
+	if err != nil {
+		return
+	}
+	records, err := s.Proxy.GetAuditLog(r, q)
+	if err != nil {
+		return
+	}
+
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "json":
+		_ = httputils.WriteJSON(w, records)
+	case "jsonl":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="apps-audit.jsonl"`)
+		enc := json.NewEncoder(w)
+		for _, rec := range records {
+			_ = enc.Encode(rec)
+		}
+	default:
+		err = utils.NewInvalidError("unsupported format %q, must be json or jsonl", format)
+	}
+}
+
+// GetMetrics serves the plugin's Prometheus metrics: app call counts and
+// latencies, upstream errors, notifications and bindings fetch times.
+//
+//	Path: /api/v1/metrics
+//	Method: GET
+//	Input: none
+//	Output: Prometheus text exposition format
+func (s *Service) GetMetrics(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	h, err := s.Proxy.GetMetricsHandler(r)
+	if err != nil {
+		httputils.WriteErrorIfNeeded(w, err)
+		return
+	}
+	h.ServeHTTP(w, req)
+}
+
 func (s *Service) GetMarketplace(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
 	filter := req.URL.Query().Get("filter")
 	includePlugins, _ := strconv.ParseBool(req.URL.Query().Get("include_plugins"))
@@ -1,34 +1,42 @@
 package httpin
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/apps/appclient"
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
 )
 
-// GetBindings returns combined bindings for all Apps.
+// GetBindings returns combined bindings for all Apps. With status=true, the
+// output also includes the status of each App's bindings, so that the user
+// agent can indicate the Apps that failed to provide them in time.
 //
 //	Path: /api/v1/bindings
 //	Method: GET
 //	Input: none
-//	Output: []Binding
+//	Output: []Binding, or BindingsResponse if status=true
 func (s *Service) GetBindings(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
 	q := req.URL.Query()
-	bindings, err := s.Proxy.GetBindings(r, apps.Context{
+	resp, err := s.Proxy.GetBindings(r, apps.Context{
 		UserAgentContext: apps.UserAgentContext{
 			TeamID:    q.Get(config.PropTeamID),
 			ChannelID: q.Get(config.PropChannelID),
 			UserAgent: q.Get(config.PropUserAgent),
 		},
 	})
+	if resp == nil {
+		resp = &apps.BindingsResponse{}
+	}
 
 	apiTestFlag := q.Get("test") != ""
 	if apiTestFlag {
 		testOut := map[string]interface{}{
-			"bindings": bindings,
+			"bindings": resp.Bindings,
+			"status":   resp.Status,
 		}
 		if err != nil {
 			testOut["error"] = err.Error()
@@ -37,5 +45,28 @@ func (s *Service) GetBindings(r *incoming.Request, w http.ResponseWriter, req *h
 		return
 	}
 
-	_ = httputils.WriteJSON(w, bindings)
+	if q.Get("status") == "true" {
+		_ = httputils.WriteJSON(w, resp)
+		return
+	}
+	_ = httputils.WriteJSON(w, resp.Bindings)
+}
+
+// InvalidateBindings removes the calling App's cached bindings, and refreshes
+// them in the user agents.
+//
+//	Path: /api/v1/bindings/invalidate
+//	Method: POST
+//	Input: appclient.InvalidateBindingsRequest
+//	Output: None
+func (s *Service) InvalidateBindings(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	var in appclient.InvalidateBindingsRequest
+	if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.AppServices.InvalidateBindings(r, in.UserID); err != nil {
+		http.Error(w, err.Error(), httputils.ErrorToStatus(err))
+		return
+	}
 }
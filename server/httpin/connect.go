@@ -0,0 +1,44 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package httpin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+var connectUpgrader = websocket.Upgrader{
+	// Apps are not browsers, and authenticate with a token rather than
+	// cookies, so the origin is not checked.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// Connect accepts a WebSocket connection from an app deployed as type
+// "websocket", and serves requests to the app over it until it is closed.
+//
+//	Path: /apps/{AppID}/connect
+//	Method: GET
+//	Input: Mattermost-App-Authorization header, "Bearer {JWT}"
+func (s *Service) Connect(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	token := strings.TrimPrefix(req.Header.Get(apps.OutgoingAuthHeader), "Bearer ")
+
+	upgraded := false
+	err := s.Proxy.ConnectApp(r, token, func() (*websocket.Conn, error) {
+		upgraded = true
+		return connectUpgrader.Upgrade(w, req, nil)
+	})
+	if err != nil {
+		r.Log.WithError(err).Warnw("failed to serve app connection")
+		// The upgrader writes the error response itself.
+		if !upgraded {
+			httputils.WriteErrorIfNeeded(w, err)
+		}
+	}
+}
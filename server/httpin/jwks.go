@@ -0,0 +1,28 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package httpin
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+// GetJWKS returns the public keys for verifying the JWTs sent to the apps.
+//
+//	Path: /api/v1/jwks.json
+//	Method: GET
+//	Input: none
+//	Output: JSON Web Key Set
+func (s *Service) GetJWKS(r *incoming.Request, w http.ResponseWriter, req *http.Request) {
+	set, err := s.Proxy.GetJWKS()
+	if err != nil {
+		r.Log.WithError(err).Warnf("Failed to get the JWT signing keys")
+		httputils.WriteErrorIfNeeded(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = httputils.WriteJSON(w, set)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/server/proxy"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 const AppIDVar = "appid"
@@ -46,8 +47,8 @@ func NewService(proxy proxy.Service, appservices appservices.Service, conf confi
 	// Set up the "gateway" endpoints (APIs and pages/files) in the app's namespace, /{appid}/...
 	h := rootHandler.PathPrefix(path.Apps + AppIDPath)
 
-	// Static files.
-	h.HandleFunc(path.Static+"/{name}", h.Static).Methods(http.MethodGet)
+	// Static files, the name may be a nested path.
+	h.HandleFunc(path.Static+"/{name:.+}", h.Static).Methods(http.MethodGet)
 
 	// Incoming remote webhooks.
 	h.HandleFunc(path.Webhook, h.Webhook).Methods(http.MethodPost)
@@ -55,6 +56,9 @@ func NewService(proxy proxy.Service, appservices appservices.Service, conf confi
 	h.HandleFunc(path.Webhook, h.WebhookValidateAuthentication).Methods(http.MethodHead)
 	h.HandleFunc(path.Webhook+"/{path}", h.WebhookValidateAuthentication).Methods(http.MethodHead)
 
+	// Persistent connections from apps deployed as type "websocket".
+	h.HandleFunc(path.Connect, h.Connect).Methods(http.MethodGet)
+
 	// Remote OAuth2: /{appid}/oauth2/remote/connect and /{appid}/oauth2/remote/complete
 	h.HandleFunc(path.RemoteOAuth2Connect, h.RemoteOAuth2Connect).Methods(http.MethodGet)
 	h.HandleFunc(path.RemoteOAuth2Complete, h.RemoteOAuth2Complete).Methods(http.MethodGet)
@@ -65,6 +69,9 @@ func NewService(proxy proxy.Service, appservices appservices.Service, conf confi
 	// Ping.
 	h.HandleFunc(path.Ping, h.Ping).Methods(http.MethodPost)
 
+	// Public keys of the outgoing JWTs, no authentication required.
+	h.HandleFunc(path.JWKS, h.GetJWKS).Methods(http.MethodGet)
+
 	// User-agent APIs.
 	h.HandleFunc(path.Call, h.Call).Methods(http.MethodPost)
 	h.HandleFunc(path.Bindings, h.GetBindings).Methods(http.MethodGet)
@@ -86,14 +93,23 @@ func NewService(proxy proxy.Service, appservices appservices.Service, conf confi
 	h.HandleFunc(path.Subscribe, h.Subscribe).Methods(http.MethodPost)
 	h.HandleFunc(path.Unsubscribe, h.Unsubscribe).Methods(http.MethodPost)
 	h.HandleFunc(path.TimerCreate, h.CreateTimer).Methods(http.MethodPost)
+	h.HandleFunc(path.InvalidateBindings, h.InvalidateBindings).Methods(http.MethodPost)
 
 	// Admin API, can be used by plugins, external services, or the user agent.
+	h.HandleFunc(path.AppTLS, h.SetAppTLS).Methods(http.MethodPost)
+	h.HandleFunc(path.Audit, h.GetAuditLog).Methods(http.MethodGet)
 	h.HandleFunc(path.DisableApp, h.DisableApp).Methods(http.MethodPost)
 	h.HandleFunc(path.EnableApp, h.EnableApp).Methods(http.MethodPost)
 	h.HandleFunc(path.InstallApp, h.InstallApp).Methods(http.MethodPost)
 	h.HandleFunc(path.Marketplace, h.GetMarketplace).Methods(http.MethodGet)
+	h.HandleFunc(path.Metrics, h.GetMetrics).Methods(http.MethodGet)
+	h.HandleFunc(path.RotateJWTSigningKey, h.RotateJWTSigningKey).Methods(http.MethodPost)
+	h.HandleFunc(path.RotateWebhookSecret, h.RotateWebhookSecret).Methods(http.MethodPost)
 	h.HandleFunc(path.UninstallApp, h.UninstallApp).Methods(http.MethodPost)
 	h.HandleFunc(path.UpdateAppListing, h.UpdateAppListing).Methods(http.MethodPost)
+	h.HandleFunc(path.WasmModule, h.StoreWasmModule).Methods(http.MethodPost)
+	h.HandleFunc(path.WebhookAllowlist, h.SetWebhookAllowlist).Methods(http.MethodPost)
+	h.HandleFunc(path.WebhookDeliveries, h.GetWebhookDeliveries).Methods(http.MethodGet)
 	h.PathPrefix(path.Apps).PathPrefix(`/{appid:[A-Za-z0-9-_.]+}`).HandleFunc("", h.GetApp).Methods(http.MethodGet)
 
 	return rootHandler
@@ -129,8 +145,18 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		r = r.WithDestination(apps.AppID(s))
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), s.Config.Get().IncomingRequestTimeout())
 	defer cancel()
+	ctx = tracing.Extract(ctx, req.Header)
+	spanName := req.URL.Path
+	if route := mux.CurrentRoute(req); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			spanName = tmpl
+		}
+	}
+	ctx, span := tracing.Start(ctx, req.Method+" "+spanName,
+		tracing.RequestIDKey.String(r.RequestID()))
+	defer span.End()
 	r = r.WithCtx(ctx)
 
 	r.Log = r.Log.With(
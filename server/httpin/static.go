@@ -2,7 +2,10 @@ package httpin
 
 import (
 	"io"
+	"mime"
 	"net/http"
+	"path"
+	"strings"
 
 	"github.com/gorilla/mux"
 
@@ -17,32 +20,57 @@ func (s *Service) Static(r *incoming.Request, w http.ResponseWriter, req *http.R
 		httputils.WriteErrorIfNeeded(w, utils.NewInvalidError("invalid URL format"))
 		return
 	}
-	assetName, err := utils.CleanStaticURL(vars["name"])
+	assetName, err := utils.CleanStaticPath(vars["name"])
 	if err != nil {
 		httputils.WriteErrorIfNeeded(w, err)
 		return
 	}
 
-	body, status, err := s.Proxy.InvokeGetStatic(r, assetName)
+	asset, status, err := s.Proxy.InvokeGetStatic(r, assetName)
 	if err != nil {
 		r.Log.WithError(err).Debugw("Failed to get asset", "asset_name", assetName)
 		httputils.WriteErrorIfNeeded(w, err)
 		return
 	}
+	defer asset.Close()
 
-	copyHeader(w.Header(), req.Header)
-	w.WriteHeader(status)
-	if _, err := io.Copy(w, body); err != nil {
-		httputils.WriteErrorIfNeeded(w, err)
-		return
+	contentType := asset.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(assetName))
 	}
-	if err := body.Close(); err != nil {
-		httputils.WriteErrorIfNeeded(w, err)
-		return
+	if contentType != "" {
+		w.Header().Set("Content-Type", httputils.PassiveContentType(contentType))
 	}
-}
+	// The asset is served from the Mattermost origin, make sure it is never
+	// rendered as active content.
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
 
-func copyHeader(dst, src http.Header) {
-	headerKey := "Content-Type"
-	dst.Add(headerKey, src.Get(headerKey))
+	if status == http.StatusOK {
+		if asset.ETag != "" {
+			w.Header().Set("ETag", asset.ETag)
+		}
+		// Let the clients cache the asset, but have them revalidate it since
+		// the app may change it at any time.
+		w.Header().Set("Cache-Control", "no-cache")
+
+		// Cached assets are seekable, ServeContent answers conditional and
+		// range requests for them.
+		if content, ok := asset.ReadCloser.(io.ReadSeeker); ok {
+			http.ServeContent(w, req, assetName, asset.LastModified, content)
+			return
+		}
+		if asset.ETag != "" && strings.Contains(req.Header.Get("If-None-Match"), asset.ETag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if !asset.LastModified.IsZero() {
+			w.Header().Set("Last-Modified", asset.LastModified.UTC().Format(http.TimeFormat))
+		}
+	}
+
+	w.WriteHeader(status)
+	if _, err := io.Copy(w, asset); err != nil {
+		r.Log.WithError(err).Debugw("Failed to write asset", "asset_name", assetName)
+	}
 }
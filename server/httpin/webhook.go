@@ -4,9 +4,11 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
 	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
 )
 
@@ -18,7 +20,7 @@ func (s *Service) Webhook(r *incoming.Request, w http.ResponseWriter, req *http.
 	}
 }
 
-func (s *Service) doHandleWebhook(r *incoming.Request, _ http.ResponseWriter, req *http.Request) error {
+func (s *Service) doHandleWebhook(r *incoming.Request, w http.ResponseWriter, req *http.Request) error {
 	sreq, err := newHTTPCallRequest(req, s.Config.Get().MaxWebhookSize)
 	if err != nil {
 		return err
@@ -26,11 +28,30 @@ func (s *Service) doHandleWebhook(r *incoming.Request, _ http.ResponseWriter, re
 	sreq.Path = mux.Vars(req)["path"]
 	r.Log = r.Log.With("call_path", sreq.Path)
 
-	err = s.Proxy.InvokeRemoteWebhook(r, *sreq)
+	err = s.checkWebhookSourceIP(r, req, *sreq)
 	if err != nil {
 		return err
 	}
 
+	resp, err := s.Proxy.InvokeRemoteWebhook(r, *sreq)
+	if err != nil {
+		return err
+	}
+
+	if resp != nil {
+		for key, value := range resp.Headers {
+			w.Header().Set(key, value)
+		}
+		// The response is served from the Mattermost origin, make sure it is
+		// never rendered as active content.
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "sandbox")
+		w.WriteHeader(resp.StatusCode)
+		_, _ = w.Write([]byte(resp.Body))
+		r.Log.Debugw("processed remote webhook synchronously", "status", resp.StatusCode)
+		return nil
+	}
+
 	r.Log.Debugf("processed remote webhook")
 	return nil
 }
@@ -52,6 +73,11 @@ func (s *Service) doHandleWebhookAuthentication(r *incoming.Request, _ http.Resp
 	sreq.Path = mux.Vars(req)["path"]
 	r.Log = r.Log.With("call_path", sreq.Path)
 
+	err = s.checkWebhookSourceIP(r, req, *sreq)
+	if err != nil {
+		return err
+	}
+
 	err = s.Proxy.ValidateWebhookAuthentication(r, *sreq)
 	if err != nil {
 		return err
@@ -61,6 +87,31 @@ func (s *Service) doHandleWebhookAuthentication(r *incoming.Request, _ http.Resp
 	return nil
 }
 
+// checkWebhookSourceIP enforces the app's webhook allowlist, if any. The
+// rejected requests are recorded in the app's webhook log.
+func (s *Service) checkWebhookSourceIP(r *incoming.Request, req *http.Request, sreq apps.HTTPCallRequest) error {
+	app, err := s.Proxy.GetInstalledApp(r.Destination(), false)
+	if err != nil {
+		return err
+	}
+	if app.WebhookAllowlist == nil || app.WebhookAllowlist.IsEmpty() {
+		return nil
+	}
+
+	conf := s.Config.Get()
+	ip := httputils.ClientIP(req, conf.TrustedProxyRanges, conf.ClientIPHeader)
+	allowed, err := app.WebhookAllowlist.Allows(sreq.Path, ip)
+	if err != nil {
+		return errors.Wrap(err, "invalid webhook allowlist")
+	}
+	if !allowed {
+		err = utils.NewForbiddenError("source IP address %s is not allowed to send webhooks to %s", ip, app.AppID)
+		s.Proxy.RecordRejectedWebhook(r, sreq, err)
+		return err
+	}
+	return nil
+}
+
 func newHTTPCallRequest(req *http.Request, limit int) (*apps.HTTPCallRequest, error) {
 	data, err := httputils.LimitReadAll(req.Body, limit)
 	if err != nil {
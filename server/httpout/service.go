@@ -4,6 +4,10 @@
 package httpout
 
 import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost/server/v8/platform/services/httpservice"
@@ -17,7 +21,23 @@ type Service interface {
 	config.Configurable
 	httpservice.HTTPService
 
+	// MakeClient returns a client like httpservice's, with a timeout long
+	// enough for the longest allowed call, instead of its fixed 30 seconds,
+	// see config.Config.IncomingRequestTimeout. The calls are also bounded by
+	// the deadlines of their contexts.
+	MakeClient(trusted bool) *http.Client
+
 	GetFromURL(url string, trusted bool, limit int) ([]byte, error)
+
+	// MakeTLSClient returns a client like MakeClient, that uses tlsConfig for
+	// the TLS connections, e.g. to present a client certificate.
+	MakeTLSClient(trusted bool, tlsConfig *tls.Config) *http.Client
+
+	// MakeInternalClient returns an untrusted client that is also allowed to
+	// connect to the internal hosts accepted by allowHost, e.g. the services
+	// of a Kubernetes cluster. Unlike a trusted client, it can not reach any
+	// other internal addresses.
+	MakeInternalClient(allowHost func(host string) bool) *http.Client
 }
 
 type service struct {
@@ -41,6 +61,49 @@ func (s *service) Configure(_ config.Config, _ utils.Logger) error {
 	return nil
 }
 
+func (s *service) MakeClient(trusted bool) *http.Client {
+	client := s.HTTPService.MakeClient(trusted)
+	client.Timeout = s.conf.Get().IncomingRequestTimeout()
+	return client
+}
+
+func (s *service) MakeTLSClient(trusted bool, tlsConfig *tls.Config) *http.Client {
+	client := s.MakeClient(trusted)
+	mmTransport, ok := client.Transport.(*httpservice.MattermostTransport)
+	if !ok {
+		return client
+	}
+	transport, ok := mmTransport.Transport.(*http.Transport)
+	if !ok {
+		return client
+	}
+
+	transport = transport.Clone()
+	conf := tlsConfig.Clone()
+	// Keep the server's setting for insecure outgoing connections, unless the
+	// CAs to trust are set explicitly.
+	if transport.TLSClientConfig != nil && conf.RootCAs == nil {
+		conf.InsecureSkipVerify = transport.TLSClientConfig.InsecureSkipVerify
+	}
+	transport.TLSClientConfig = conf
+	client.Transport = &httpservice.MattermostTransport{Transport: transport}
+	return client
+}
+
+func (s *service) MakeInternalClient(allowHost func(host string) bool) *http.Client {
+	mmconf := s.conf.MattermostConfig().Config()
+	insecure := mmconf.ServiceSettings.EnableInsecureOutgoingConnections != nil &&
+		*mmconf.ServiceSettings.EnableInsecureOutgoingConnections
+	allowIP := func(ip net.IP) bool {
+		own, err := httpservice.IsOwnIP(ip)
+		return err == nil && !own && !httpservice.IsReservedIP(ip)
+	}
+	return &http.Client{
+		Transport: httpservice.NewTransport(insecure, allowHost, allowIP),
+		Timeout:   s.conf.Get().IncomingRequestTimeout(),
+	}
+}
+
 func (s *service) GetFromURL(url string, trusted bool, limit int) ([]byte, error) {
 	client := s.MakeClient(trusted)
 	resp, err := client.Get(url)
@@ -0,0 +1,38 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package httpout
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+)
+
+func TestClientTimeout(t *testing.T) {
+	for name, tc := range map[string]struct {
+		maxCallTimeoutSeconds int
+		expected              time.Duration
+	}{
+		"default":   {expected: config.RequestTimeout},
+		"short max": {maxCallTimeoutSeconds: 10, expected: config.RequestTimeout},
+		"long max":  {maxCallTimeoutSeconds: 120, expected: 120 * time.Second},
+	} {
+		t.Run(name, func(t *testing.T) {
+			conf := config.NewTestConfigService(&config.Config{
+				StoredConfig: config.StoredConfig{MaxCallTimeoutSeconds: tc.maxCallTimeoutSeconds},
+			}).WithMattermostConfig(model.Config{})
+			s := NewService(conf)
+
+			require.Equal(t, tc.expected, s.MakeClient(false).Timeout)
+			require.Equal(t, tc.expected, s.MakeTLSClient(false, &tls.Config{MinVersion: tls.VersionTLS12}).Timeout)
+			require.Equal(t, tc.expected, s.MakeInternalClient(func(string) bool { return false }).Timeout)
+		})
+	}
+}
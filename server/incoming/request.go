@@ -56,6 +56,10 @@ func (r *Request) Clone() *Request {
 	return &clone
 }
 
+func (r *Request) RequestID() string {
+	return r.requestID
+}
+
 func (r *Request) Ctx() context.Context {
 	return r.ctx
 }
@@ -0,0 +1,168 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// Package metrics collects the Prometheus metrics of the Apps plugin: app
+// calls, upstream errors, subscription notifications, and bindings fetches.
+// The metrics are kept in a private registry, exposed via Handler.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+const (
+	Namespace = "mattermost"
+	Subsystem = "plugin_apps"
+)
+
+// Label names.
+const (
+	AppIDLabel        = "app_id"
+	DeployTypeLabel   = "deploy_type"
+	NodeLabel         = "node"
+	PathLabel         = "path"
+	ResponseTypeLabel = "response_type"
+	SubjectLabel      = "subject"
+)
+
+// ResponseTypeFailed is used as the response_type label for calls that failed
+// before a response was received from the app.
+const ResponseTypeFailed = "failed"
+
+// PathOther is used as the path label for the calls to an app once
+// MaxPathsPerApp distinct paths of the app have been recorded. The users can
+// call any path, each distinct label value would otherwise create a new time
+// series.
+const PathOther = "other"
+
+// MaxPathsPerApp is the maximum number of distinct path label values recorded
+// for each app, on each server.
+const MaxPathsPerApp = 50
+
+// Metrics is the set of collectors for the plugin. A nil *Metrics is valid and
+// records nothing.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	callDuration     *prometheus.HistogramVec
+	upstreamErrors   *prometheus.CounterVec
+	notifications    *prometheus.CounterVec
+	bindingsDuration *prometheus.HistogramVec
+
+	pathsMutex sync.Mutex
+	paths      map[apps.AppID]map[string]bool
+}
+
+// New returns the metrics of the server identified by node. The metrics are
+// collected on each server in the cluster, all of them are labeled with node
+// so that the series of the servers can be told apart.
+func New(node string) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		paths:    map[apps.AppID]map[string]bool{},
+		callDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "call_duration_seconds",
+			Help:      "Duration of the calls to apps, by app, call path (\"other\" past the per-app limit), and response type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{AppIDLabel, PathLabel, ResponseTypeLabel}),
+		upstreamErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "upstream_errors_total",
+			Help:      "Number of failed requests to app upstreams, by deploy type.",
+		}, []string{DeployTypeLabel}),
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "notifications_total",
+			Help:      "Number of notifications sent to apps for subscribed events, by subject.",
+		}, []string{SubjectLabel}),
+		bindingsDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "bindings_fetch_duration_seconds",
+			Help:      "Duration of fetching bindings from apps, by app.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{AppIDLabel}),
+	}
+
+	prometheus.WrapRegistererWith(prometheus.Labels{NodeLabel: node}, m.registry).MustRegister(
+		m.callDuration,
+		m.upstreamErrors,
+		m.notifications,
+		m.bindingsDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler returns the HTTP handler that serves the metrics in the Prometheus
+// exposition format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveCall records a completed call to an app. responseType should be
+// ResponseTypeFailed if no response was received. The path is recorded as
+// PathOther past the first MaxPathsPerApp distinct paths of the app.
+func (m *Metrics) ObserveCall(appID apps.AppID, path, responseType string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.callDuration.WithLabelValues(string(appID), m.pathLabel(appID, path), responseType).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) pathLabel(appID apps.AppID, path string) string {
+	m.pathsMutex.Lock()
+	defer m.pathsMutex.Unlock()
+	paths := m.paths[appID]
+	if paths == nil {
+		paths = map[string]bool{}
+		m.paths[appID] = paths
+	}
+	if !paths[path] {
+		if len(paths) >= MaxPathsPerApp {
+			return PathOther
+		}
+		paths[path] = true
+	}
+	return path
+}
+
+// IncUpstreamErrors counts a failed request to an upstream of the deploy type.
+func (m *Metrics) IncUpstreamErrors(deployType apps.DeployType) {
+	if m == nil {
+		return
+	}
+	m.upstreamErrors.WithLabelValues(string(deployType)).Inc()
+}
+
+// AddNotifications counts the notifications fanned out for an event subject.
+func (m *Metrics) AddNotifications(subject apps.Subject, n int) {
+	if m == nil || n == 0 {
+		return
+	}
+	m.notifications.WithLabelValues(string(subject)).Add(float64(n))
+}
+
+// ObserveBindingsFetch records the time it took to fetch an app's bindings.
+func (m *Metrics) ObserveBindingsFetch(appID apps.AppID, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.bindingsDuration.WithLabelValues(string(appID)).Observe(elapsed.Seconds())
+}
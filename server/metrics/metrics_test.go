@@ -0,0 +1,71 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New("node1")
+	m.ObserveCall("app1", "/submit", string(apps.CallResponseTypeOK), 10*time.Millisecond)
+	m.ObserveCall("app1", "/submit", string(apps.CallResponseTypeOK), 20*time.Millisecond)
+	m.ObserveCall("app1", "/submit", ResponseTypeFailed, time.Second)
+	m.ObserveCall("app1", "/random1", string(apps.CallResponseTypeOK), time.Second)
+	m.IncUpstreamErrors(apps.DeployHTTP)
+	m.AddNotifications(apps.SubjectChannelCreated, 3)
+	m.AddNotifications(apps.SubjectChannelCreated, 0)
+	m.ObserveBindingsFetch("app1", 5*time.Millisecond)
+
+	require.Equal(t, 3, testutil.CollectAndCount(m.callDuration))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.upstreamErrors.WithLabelValues(string(apps.DeployHTTP))))
+	require.Equal(t, float64(3), testutil.ToFloat64(m.notifications.WithLabelValues(string(apps.SubjectChannelCreated))))
+	require.Equal(t, 1, testutil.CollectAndCount(m.bindingsDuration))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `mattermost_plugin_apps_call_duration_seconds_count{app_id="app1",node="node1",path="/submit",response_type="ok"} 2`)
+	require.Contains(t, string(body), `mattermost_plugin_apps_call_duration_seconds_count{app_id="app1",node="node1",path="/submit",response_type="failed"} 1`)
+	require.Contains(t, string(body), `mattermost_plugin_apps_call_duration_seconds_count{app_id="app1",node="node1",path="/random1",response_type="ok"} 1`)
+	require.Contains(t, string(body), `mattermost_plugin_apps_upstream_errors_total{deploy_type="http",node="node1"} 1`)
+	require.Contains(t, string(body), `mattermost_plugin_apps_notifications_total{node="node1",subject="channel_created"} 3`)
+	require.Contains(t, string(body), `mattermost_plugin_apps_bindings_fetch_duration_seconds_count{app_id="app1",node="node1"} 1`)
+
+	t.Run("nil metrics", func(t *testing.T) {
+		var m *Metrics
+		m.ObserveCall("app1", "/submit", "ok", time.Second)
+		m.IncUpstreamErrors(apps.DeployHTTP)
+		m.AddNotifications(apps.SubjectChannelCreated, 1)
+		m.ObserveBindingsFetch("app1", time.Second)
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPathLabel(t *testing.T) {
+	m := New("node1")
+	for i := 0; i < MaxPathsPerApp; i++ {
+		path := fmt.Sprintf("/path%v", i)
+		require.Equal(t, path, m.pathLabel("app1", path))
+	}
+	require.Equal(t, PathOther, m.pathLabel("app1", "/one-too-many"))
+
+	// The paths already recorded, and the paths of the other apps, are kept.
+	require.Equal(t, "/path0", m.pathLabel("app1", "/path0"))
+	require.Equal(t, "/one-too-many", m.pathLabel("app2", "/one-too-many"))
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	gohttp "net/http"
+	"os"
 	"path/filepath"
 
 	"github.com/pkg/errors"
@@ -22,11 +23,13 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/httpin"
 	"github.com/mattermost/mattermost-plugin-apps/server/httpout"
+	"github.com/mattermost/mattermost-plugin-apps/server/metrics"
 	"github.com/mattermost/mattermost-plugin-apps/server/proxy"
 	"github.com/mattermost/mattermost-plugin-apps/server/session"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
 	"github.com/mattermost/mattermost-plugin-apps/server/telemetry"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 type Plugin struct {
@@ -45,6 +48,17 @@ type Plugin struct {
 
 	telemetryClient mmtelemetry.Client
 	tracker         *telemetry.Telemetry
+	tracing         *tracing.Provider
+}
+
+// tracingConfigurator applies the tracing configuration when the plugin is
+// re-configured.
+type tracingConfigurator struct {
+	*tracing.Provider
+}
+
+func (t tracingConfigurator) Configure(conf config.Config, log utils.Logger) error {
+	return t.Provider.Configure(conf.TracingOptions(), log)
 }
 
 func NewPlugin(pluginManifest model.Manifest) *Plugin {
@@ -104,6 +118,13 @@ func (p *Plugin) OnActivate() (err error) {
 	conf := p.conf.Get()
 	log.With(conf).Debugw("configured the plugin.")
 
+	// Initialize tracing, failing to export the spans should not prevent the
+	// plugin from activating.
+	p.tracing = tracing.NewProvider()
+	if err = p.tracing.Configure(conf.TracingOptions(), log); err != nil {
+		log.WithError(err).Warnf("failed to configure tracing")
+	}
+
 	// Initialize outgoing HTTP.
 	p.httpOut = httpout.NewService(p.conf)
 
@@ -130,7 +151,12 @@ func (p *Plugin) OnActivate() (err error) {
 		return errors.Wrapf(err, "failed creating cluster mutex")
 	}
 
-	p.proxy = proxy.NewService(p.conf, p.store, mutex, p.httpOut, p.sessionService, p.appservices)
+	// The metrics are per server, labeled with its host name.
+	node, err := os.Hostname()
+	if err != nil {
+		node = model.NewId()
+	}
+	p.proxy = proxy.NewService(p.conf, p.store, mutex, p.httpOut, p.sessionService, p.appservices, metrics.New(node))
 	err = p.proxy.Configure(conf, log)
 	if err != nil {
 		return errors.Wrapf(err, "failed to initialize app proxy")
@@ -171,6 +197,10 @@ func (p *Plugin) OnDeactivate() error { //nolint:golint,unparam
 		}
 	}
 
+	if p.tracing != nil {
+		p.tracing.Shutdown(p.conf.NewBaseLogger())
+	}
+
 	return nil
 }
 
@@ -192,7 +222,7 @@ func (p *Plugin) OnConfigurationChange() error {
 		return err
 	}
 
-	err = p.conf.Reconfigure(sc, false, p.store.App, p.store.Manifest, p.proxy)
+	err = p.conf.Reconfigure(sc, false, p.store.App, p.store.Manifest, p.proxy, tracingConfigurator{p.tracing})
 	if err != nil {
 		p.API.LogInfo("failed to reconfigure", "error", err.Error())
 		return err
@@ -204,6 +234,10 @@ func (p *Plugin) ServeHTTP(c *plugin.Context, w gohttp.ResponseWriter, req *goht
 	p.httpIn.ServePluginHTTP(c, w, req)
 }
 
+func (p *Plugin) OnPluginClusterEvent(_ *plugin.Context, ev model.PluginClusterEvent) {
+	p.proxy.OnPluginClusterEvent(ev)
+}
+
 func (p *Plugin) UserHasBeenCreated(_ *plugin.Context, user *model.User) {
 	p.proxy.NotifyUserCreated(user.Id)
 }
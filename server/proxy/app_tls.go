@@ -0,0 +1,80 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/uphttp"
+)
+
+var _ uphttp.ClientKeys = (*Proxy)(nil)
+
+// SetAppTLS replaces the TLS configuration for the connections to an app, e.g.
+// the client certificate for mutual TLS. An empty configuration removes it.
+func (p *Proxy) SetAppTLS(r *incoming.Request, appID apps.AppID, tlsConfig apps.TLSConfig) (string, error) {
+	if err := r.Check(
+		r.RequireActingUser,
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return "", err
+	}
+	if err := tlsConfig.Validate(); err != nil {
+		return "", err
+	}
+
+	app, err := p.GetInstalledApp(appID, false)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get app. appID: %s", appID)
+	}
+
+	// The client key is kept out of the App record. It is saved before the
+	// record, so that the servers that see the new certificate find its key.
+	clientKey := tlsConfig.ClientKey
+	tlsConfig.ClientKey = ""
+	if clientKey != "" {
+		err = p.store.TLSKey.Save(appID, clientKey)
+	} else {
+		err = p.store.TLSKey.Delete(appID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	message := fmt.Sprintf("Removed the TLS configuration for %s.", app.DisplayName)
+	if tlsConfig.IsEmpty() {
+		app.TLS = nil
+	} else {
+		app.TLS = &tlsConfig
+		message = fmt.Sprintf("Updated the TLS configuration for %s.", app.DisplayName)
+	}
+
+	err = p.store.App.Save(r, *app)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to save app. appID: %s", appID)
+	}
+
+	r.Log.With(
+		"client_certificate", tlsConfig.ClientCertificate != "",
+		"root_cas", tlsConfig.RootCAs != "",
+		"server_name", tlsConfig.ServerName,
+	).Infof("Updated TLS configuration for app %s", appID)
+	p.store.Audit.Record(r, apps.AuditAppTLSUpdated, appID, map[string]string{
+		"client_certificate": strconv.FormatBool(tlsConfig.ClientCertificate != ""),
+		"root_cas":           strconv.FormatBool(tlsConfig.RootCAs != ""),
+		"server_name":        tlsConfig.ServerName,
+	})
+	return message, nil
+}
+
+// GetAppClientKey returns the private key of the app's TLS client
+// certificate, see uphttp.ClientKeys.
+func (p *Proxy) GetAppClientKey(appID apps.AppID) (string, error) {
+	return p.store.TLSKey.Get(appID)
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+// GetAuditLog returns the audit log records that match the query, most recent
+// first. Only available to system administrators and plugins.
+func (p *Proxy) GetAuditLog(r *incoming.Request, q apps.AuditQuery) ([]apps.AuditRecord, error) {
+	if err := r.Check(
+		r.RequireActingUser,
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, err
+	}
+	return p.store.Audit.Query(r.Ctx(), q)
+}
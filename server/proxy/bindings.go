@@ -1,16 +1,20 @@
 package proxy
 
 import (
+	"context"
 	"sort"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
 	"github.com/mattermost/mattermost/server/public/model"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 func mergeBindings(bb1, bb2 []apps.Binding) []apps.Binding {
@@ -37,9 +41,11 @@ func mergeBindings(bb1, bb2 []apps.Binding) []apps.Binding {
 	return out
 }
 
-// GetBindings fetches bindings for all apps.
+// GetBindings fetches bindings for all apps. Each app's bindings are waited for
+// until its bindings timeout, the apps that fail or time out are represented by
+// their last good bindings, if any, and reported in the status.
 // We should avoid unnecessary logging here as this route is called very often.
-func (p *Proxy) GetBindings(r *incoming.Request, cc apps.Context) (ret []apps.Binding, err error) {
+func (p *Proxy) GetBindings(r *incoming.Request, cc apps.Context) (ret *apps.BindingsResponse, err error) {
 	start := time.Now()
 	var allApps []apps.App
 	defer func() {
@@ -57,41 +63,88 @@ func (p *Proxy) GetBindings(r *incoming.Request, cc apps.Context) (ret []apps.Bi
 		return nil, err
 	}
 
+	ctx, span := tracing.Start(r.Ctx(), "Proxy.GetBindings",
+		tracing.RequestIDKey.String(r.RequestID()),
+	)
+	r = r.WithCtx(ctx)
+	defer func() { tracing.End(span, err) }()
+
 	type result struct {
 		appID    apps.AppID
 		bindings []apps.Binding
+		status   apps.AppBindingsStatus
 		err      error
 	}
 
 	all := make(chan result)
 	defer close(all)
 
+	conf := p.conf.Get()
 	allApps = p.store.App.AsList(store.EnabledAppsOnly)
 	for i := range allApps {
 		go func(app apps.App) {
-			apprequest := r.WithDestination(app.AppID)
+			bindingsPath := app.Bindings.WithDefault(apps.DefaultBindings).Path
+			timeout := conf.BindingsTimeout(app.CallTimeouts.Timeout(bindingsPath))
+			appCtx, cancel := context.WithTimeout(r.Ctx(), timeout)
+			defer cancel()
+			appCtx, appSpan := tracing.Start(appCtx, "Proxy.GetBindings app",
+				tracing.AppIDKey.String(string(app.AppID)),
+			)
+			apprequest := r.WithDestination(app.AppID).WithCtx(appCtx)
 			res := result{
 				appID: app.AppID,
 			}
-			res.bindings, res.err = p.InvokeGetBindings(apprequest, cc)
+
+			// The fetch may outlive the timeout if the upstream does not
+			// respect the context, its result is then only cached.
+			fetched := make(chan result, 1)
+			fetchStart := time.Now()
+			go func() {
+				bindings, err := p.InvokeGetBindings(apprequest, cc)
+				fetched <- result{bindings: bindings, err: err}
+			}()
+			select {
+			case f := <-fetched:
+				res.bindings, res.err = f.bindings, f.err
+			case <-appCtx.Done():
+				res.err = errors.Errorf("%s: timed out fetching bindings after %s", app.AppID, timeout)
+			}
+			p.metrics.ObserveBindingsFetch(app.AppID, time.Since(fetchStart))
+			tracing.End(appSpan, res.err)
 			if res.err != nil {
 				r.Log.WithError(res.err).Debugf("failed to fetch app bindings")
 			}
+
+			res.status.Status = apps.BindingsStatusOK
+			if res.bindings == nil && res.err != nil {
+				res.status.Status = apps.BindingsStatusFailed
+				res.status.Error = res.err.Error()
+				if stale, ok := p.bindingsCache.getStale(newBindingsCacheKey(&app, r.ActingUserID(), cc)); ok {
+					res.bindings = stale.bindings
+					res.status.Status = apps.BindingsStatusStale
+					res.status.FetchedAt = stale.fetched
+				}
+			}
 			all <- res
 		}(allApps[i])
 	}
 
-	ret = []apps.Binding{}
+	bindings := []apps.Binding{}
+	status := map[apps.AppID]apps.AppBindingsStatus{}
 	var problems error
 	for i := 0; i < len(allApps); i++ {
 		res := <-all
-		ret = mergeBindings(ret, res.bindings)
+		bindings = mergeBindings(bindings, res.bindings)
+		status[res.appID] = res.status
 		if res.err != nil {
 			problems = multierror.Append(problems, res.err)
 		}
 	}
 
-	return SortTopBindings(ret), problems
+	return &apps.BindingsResponse{
+		Bindings: SortTopBindings(bindings),
+		Status:   status,
+	}, problems
 }
 
 func (p *Proxy) dispatchRefreshBindingsEvent(userID string) {
@@ -0,0 +1,229 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+)
+
+const DefaultBindingsCacheSize = 10000
+
+// ClusterEventPurgeBindings is the ID of the plugin cluster event that purges
+// the cached bindings on the other servers.
+const ClusterEventPurgeBindings = "purge_bindings"
+
+type purgeBindingsEvent struct {
+	AppID  apps.AppID `json:"app_id"`
+	UserID string     `json:"user_id,omitempty"`
+}
+
+// bindingsCache is an in-memory LRU cache of the apps' bindings, for each user
+// and context. The bindings are served from the cache only for the apps that
+// declare bindings_cache_seconds in their manifests, and for no longer than
+// that. Expired entries are kept as the last good bindings, used when the app
+// fails to provide its bindings in time. The cache is local to the server, the
+// invalidations are broadcast to the other servers in the cluster as plugin
+// cluster events, see purgeCachedBindings.
+type bindingsCache struct {
+	now func() time.Time
+
+	mutex   sync.Mutex
+	maxSize int
+	lru     *list.List // of *bindingsCacheEntry, most recently used first
+	entries map[bindingsCacheKey]*list.Element
+}
+
+type bindingsCacheKey struct {
+	appID   apps.AppID
+	version apps.AppVersion
+	userID  string
+	uac     apps.UserAgentContext
+}
+
+type bindingsCacheEntry struct {
+	key      bindingsCacheKey
+	bindings []apps.Binding
+	problems error
+	fetched  time.Time
+	expires  time.Time
+}
+
+func newBindingsCache() *bindingsCache {
+	return &bindingsCache{
+		now:     time.Now,
+		maxSize: DefaultBindingsCacheSize,
+		lru:     list.New(),
+		entries: map[bindingsCacheKey]*list.Element{},
+	}
+}
+
+func newBindingsCacheKey(app *apps.App, userID string, cc apps.Context) bindingsCacheKey {
+	return bindingsCacheKey{
+		appID:   app.AppID,
+		version: app.Version,
+		userID:  userID,
+		uac:     cc.UserAgentContext,
+	}
+}
+
+// configure applies the cache settings from conf, evicting the entries that
+// no longer fit.
+func (c *bindingsCache) configure(conf config.Config) {
+	maxSize := DefaultBindingsCacheSize
+	switch {
+	case conf.BindingsCacheSize < 0:
+		maxSize = 0
+	case conf.BindingsCacheSize > 0:
+		maxSize = conf.BindingsCacheSize
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maxSize = maxSize
+	c.evict()
+}
+
+// get returns a copy of the cached entry, if it has not expired.
+func (c *bindingsCache) get(key bindingsCacheKey) (bindingsCacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem := c.entries[key]
+	if elem == nil {
+		return bindingsCacheEntry{}, false
+	}
+	entry := *elem.Value.(*bindingsCacheEntry)
+	if !c.now().Before(entry.expires) {
+		return bindingsCacheEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	entry.bindings = copyTopBindings(entry.bindings)
+	return entry, true
+}
+
+// getStale returns a copy of the cached entry, even if it has expired.
+func (c *bindingsCache) getStale(key bindingsCacheKey) (bindingsCacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem := c.entries[key]
+	if elem == nil {
+		return bindingsCacheEntry{}, false
+	}
+	entry := *elem.Value.(*bindingsCacheEntry)
+	entry.bindings = copyTopBindings(entry.bindings)
+	return entry, true
+}
+
+func (c *bindingsCache) put(key bindingsCacheKey, bindings []apps.Binding, problems error, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.maxSize <= 0 {
+		return
+	}
+	if elem := c.entries[key]; elem != nil {
+		c.remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&bindingsCacheEntry{
+		key:      key,
+		bindings: copyTopBindings(bindings),
+		problems: problems,
+		fetched:  c.now(),
+		expires:  c.now().Add(ttl),
+	})
+	c.evict()
+}
+
+// purge removes the cached bindings of the app for the user, or for all users
+// if userID is empty.
+func (c *bindingsCache) purge(appID apps.AppID, userID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		key := elem.Value.(*bindingsCacheEntry).key
+		if key.appID == appID && (userID == "" || key.userID == userID) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+func (c *bindingsCache) evict() {
+	for c.lru.Len() > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *bindingsCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*bindingsCacheEntry)
+	delete(c.entries, entry.key)
+}
+
+// copyTopBindings copies the top-level bindings and their sub-binding slices,
+// which GetBindings merges and sorts in place.
+func copyTopBindings(in []apps.Binding) []apps.Binding {
+	out := make([]apps.Binding, 0, len(in))
+	for _, b := range in {
+		b.Bindings = append([]apps.Binding(nil), b.Bindings...)
+		out = append(out, b)
+	}
+	return out
+}
+
+// InvalidateCachedBindings removes the cached bindings of the app for the
+// user, or for all users if userID is empty, on all servers.
+func (p *Proxy) InvalidateCachedBindings(appID apps.AppID, userID string) {
+	p.purgeCachedBindings(appID, userID)
+}
+
+// purgeCachedBindings purges the cached bindings on this server, and
+// broadcasts the purge to the other servers in the cluster. The clients are
+// told to refresh their bindings by a WebSocket event sent to all servers, so
+// they must not get the stale bindings from any of them.
+func (p *Proxy) purgeCachedBindings(appID apps.AppID, userID string) {
+	p.bindingsCache.purge(appID, userID)
+
+	err := p.publishClusterEvent(ClusterEventPurgeBindings, purgeBindingsEvent{AppID: appID, UserID: userID})
+	if err != nil {
+		p.conf.NewBaseLogger().WithError(err).Warnw("failed to broadcast the purge of cached bindings", "app_id", appID)
+	}
+}
+
+// OnPluginClusterEvent handles the events broadcast by the other servers in
+// the cluster.
+func (p *Proxy) OnPluginClusterEvent(ev model.PluginClusterEvent) {
+	switch ev.Id {
+	case ClusterEventPurgeBindings:
+		purge := purgeBindingsEvent{}
+		if err := json.Unmarshal(ev.Data, &purge); err != nil {
+			p.conf.NewBaseLogger().WithError(err).Warnf("invalid %s cluster event", ev.Id)
+			return
+		}
+		p.bindingsCache.purge(purge.AppID, purge.UserID)
+
+	case ClusterEventWebSocketRequest:
+		req := webSocketRequestEvent{}
+		if err := json.Unmarshal(ev.Data, &req); err != nil {
+			p.conf.NewBaseLogger().WithError(err).Warnf("invalid %s cluster event", ev.Id)
+			return
+		}
+		// Do not block the cluster event handler while the app responds.
+		go p.handleWebSocketRequest(req)
+
+	case ClusterEventWebSocketResponse:
+		resp := webSocketResponseEvent{}
+		if err := json.Unmarshal(ev.Data, &resp); err != nil {
+			p.conf.NewBaseLogger().WithError(err).Warnf("invalid %s cluster event", ev.Id)
+			return
+		}
+		p.handleWebSocketResponse(resp)
+	}
+}
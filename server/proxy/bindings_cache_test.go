@@ -0,0 +1,170 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+)
+
+func TestBindingsCache(t *testing.T) {
+	now := time.Now()
+	c := newBindingsCache()
+	c.now = func() time.Time { return now }
+	c.configure(config.Config{StoredConfig: config.StoredConfig{BindingsCacheSize: 3}})
+
+	app1 := &apps.App{Manifest: apps.Manifest{AppID: "app1", Version: "v1"}}
+	app2 := &apps.App{Manifest: apps.Manifest{AppID: "app2", Version: "v1"}}
+	channel1 := apps.Context{UserAgentContext: apps.UserAgentContext{ChannelID: "channel1"}}
+	channel2 := apps.Context{UserAgentContext: apps.UserAgentContext{ChannelID: "channel2"}}
+	bindings := []apps.Binding{{Location: apps.LocationCommand, Bindings: []apps.Binding{{Location: "a"}, {Location: "b"}}}}
+
+	c.put(newBindingsCacheKey(app1, "user1", channel1), bindings, nil, 10*time.Second)
+	c.put(newBindingsCacheKey(app1, "user1", channel2), bindings, nil, 20*time.Second)
+	c.put(newBindingsCacheKey(app1, "user2", channel1), bindings, nil, 10*time.Second)
+
+	cached, ok := c.get(newBindingsCacheKey(app1, "user1", channel1))
+	require.True(t, ok)
+	require.Equal(t, bindings, cached.bindings)
+
+	// The returned bindings are a copy.
+	cached.bindings[0].Bindings[0], cached.bindings[0].Bindings[1] = cached.bindings[0].Bindings[1], cached.bindings[0].Bindings[0]
+	cached, _ = c.get(newBindingsCacheKey(app1, "user1", channel1))
+	require.Equal(t, bindings, cached.bindings)
+
+	// Different users, contexts and app versions don't share the entries.
+	_, ok = c.get(newBindingsCacheKey(app1, "user3", channel1))
+	require.False(t, ok)
+	app1v2 := &apps.App{Manifest: apps.Manifest{AppID: "app1", Version: "v2"}}
+	_, ok = c.get(newBindingsCacheKey(app1v2, "user1", channel1))
+	require.False(t, ok)
+
+	// The least recently used entry is evicted.
+	c.put(newBindingsCacheKey(app2, "user1", channel1), bindings, nil, 10*time.Second)
+	_, ok = c.get(newBindingsCacheKey(app1, "user1", channel2))
+	require.False(t, ok)
+	require.Equal(t, 3, c.lru.Len())
+
+	// Expired entries are kept as stale.
+	fetched := now
+	now = now.Add(10 * time.Second)
+	_, ok = c.get(newBindingsCacheKey(app1, "user1", channel1))
+	require.False(t, ok)
+	stale, ok := c.getStale(newBindingsCacheKey(app1, "user1", channel1))
+	require.True(t, ok)
+	require.Equal(t, bindings, stale.bindings)
+	require.Equal(t, fetched, stale.fetched)
+	require.Equal(t, 3, c.lru.Len())
+
+	c.put(newBindingsCacheKey(app1, "user1", channel1), bindings, nil, 10*time.Second)
+	c.purge("app1", "user2")
+	_, ok = c.get(newBindingsCacheKey(app1, "user2", channel1))
+	require.False(t, ok)
+	_, ok = c.get(newBindingsCacheKey(app1, "user1", channel1))
+	require.True(t, ok)
+
+	c.purge("app1", "")
+	require.Equal(t, 1, c.lru.Len())
+	_, ok = c.getStale(newBindingsCacheKey(app1, "user1", channel1))
+	require.False(t, ok)
+
+	c.configure(config.Config{StoredConfig: config.StoredConfig{BindingsCacheSize: -1}})
+	require.Equal(t, 0, c.lru.Len())
+	c.put(newBindingsCacheKey(app1, "user1", channel1), bindings, nil, 10*time.Second)
+	require.Equal(t, 0, c.lru.Len())
+}
+
+func TestInvokeGetBindingsCached(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cacheSeconds    int
+		expectedFetched int
+	}{
+		"not cached": {expectedFetched: 3},
+		"cached":     {cacheSeconds: 60, expectedFetched: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			app := apps.App{
+				DeployType:       apps.DeployBuiltin,
+				GrantedLocations: apps.Locations{apps.LocationCommand},
+				Manifest: apps.Manifest{
+					AppID:                "app1",
+					BindingsCacheSeconds: tc.cacheSeconds,
+				},
+			}
+
+			ctrl := gomock.NewController(t)
+			testAPI := &plugintest.API{}
+			conf := config.NewTestConfigService(nil).WithMattermostConfig(model.Config{
+				ServiceSettings: model.ServiceSettings{
+					SiteURL: model.NewString("test.mattermost.com"),
+				},
+			}).WithMattermostAPI(pluginapi.NewClient(testAPI, &plugintest.Driver{}))
+			s, err := store.MakeService(conf, nil)
+			require.NoError(t, err)
+			appStore := mock_store.NewMockAppStore(ctrl)
+			appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+			s.App = appStore
+
+			up := &testCallUpstream{responses: map[string]apps.CallResponse{
+				"/bindings": {
+					Type: apps.CallResponseTypeOK,
+					Data: []apps.Binding{{
+						Location: apps.LocationCommand,
+						Bindings: []apps.Binding{{Location: "send", Submit: apps.NewCall("/send")}},
+					}},
+				},
+			}}
+			p := &Proxy{
+				store:            s,
+				builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+				conf:             conf,
+				bindingsCache:    newBindingsCache(),
+			}
+
+			get := func(userID string) {
+				r := incoming.NewRequest(conf, nil).WithDestination(app.AppID).WithActingUserID(userID)
+				bindings, err := p.InvokeGetBindings(r, apps.Context{})
+				require.NoError(t, err)
+				require.Len(t, bindings, 1)
+				require.Equal(t, "send", bindings[0].Bindings[0].Label)
+			}
+			get("user1")
+			get("user1")
+			get("user2")
+			require.Len(t, up.received, tc.expectedFetched)
+
+			// The purge is broadcast to the other servers.
+			purgeEvent := model.PluginClusterEvent{
+				Id:   ClusterEventPurgeBindings,
+				Data: []byte(`{"app_id":"app1","user_id":"user1"}`),
+			}
+			testAPI.On("PublishPluginClusterEvent", purgeEvent,
+				model.PluginClusterEventSendOptions{SendType: model.PluginClusterEventSendTypeReliable}).Return(nil).Once()
+			p.InvalidateCachedBindings(app.AppID, "user1")
+			testAPI.AssertExpectations(t)
+			get("user1")
+			require.Len(t, up.received, tc.expectedFetched+1)
+
+			// A purge broadcast by another server.
+			p.OnPluginClusterEvent(purgeEvent)
+			get("user1")
+			get("user2")
+			require.Len(t, up.received, 2*tc.expectedFetched)
+		})
+	}
+}
@@ -2,10 +2,12 @@ package proxy
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
@@ -535,6 +537,10 @@ func TestRefreshBindingsEventAfterCall(t *testing.T) {
 				RefreshBindings: true,
 			},
 			checkExpectation: func(testApi *plugintest.API) {
+				testApi.On("PublishPluginClusterEvent", model.PluginClusterEvent{
+					Id:   ClusterEventPurgeBindings,
+					Data: []byte(`{"app_id":"app1","user_id":"userid"}`),
+				}, model.PluginClusterEventSendOptions{SendType: model.PluginClusterEventSendTypeReliable}).Return(nil).Once()
 				testApi.On("PublishWebSocketEvent", config.WebSocketEventRefreshBindings, map[string]interface{}{}, &model.WebsocketBroadcast{UserId: "userid"}).Once()
 			},
 		},
@@ -607,6 +613,7 @@ func TestRefreshBindingsEventAfterCall(t *testing.T) {
 				store:            s,
 				builtinUpstreams: upstreams,
 				conf:             conf,
+				bindingsCache:    newBindingsCache(),
 			}
 
 			tc.checkExpectation(testAPI)
@@ -625,3 +632,101 @@ func TestRefreshBindingsEventAfterCall(t *testing.T) {
 		})
 	}
 }
+
+// testHangingUpstream does not respond until released, regardless of the
+// context.
+type testHangingUpstream struct {
+	release chan struct{}
+}
+
+func (u *testHangingUpstream) Roundtrip(context.Context, apps.App, apps.CallRequest, bool) (io.ReadCloser, error) {
+	<-u.release
+	return nil, utils.ErrNotFound
+}
+
+func (u *testHangingUpstream) GetStatic(context.Context, apps.App, string) (io.ReadCloser, int, error) {
+	return nil, 0, utils.ErrNotFound
+}
+
+func TestGetBindingsPartial(t *testing.T) {
+	newApp := func(appID apps.AppID) apps.App {
+		return apps.App{
+			DeployType:       apps.DeployBuiltin,
+			GrantedLocations: apps.Locations{apps.LocationCommand},
+			Manifest:         apps.Manifest{AppID: appID},
+		}
+	}
+	okApp := newApp("ok")
+	staleApp := newApp("stale")
+	failedApp := newApp("failed")
+	hangingApp := newApp("hanging")
+	allApps := []apps.App{okApp, staleApp, failedApp, hangingApp}
+
+	commandBindings := func(appID apps.AppID) []apps.Binding {
+		return []apps.Binding{{
+			Location: apps.LocationCommand,
+			Bindings: []apps.Binding{{Location: apps.Location(appID), Submit: apps.NewCall("/submit")}},
+		}}
+	}
+
+	ctrl := gomock.NewController(t)
+	conf := config.NewTestConfigService(nil).WithMattermostConfig(model.Config{
+		ServiceSettings: model.ServiceSettings{
+			SiteURL: model.NewString("test.mattermost.com"),
+		},
+	})
+	s, err := store.MakeService(conf, nil)
+	require.NoError(t, err)
+	appStore := mock_store.NewMockAppStore(ctrl)
+	appStore.EXPECT().AsList(store.EnabledAppsOnly).Return(allApps)
+	for i := range allApps {
+		app := allApps[i]
+		appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+	}
+	s.App = appStore
+
+	hanging := &testHangingUpstream{release: make(chan struct{})}
+	defer close(hanging.release)
+	p := &Proxy{
+		store: s,
+		builtinUpstreams: map[apps.AppID]upstream.Upstream{
+			okApp.AppID: &testCallUpstream{responses: map[string]apps.CallResponse{
+				"/bindings": {Type: apps.CallResponseTypeOK, Data: commandBindings(okApp.AppID)},
+			}},
+			staleApp.AppID:   &testCallUpstream{},
+			failedApp.AppID:  &testCallUpstream{},
+			hangingApp.AppID: hanging,
+		},
+		conf:          conf,
+		bindingsCache: newBindingsCache(),
+	}
+
+	cc := apps.Context{UserAgentContext: apps.UserAgentContext{ChannelID: "channelid"}}
+	fetched := time.Now().Add(-time.Hour)
+	p.bindingsCache.now = func() time.Time { return fetched }
+	p.bindingsCache.put(newBindingsCacheKey(&staleApp, "userid", cc), commandBindings(staleApp.AppID), nil, 0)
+	p.bindingsCache.now = time.Now
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	r := incoming.NewRequest(conf, nil).WithActingUserID("userid").WithCtx(ctx)
+	resp, err := p.GetBindings(r, cc)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hanging: timed out fetching bindings")
+
+	require.Len(t, resp.Bindings, 1)
+	locations := []apps.Location{}
+	for _, b := range resp.Bindings[0].Bindings {
+		locations = append(locations, b.Location)
+	}
+	require.ElementsMatch(t, []apps.Location{"ok", "stale"}, locations)
+
+	require.Len(t, resp.Status, 4)
+	require.Equal(t, apps.AppBindingsStatus{Status: apps.BindingsStatusOK}, resp.Status[okApp.AppID])
+	require.Equal(t, apps.BindingsStatusStale, resp.Status[staleApp.AppID].Status)
+	require.NotEmpty(t, resp.Status[staleApp.AppID].Error)
+	require.Equal(t, fetched, resp.Status[staleApp.AppID].FetchedAt)
+	require.Equal(t, apps.BindingsStatusFailed, resp.Status[failedApp.AppID].Status)
+	require.Equal(t, apps.BindingsStatusFailed, resp.Status[hangingApp.AppID].Status)
+	require.Contains(t, resp.Status[hangingApp.AppID].Error, "timed out")
+}
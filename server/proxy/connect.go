@@ -0,0 +1,105 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upws"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// MaxConnectTokenLifetime limits how far in the future the expiration time of
+// the token an app connects with may be, so that a leaked token can not be used
+// to connect for long.
+const MaxConnectTokenLifetime = 10 * time.Minute
+
+// ConnectApp authenticates an incoming WebSocket connection from an app
+// deployed as type "websocket", and serves it until it is closed. upgrade is
+// called to establish the connection once the app is authenticated.
+func (p *Proxy) ConnectApp(r *incoming.Request, token string, upgrade func() (*websocket.Conn, error)) error {
+	app, err := p.getEnabledDestination(r)
+	if err != nil {
+		return err
+	}
+	if app.DeployType != apps.DeployWebSocket {
+		return utils.NewInvalidError("%s is not deployed as type %s", app.AppID, apps.DeployWebSocket)
+	}
+	if err = verifyAppConnectToken(token, app.Secret, app.AppID); err != nil {
+		return err
+	}
+
+	wsUp, err := p.webSocketUpstream(app)
+	if err != nil {
+		return err
+	}
+
+	ws, err := upgrade()
+	if err != nil {
+		return err
+	}
+	wsUp.Serve(app.AppID, ws)
+	return nil
+}
+
+// getWebSocketStatus returns the status of a WebSocket app's connection, nil
+// if the app has not connected.
+func (p *Proxy) getWebSocketStatus(app *apps.App) *upws.Status {
+	wsUp, err := p.webSocketUpstream(app)
+	if err != nil {
+		return nil
+	}
+	return wsUp.Status(app.AppID)
+}
+
+func (p *Proxy) webSocketUpstream(app *apps.App) (*upws.Upstream, error) {
+	up, err := p.rawUpstreamForApp(app)
+	if err != nil {
+		return nil, err
+	}
+	wsUp, ok := up.(*upws.Upstream)
+	if !ok {
+		return nil, errors.Errorf("invalid upstream for %s", apps.DeployWebSocket)
+	}
+	return wsUp, nil
+}
+
+// verifyAppConnectToken validates the JWT an app uses to connect to
+// Mattermost. It must be signed with the app's secret, issued by the app, and
+// have an expiration time no later than MaxConnectTokenLifetime from now.
+func verifyAppConnectToken(token, secret string, appID apps.AppID) error {
+	if secret == "" {
+		return utils.NewUnauthorizedError("%s has no secret to authenticate connections with", appID)
+	}
+	if token == "" {
+		return utils.NewUnauthorizedError("no token provided")
+	}
+
+	claims := jwt.StandardClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return utils.NewUnauthorizedError(err)
+	}
+	if claims.Issuer != string(appID) {
+		return utils.NewUnauthorizedError("token is not issued by %s", appID)
+	}
+	if claims.ExpiresAt == 0 {
+		return utils.NewUnauthorizedError("token has no expiration time")
+	}
+	if time.Unix(claims.ExpiresAt, 0).After(time.Now().Add(MaxConnectTokenLifetime)) {
+		return utils.NewUnauthorizedError("token expires too far in the future, the limit is %s", MaxConnectTokenLifetime)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+func TestVerifyAppConnectToken(t *testing.T) {
+	sign := func(method jwt.SigningMethod, secret string, claims jwt.StandardClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return token
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+
+	for name, tc := range map[string]struct {
+		token         string
+		secret        string
+		expectedError string
+	}{
+		"valid": {
+			token:  sign(jwt.SigningMethodHS256, "1234", jwt.StandardClaims{Issuer: "test", ExpiresAt: exp}),
+			secret: "1234",
+		},
+		"no secret": {
+			token:         sign(jwt.SigningMethodHS256, "", jwt.StandardClaims{Issuer: "test", ExpiresAt: exp}),
+			expectedError: "test has no secret to authenticate connections with: unauthorized",
+		},
+		"no token": {
+			secret:        "1234",
+			expectedError: "no token provided: unauthorized",
+		},
+		"wrong secret": {
+			token:         sign(jwt.SigningMethodHS256, "5678", jwt.StandardClaims{Issuer: "test", ExpiresAt: exp}),
+			secret:        "1234",
+			expectedError: "signature is invalid: unauthorized",
+		},
+		"wrong issuer": {
+			token:         sign(jwt.SigningMethodHS256, "1234", jwt.StandardClaims{Issuer: "other", ExpiresAt: exp}),
+			secret:        "1234",
+			expectedError: "token is not issued by test: unauthorized",
+		},
+		"no expiration": {
+			token:         sign(jwt.SigningMethodHS256, "1234", jwt.StandardClaims{Issuer: "test"}),
+			secret:        "1234",
+			expectedError: "token has no expiration time: unauthorized",
+		},
+		"expires too late": {
+			token:         sign(jwt.SigningMethodHS256, "1234", jwt.StandardClaims{Issuer: "test", ExpiresAt: time.Now().Add(time.Hour).Unix()}),
+			secret:        "1234",
+			expectedError: "token expires too far in the future, the limit is 10m0s: unauthorized",
+		},
+		"expired": {
+			token:         sign(jwt.SigningMethodHS256, "1234", jwt.StandardClaims{Issuer: "test", ExpiresAt: time.Now().Add(-time.Minute).Unix()}),
+			secret:        "1234",
+			expectedError: "token is expired",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := verifyAppConnectToken(tc.token, tc.secret, apps.AppID("test"))
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
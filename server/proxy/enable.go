@@ -61,6 +61,8 @@ func (p *Proxy) EnableApp(r *incoming.Request, cc apps.Context, appID apps.AppID
 		}
 	}
 
+	p.store.Audit.Record(r, apps.AuditAppEnabled, app.AppID, nil)
+
 	p.dispatchRefreshBindingsEvent(r.ActingUserID())
 
 	if message == "" {
@@ -115,7 +117,10 @@ func (p *Proxy) DisableApp(r *incoming.Request, cc apps.Context, appID apps.AppI
 		return "", errors.Wrapf(err, "failed to get app. appID: %s", appID)
 	}
 
+	p.stopUpstream(app)
+
 	r.Log.Infof("Disabled app")
+	p.store.Audit.Record(r, apps.AuditAppDisabled, app.AppID, nil)
 
 	p.dispatchRefreshBindingsEvent(r.ActingUserID())
 
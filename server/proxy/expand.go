@@ -3,6 +3,7 @@ package proxy
 import (
 	"encoding/json"
 	"path"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -13,8 +14,14 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
+// ActingUserTokenAuditPeriod is how often the expansions of the acting user's
+// token are recorded in the audit log, for each app and user. The tokens are
+// expanded for most calls.
+const ActingUserTokenAuditPeriod = 24 * time.Hour
+
 type expandFunc func(apps.ExpandLevel) error
 
 type expander struct {
@@ -43,6 +50,12 @@ func (p *Proxy) expandContext(
 	expand *apps.Expand,
 	specialGetter ExpandGetter,
 ) (_ *apps.Context, err error) {
+	ctx, span := tracing.Start(r.Ctx(), "Proxy.expandContext",
+		tracing.AppIDKey.String(string(app.AppID)),
+	)
+	r = r.WithCtx(ctx)
+	defer func() { tracing.End(span, err) }()
+
 	conf := r.Config().Get()
 	defer func() {
 		if err != nil && conf.DeveloperMode {
@@ -234,6 +247,7 @@ func (e *expander) expandActingUserAccessToken(level apps.ExpandLevel) error {
 		return err
 	}
 	e.ExpandedContext.ActingUserAccessToken = token
+	e.proxy.store.Audit.RecordOnce(e.r, apps.AuditActingUserTokenExpanded, to.AppID, ActingUserTokenAuditPeriod)
 	return nil
 }
 
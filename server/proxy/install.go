@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -120,6 +121,18 @@ func (p *Proxy) InstallApp(r *incoming.Request, cc apps.Context, appID apps.AppI
 	}
 	r.Log.Debugf("app install flow: stored updated app configuration")
 
+	p.store.Audit.Record(r, apps.AuditAppInstalled, app.AppID, map[string]string{
+		"deploy_type": string(app.DeployType),
+		"version":     string(app.Version),
+		"trusted":     strconv.FormatBool(trusted),
+	})
+	if len(app.GrantedPermissions) > 0 || len(app.GrantedLocations) > 0 {
+		p.store.Audit.Record(r, apps.AuditPermissionsGranted, app.AppID, map[string]string{
+			"permissions": app.GrantedPermissions.String(),
+			"locations":   app.GrantedLocations.String(),
+		})
+	}
+
 	message = fmt.Sprintf("Installed app `%s`: %s.", app.AppID, app.DisplayName)
 	if app.OnInstall != nil {
 		cresp := p.call(r, app, *app.OnInstall, &cc)
@@ -142,6 +155,7 @@ func (p *Proxy) InstallApp(r *incoming.Request, cc apps.Context, appID apps.AppI
 
 	p.conf.Telemetry().TrackInstall(string(app.AppID), string(app.DeployType))
 
+	p.purgeCachedBindings(app.AppID, "")
 	p.dispatchRefreshBindingsEvent(r.ActingUserID())
 
 	return app, message, nil
@@ -3,6 +3,7 @@ package proxy
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
@@ -25,9 +26,16 @@ func (p *Proxy) InvokeGetBindings(r *incoming.Request, cc apps.Context) ([]apps.
 		return nil, utils.NewForbiddenError("no location granted to bind to")
 	}
 
+	ttl := time.Duration(app.BindingsCacheSeconds) * time.Second
+	cacheKey := newBindingsCacheKey(app, r.ActingUserID(), cc)
+	if ttl > 0 {
+		if cached, ok := p.bindingsCache.get(cacheKey); ok {
+			return cached.bindings, cached.problems
+		}
+	}
+
 	var problems error
 	conf := p.conf.Get()
-	// TODO PERF: Add caching
 	bindingsCall := app.Bindings.WithDefault(apps.DefaultBindings)
 
 	// no need to clean the context, Call will do it.
@@ -45,6 +53,7 @@ func (p *Proxy) InvokeGetBindings(r *incoming.Request, cc apps.Context) ([]apps.
 		if err != nil {
 			problems = multierror.Append(problems, err)
 		}
+		p.bindingsCache.put(cacheKey, bindings, problems, ttl)
 		return bindings, problems
 
 	case apps.CallResponseTypeError:
@@ -10,8 +10,10 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/metrics"
 	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 func isBindingPath(app *apps.App, pathForCheck string) bool {
@@ -36,25 +38,84 @@ func (p *Proxy) InvokeCall(r *incoming.Request, creq apps.CallRequest) (*apps.Ap
 		return nil, apps.NewErrorResponse(utils.NewInvalidError("incoming.Request validation error: app_id mismatch"))
 	}
 
-	if creq.Path[0] != '/' {
-		return app, apps.NewErrorResponse(utils.NewInvalidError("call path must start with a %q: %q", "/", creq.Path))
+	creq.Path, err = cleanUserCallPath(app, creq.Path)
+	if err != nil {
+		return app, apps.NewErrorResponse(err)
 	}
 
-	cleanPath, err := utils.CleanPath(creq.Path)
-	if err != nil {
-		return app, apps.NewErrorResponse(errors.Wrap(err, "failed to clean call path"))
+	appRequest := r.WithDestination(app.AppID)
+	cresp := p.callApp(appRequest, app, creq, false)
+
+	// Follow the "call" responses. Each chained call is made to the same app,
+	// with the same values (and lookup query) as the original request. The
+	// context is re-expanded from the original user agent context according to
+	// the chained call's expand, and its state is merged over the previous
+	// call's.
+	for chained := 0; cresp.Type == apps.CallResponseTypeCall; chained++ {
+		if chained >= apps.MaxChainedCalls {
+			return app, apps.NewErrorResponse(utils.NewInvalidError("too many chained calls, the limit is %v", apps.MaxChainedCalls))
+		}
+		if cresp.Call == nil {
+			return app, apps.NewErrorResponse(utils.NewInvalidError("call response has no call to follow"))
+		}
+		next := *cresp.Call
+		next.Path, err = cleanUserCallPath(app, next.Path)
+		if err != nil {
+			return app, apps.NewErrorResponse(errors.Wrap(err, "invalid chained call"))
+		}
+		next.State = mergeCallState(creq.State, next.State)
+		creq.Call = next
+
+		appRequest.Log.Debugf("following chained call %v to %s", chained+1, next.Path)
+		cresp = p.callApp(appRequest, app, creq, false)
+	}
+
+	return app, cresp
+}
+
+// cleanUserCallPath cleans the path of a call made by a user agent, and makes
+// sure it is not one of the paths reserved for the proxy.
+func cleanUserCallPath(app *apps.App, path string) (string, error) {
+	if path == "" || path[0] != '/' {
+		return "", utils.NewInvalidError("call path must start with a %q: %q", "/", path)
 	}
-	creq.Path = cleanPath
 
-	err = checkForForbiddenPath(app, creq.Path)
+	cleanPath, err := utils.CleanPath(path)
 	if err != nil {
-		return app, apps.NewErrorResponse(errors.Wrap(err, "forbidden call path"))
+		return "", errors.Wrap(err, "failed to clean call path")
 	}
 
-	appRequest := r.WithDestination(app.AppID)
-	cresp := p.callApp(appRequest, app, creq, false)
+	err = checkForForbiddenPath(app, cleanPath)
+	if err != nil {
+		return "", errors.Wrap(err, "forbidden call path")
+	}
+	return cleanPath, nil
+}
 
-	return app, cresp
+// mergeCallState merges the state of a chained call over the state of the
+// previous call. If both are JSON objects, the keys are merged, with the
+// chained call's values taking precedence. Otherwise the chained call's state
+// replaces the previous one, unless it is empty.
+func mergeCallState(prev, next interface{}) interface{} {
+	if next == nil {
+		return prev
+	}
+	prevMap, ok := prev.(map[string]interface{})
+	if !ok {
+		return next
+	}
+	nextMap, ok := next.(map[string]interface{})
+	if !ok {
+		return next
+	}
+	merged := map[string]interface{}{}
+	for k, v := range prevMap {
+		merged[k] = v
+	}
+	for k, v := range nextMap {
+		merged[k] = v
+	}
+	return merged
 }
 
 // checkForForbiddenPath checks if the call path matches on of the call paths defined in the manifest, expect /bindings.
@@ -152,6 +213,26 @@ func (p *Proxy) callAppWithExpandGetter(r *incoming.Request, app *apps.App, creq
 func (p *Proxy) callAppImpl(r *incoming.Request, app *apps.App, creq apps.CallRequest, notify bool, expandGetter ExpandGetter) (cresp *apps.CallResponse, err error) {
 	start := time.Now()
 	var callElapsed, expandElapsed time.Duration
+	ctx, span := tracing.Start(r.Ctx(), "Proxy.callApp",
+		tracing.AppIDKey.String(string(app.AppID)),
+		tracing.CallPathKey.String(creq.Path),
+		tracing.RequestIDKey.String(r.RequestID()),
+	)
+	r = r.WithCtx(ctx)
+	defer func() {
+		spanErr := err
+		if spanErr == nil && cresp != nil && cresp.Type == apps.CallResponseTypeError {
+			spanErr = cresp
+		}
+		tracing.End(span, spanErr)
+	}()
+	defer func() {
+		responseType := metrics.ResponseTypeFailed
+		if err == nil && cresp != nil {
+			responseType = string(cresp.Type)
+		}
+		p.metrics.ObserveCall(app.AppID, creq.Path, responseType, time.Since(start))
+	}()
 	defer func() {
 		log := r.Log.With(
 			"elapsed", time.Since(start).String(),
@@ -177,6 +258,13 @@ func (p *Proxy) callAppImpl(r *incoming.Request, app *apps.App, creq apps.CallRe
 	// here, make sure it's set in the request
 	r = r.WithDestination(app.AppID)
 
+	if declared := app.Manifest.CallTimeouts.Timeout(creq.Path); declared > 0 {
+		timeout := p.conf.Get().CallTimeout(declared)
+		ctx, cancel := upstream.WithCallTimeout(r.Ctx(), timeout)
+		defer cancel()
+		r = r.WithCtx(ctx)
+	}
+
 	up, err := p.upstreamForApp(app)
 	if err != nil {
 		return nil, errors.Wrapf(err, "no available upstream for %s", app.AppID)
@@ -195,6 +283,7 @@ func (p *Proxy) callAppImpl(r *incoming.Request, app *apps.App, creq apps.CallRe
 		err = upstream.Notify(r.Ctx(), up, *app, creq)
 		callElapsed = time.Since(callStart)
 		if err != nil {
+			p.metrics.IncUpstreamErrors(app.DeployType)
 			return nil, errors.Wrap(err, "upstream call failed")
 		}
 		return &apps.CallResponse{
@@ -206,6 +295,7 @@ func (p *Proxy) callAppImpl(r *incoming.Request, app *apps.App, creq apps.CallRe
 	response, err := upstream.Call(r.Ctx(), up, *app, creq)
 	callElapsed = time.Since(callStart)
 	if err != nil {
+		p.metrics.IncUpstreamErrors(app.DeployType)
 		return nil, errors.Wrap(err, "upstream call failed")
 	}
 	cresp = &response
@@ -223,6 +313,7 @@ func (p *Proxy) callAppImpl(r *incoming.Request, app *apps.App, creq apps.CallRe
 	if cresp.Type != apps.CallResponseTypeError &&
 		!isBindingPath(app, creq.Call.Path) &&
 		cresp.RefreshBindings && r.ActingUserID() != "" {
+		p.purgeCachedBindings(app.AppID, r.ActingUserID())
 		p.dispatchRefreshBindingsEvent(r.ActingUserID())
 	}
 
@@ -0,0 +1,237 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// testCallUpstream responds to each call path with a preset response, and
+// records the requests it receives, and their call timeouts.
+type testCallUpstream struct {
+	responses map[string]apps.CallResponse
+	received  []apps.CallRequest
+	timeouts  []time.Duration
+}
+
+func (u *testCallUpstream) Roundtrip(ctx context.Context, _ apps.App, creq apps.CallRequest, _ bool) (io.ReadCloser, error) {
+	u.received = append(u.received, creq)
+	u.timeouts = append(u.timeouts, upstream.CallTimeout(ctx, 0))
+	cresp, ok := u.responses[creq.Path]
+	if !ok {
+		cresp = apps.NewErrorResponse(utils.NewNotFoundError(creq.Path))
+	}
+	data, err := json.Marshal(cresp)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (u *testCallUpstream) GetStatic(context.Context, apps.App, string) (io.ReadCloser, int, error) {
+	return nil, 0, utils.ErrNotFound
+}
+
+func TestInvokeCallChained(t *testing.T) {
+	app := apps.App{
+		DeployType: apps.DeployBuiltin,
+		Manifest: apps.Manifest{
+			AppID:       "app1",
+			DisplayName: "App 1",
+			OnInstall:   apps.NewCall("/installed"),
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		responses     map[string]apps.CallResponse
+		expectedPaths []string
+		expected      apps.CallResponse
+		expectedError string
+	}{
+		"no chaining": {
+			responses: map[string]apps.CallResponse{
+				"/submit": apps.NewTextResponse("done"),
+			},
+			expectedPaths: []string{"/submit"},
+			expected:      apps.NewTextResponse("done"),
+		},
+		"chained": {
+			responses: map[string]apps.CallResponse{
+				"/submit": {
+					Type: apps.CallResponseTypeCall,
+					Call: &apps.Call{
+						Path:   "/step2",
+						Expand: &apps.Expand{ActingUser: apps.ExpandID},
+						State:  map[string]interface{}{"step": 2.0, "added": "yes"},
+					},
+				},
+				"/step2": {
+					Type: apps.CallResponseTypeCall,
+					Call: apps.NewCall("/step3/../final"),
+				},
+				"/final": apps.NewTextResponse("done"),
+			},
+			expectedPaths: []string{"/submit", "/step2", "/final"},
+			expected:      apps.NewTextResponse("done"),
+		},
+		"no call in response": {
+			responses: map[string]apps.CallResponse{
+				"/submit": {Type: apps.CallResponseTypeCall},
+			},
+			expectedPaths: []string{"/submit"},
+			expectedError: "call response has no call to follow: invalid input",
+		},
+		"forbidden path": {
+			responses: map[string]apps.CallResponse{
+				"/submit": {
+					Type: apps.CallResponseTypeCall,
+					Call: apps.NewCall("/installed"),
+				},
+			},
+			expectedPaths: []string{"/submit"},
+			expectedError: "invalid chained call: forbidden call path: path /installed defined as on_install.path",
+		},
+		"relative path": {
+			responses: map[string]apps.CallResponse{
+				"/submit": {
+					Type: apps.CallResponseTypeCall,
+					Call: apps.NewCall("final"),
+				},
+			},
+			expectedPaths: []string{"/submit"},
+			expectedError: `invalid chained call: call path must start with a "/": "final": invalid input`,
+		},
+		"loop": {
+			responses: map[string]apps.CallResponse{
+				"/submit": {
+					Type: apps.CallResponseTypeCall,
+					Call: apps.NewCall("/submit"),
+				},
+			},
+			expectedPaths: []string{"/submit", "/submit", "/submit", "/submit", "/submit", "/submit"},
+			expectedError: "too many chained calls, the limit is 5: invalid input",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			testAPI := &plugintest.API{}
+			testAPI.On("GetUser", "userid").Return(&model.User{Id: "userid", Username: "user"}, nil)
+			conf := config.NewTestConfigService(nil).WithMattermostConfig(model.Config{
+				ServiceSettings: model.ServiceSettings{
+					SiteURL: model.NewString("test.mattermost.com"),
+				},
+			}).WithMattermostAPI(pluginapi.NewClient(testAPI, &plugintest.Driver{}))
+
+			s, err := store.MakeService(conf, nil)
+			require.NoError(t, err)
+			appStore := mock_store.NewMockAppStore(ctrl)
+			appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+			s.App = appStore
+
+			up := &testCallUpstream{responses: tc.responses}
+			p := &Proxy{
+				store:            s,
+				builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+				conf:             conf,
+			}
+
+			r := incoming.NewRequest(conf, nil).WithDestination(app.AppID).WithActingUserID("userid")
+			_, cresp := p.InvokeCall(r, apps.CallRequest{
+				Call: apps.Call{
+					Path:  "/submit",
+					State: map[string]interface{}{"step": 1.0, "kept": "yes"},
+				},
+				Values: map[string]interface{}{"field": "value"},
+				Context: apps.Context{
+					UserAgentContext: apps.UserAgentContext{AppID: app.AppID},
+				},
+			})
+
+			paths := []string{}
+			for _, creq := range up.received {
+				paths = append(paths, creq.Path)
+				require.Equal(t, map[string]interface{}{"field": "value"}, creq.Values)
+			}
+			require.Equal(t, tc.expectedPaths, paths)
+
+			if tc.expectedError != "" {
+				require.Equal(t, apps.CallResponseTypeError, cresp.Type)
+				require.Equal(t, tc.expectedError, cresp.Text)
+				return
+			}
+			require.Equal(t, tc.expected, cresp)
+
+			if name == "chained" {
+				step2 := up.received[1]
+				require.Equal(t, &apps.Expand{ActingUser: apps.ExpandID}, step2.Expand)
+				require.Equal(t, map[string]interface{}{"step": 2.0, "kept": "yes", "added": "yes"}, step2.State)
+				require.NotNil(t, step2.Context.ActingUser)
+				require.Equal(t, "userid", step2.Context.ActingUser.Id)
+
+				final := up.received[2]
+				require.Nil(t, final.Expand)
+				require.Equal(t, map[string]interface{}{"step": 2.0, "kept": "yes", "added": "yes"}, final.State)
+				require.Nil(t, final.Context.ActingUser)
+			}
+		})
+	}
+}
+
+func TestMergeCallState(t *testing.T) {
+	require.Equal(t, "prev", mergeCallState("prev", nil))
+	require.Equal(t, "next", mergeCallState("prev", "next"))
+	require.Equal(t, "next", mergeCallState(map[string]interface{}{"a": 1}, "next"))
+	require.Equal(t, map[string]interface{}{"b": 2}, mergeCallState("prev", map[string]interface{}{"b": 2}))
+	require.Equal(t, map[string]interface{}{"a": 1, "b": 3},
+		mergeCallState(map[string]interface{}{"a": 1, "b": 2}, map[string]interface{}{"b": 3}))
+}
+
+func TestCallAppTimeout(t *testing.T) {
+	app := apps.App{
+		DeployType: apps.DeployBuiltin,
+		Manifest: apps.Manifest{
+			AppID: "app1",
+			CallTimeouts: &apps.CallTimeouts{
+				Paths: map[string]int{"/bindings": 2, "/report/submit": 25, "/slow": 120},
+			},
+		},
+	}
+	up := &testCallUpstream{responses: map[string]apps.CallResponse{}}
+	paths := []string{"/bindings", "/report/submit", "/slow", "/other"}
+	for _, path := range paths {
+		up.responses[path] = apps.NewTextResponse("ok")
+	}
+	conf := config.NewTestConfigService(nil)
+	p := &Proxy{
+		builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+		conf:             conf,
+	}
+
+	for _, path := range paths {
+		r := incoming.NewRequest(conf, nil)
+		cresp := p.callApp(r, &app, apps.CallRequest{Call: *apps.NewCall(path)}, false)
+		require.Equal(t, apps.NewTextResponse("ok"), cresp)
+	}
+	require.Equal(t, []time.Duration{2 * time.Second, 25 * time.Second, config.RequestTimeout, 0}, up.timeouts)
+}
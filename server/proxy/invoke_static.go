@@ -5,7 +5,6 @@ package proxy
 
 import (
 	"context"
-	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -35,8 +34,16 @@ func normalizeStaticPath(conf config.Config, appID apps.AppID, icon string) (str
 	return icon, nil
 }
 
-func (p *Proxy) InvokeGetStatic(r *incoming.Request, path string) (io.ReadCloser, int, error) {
-	app, err := p.GetApp(r)
+func (p *Proxy) InvokeGetStatic(r *incoming.Request, path string) (*upstream.StaticAsset, int, error) {
+	if err := r.Check(
+		r.RequireActingUser,
+	); err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+
+	// Not GetApp, the upstream needs the app's unsanitized record, e.g. its
+	// TLS configuration.
+	app, err := p.GetInstalledApp(r.Destination(), false)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if errors.Is(err, utils.ErrNotFound) {
@@ -49,28 +56,72 @@ func (p *Proxy) InvokeGetStatic(r *incoming.Request, path string) (io.ReadCloser
 	return p.getStatic(r, app, path)
 }
 
-func (p *Proxy) getStatic(r *incoming.Request, app *apps.App, path string) (io.ReadCloser, int, error) {
+// getStatic returns the asset from the cache, or fetches it from the app.
+// Stale cached assets are revalidated with the app using a conditional
+// request, if the upstream supports it. The path is cleaned, so the upstreams
+// receive a path relative to the app's static folder, e.g. "img/logo.png".
+func (p *Proxy) getStatic(r *incoming.Request, app *apps.App, path string) (*upstream.StaticAsset, int, error) {
+	path, err := utils.CleanStaticPath(path)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	key := staticCacheKey(app, path)
+	cached, stale := p.staticCache.get(key)
+	if cached != nil && !stale {
+		return cached.asset(), http.StatusOK, nil
+	}
+
 	up, err := p.upstreamForApp(app)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
-	return up.GetStatic(r.Ctx(), *app, path)
+	ctx := r.Ctx()
+	if cached != nil {
+		ctx = upstream.WithRevalidation(ctx, cached.upstreamETag, cached.lastModified)
+	}
+	body, status, err := up.GetStatic(ctx, *app, path)
+	if err != nil {
+		p.metrics.IncUpstreamErrors(app.DeployType)
+	}
+	if cached != nil && err == nil && status == http.StatusNotModified {
+		if body != nil {
+			_ = body.Close()
+		}
+		p.staticCache.revalidated(cached)
+		return cached.asset(), http.StatusOK, nil
+	}
+	if body == nil {
+		return nil, status, err
+	}
+	asset, ok := body.(*upstream.StaticAsset)
+	if !ok {
+		asset = &upstream.StaticAsset{ReadCloser: body}
+	}
+	if err != nil || status != http.StatusOK {
+		return asset, status, err
+	}
+
+	asset, err = p.cacheStaticAsset(key, app, path, asset)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	return asset, http.StatusOK, nil
 }
 
 // pingApp checks if the app is accessible. Call its ping path with nothing
 // expanded, ignore 404 errors coming back and consider everything else a
 // "success".
-func (p *Proxy) pingApp(ctx context.Context, app *apps.App) error {
-	var timeout time.Duration
+func pingTimeout(app *apps.App) time.Duration {
 	if app.DeployType == apps.DeployAWSLambda {
 		// Lambda functions might need to cold start and take longer to reply.
 		// Use a longer timeout.
-		timeout = pingAppTimeoutLambda
-	} else {
-		timeout = pingAppTimeout
+		return pingAppTimeoutLambda
 	}
+	return pingAppTimeout
+}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+func (p *Proxy) pingApp(ctx context.Context, app *apps.App) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout(app))
 	defer cancel()
 
 	up, err := p.upstreamForApp(app)
@@ -88,3 +139,20 @@ func (p *Proxy) pingApp(ctx context.Context, app *apps.App) error {
 
 	return nil
 }
+
+// pingEndpoints pings each of the app's endpoints, if it has several, and
+// returns their health. It returns nil if the app has a single endpoint.
+func (p *Proxy) pingEndpoints(ctx context.Context, app *apps.App) []upstream.EndpointStatus {
+	up, err := p.rawUpstreamForApp(app)
+	if err != nil {
+		return nil
+	}
+	pinger, ok := up.(upstream.EndpointPinger)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout(app))
+	defer cancel()
+	return pinger.PingEndpoints(ctx, *app)
+}
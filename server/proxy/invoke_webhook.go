@@ -4,10 +4,17 @@
 package proxy
 
 import (
+	"crypto/hmac"
 	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -17,20 +24,46 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
-func (p *Proxy) InvokeRemoteWebhook(r *incoming.Request, httpCallRequest apps.HTTPCallRequest) error {
-	app, err := p.getEnabledDestination(r)
+// InvokeRemoteWebhook passes an incoming remote webhook request to the app. If
+// the app has RemoteWebhookSynchronous set, the app's response is returned, to
+// be sent back to the remote caller. Otherwise the request is sent to the app
+// as a notification, and the returned response is nil.
+//
+// Deliveries to installed apps are recorded in the webhook log, see
+// GetWebhookDeliveries.
+func (p *Proxy) InvokeRemoteWebhook(r *incoming.Request, httpCallRequest apps.HTTPCallRequest) (resp *apps.HTTPCallResponse, err error) {
+	app, err := p.GetInstalledApp(r.Destination(), false)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	start := time.Now()
+	delivery := newWebhookDelivery(app, httpCallRequest, start)
+	defer func() {
+		finishWebhookDelivery(&delivery, resp, err, time.Since(start))
+		p.webhookLog.add(delivery)
+	}()
+
+	if err = p.ensureEnabled(app); err != nil {
+		return nil, err
 	}
 
 	err = p.validateWebhookAuthentication(app, httpCallRequest)
 	if err != nil {
-		return errors.Wrap(err, "failed to validate webhook authentication")
+		delivery.AuthError = err.Error()
+		return nil, errors.Wrap(err, "failed to validate webhook authentication")
 	}
 
+	err = p.checkWebhookReplay(app, httpCallRequest)
+	if err != nil {
+		delivery.AuthError = err.Error()
+		return nil, errors.Wrap(err, "failed to validate webhook replay protection")
+	}
+	delivery.Authenticated = true
+
 	up, err := p.upstreamForApp(app)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var datav interface{}
@@ -46,10 +79,10 @@ func (p *Proxy) InvokeRemoteWebhook(r *incoming.Request, httpCallRequest apps.HT
 	appRequest := r.WithActingUserID("")
 	cc, err := p.expandContext(appRequest, app, nil, call.Expand, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return upstream.Notify(r.Ctx(), up, *app, apps.CallRequest{
+	creq := apps.CallRequest{
 		Call:    call,
 		Context: *cc,
 		Values: map[string]interface{}{
@@ -58,7 +91,132 @@ func (p *Proxy) InvokeRemoteWebhook(r *incoming.Request, httpCallRequest apps.HT
 			"httpMethod": httpCallRequest.HTTPMethod,
 			"rawQuery":   httpCallRequest.RawQuery,
 		},
-	})
+	}
+
+	if !app.RemoteWebhookSynchronous {
+		return nil, upstream.Notify(r.Ctx(), up, *app, creq)
+	}
+
+	cresp, err := upstream.Call(r.Ctx(), up, *app, creq)
+	if err != nil {
+		return nil, errors.Wrap(err, "upstream call failed")
+	}
+	resp, err = webhookHTTPResponse(cresp)
+	if err != nil {
+		return nil, err
+	}
+	resp, statusErr := checkWebhookStatusCode(resp)
+	if statusErr != nil {
+		delivery.Error = statusErr.Error()
+	}
+	return resp, nil
+}
+
+// checkWebhookStatusCode replaces the app's response with 502 Bad Gateway if
+// its status code is not a final status (200-599) that can be sent back to the
+// remote caller. The returned error is recorded in the webhook log.
+func checkWebhookStatusCode(resp *apps.HTTPCallResponse) (*apps.HTTPCallResponse, error) {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 599 {
+		return resp, nil
+	}
+	return &apps.HTTPCallResponse{
+		StatusCode: http.StatusBadGateway,
+		Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+		Body:       http.StatusText(http.StatusBadGateway),
+	}, errors.Errorf("app returned an invalid status code %v", resp.StatusCode)
+}
+
+// webhookHTTPResponse converts the app's response to a synchronous remote
+// webhook into the HTTP response for the remote caller:
+//   - an "ok" response with an HTTPCallResponse in Data is used as is, the
+//     status code defaults to 200;
+//   - an "ok" response with any other Data is sent as a JSON body;
+//   - an "ok" response with no Data is sent as a plain text body with Text;
+//   - an "error" response is sent as 500, with Text as the body.
+func webhookHTTPResponse(cresp apps.CallResponse) (*apps.HTTPCallResponse, error) {
+	switch cresp.Type {
+	case "", apps.CallResponseTypeOK:
+	case apps.CallResponseTypeError:
+		return &apps.HTTPCallResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			Body:       cresp.Text,
+		}, nil
+	default:
+		return nil, utils.NewInvalidError("unexpected response type %q for a synchronous webhook", cresp.Type)
+	}
+
+	if cresp.Data == nil {
+		resp := &apps.HTTPCallResponse{
+			StatusCode: http.StatusOK,
+			Body:       cresp.Text,
+		}
+		if cresp.Text != "" {
+			resp.Headers = map[string]string{"Content-Type": "text/plain; charset=utf-8"}
+		}
+		return resp, nil
+	}
+
+	var resp apps.HTTPCallResponse
+	utils.Remarshal(&resp, cresp.Data)
+	if resp.StatusCode != 0 || resp.Body != "" || len(resp.Headers) > 0 {
+		resp.Headers = safeWebhookResponseHeaders(resp.Headers)
+		if resp.StatusCode == 0 {
+			resp.StatusCode = http.StatusOK
+		}
+		if resp.IsBase64Encoded {
+			data, err := base64.StdEncoding.DecodeString(resp.Body)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode webhook response body")
+			}
+			resp.Body = string(data)
+			resp.IsBase64Encoded = false
+		}
+		return &resp, nil
+	}
+
+	data, err := json.Marshal(cresp.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode webhook response data")
+	}
+	return &apps.HTTPCallResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(data),
+	}, nil
+}
+
+// webhookResponseHeaders are the headers that an app may set in its
+// synchronous webhook responses, in addition to the custom X- headers. The
+// responses are served from the Mattermost origin, so the headers that affect
+// cookies, security policies, CORS, or redirects are dropped.
+var webhookResponseHeaders = map[string]bool{
+	"Cache-Control": true,
+	"Content-Type":  true,
+}
+
+// unsafeWebhookResponseXHeaders are the X- headers that affect the browser's
+// security policies.
+var unsafeWebhookResponseXHeaders = map[string]bool{
+	"X-Content-Type-Options":            true,
+	"X-Frame-Options":                   true,
+	"X-Permitted-Cross-Domain-Policies": true,
+	"X-Xss-Protection":                  true,
+}
+
+func safeWebhookResponseHeaders(in map[string]string) map[string]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := map[string]string{}
+	for key, value := range in {
+		key = http.CanonicalHeaderKey(key)
+		isCustom := strings.HasPrefix(key, "X-") && !unsafeWebhookResponseXHeaders[key]
+		if webhookResponseHeaders[key] || isCustom {
+			out[key] = value
+		}
+	}
+	return out
 }
 
 func (p *Proxy) ValidateWebhookAuthentication(r *incoming.Request, httpCallRequest apps.HTTPCallRequest) error {
@@ -91,7 +249,13 @@ func (p *Proxy) validateWebhookAuthentication(app *apps.App, httpCallRequest app
 		if secret == "" {
 			return utils.NewInvalidError("webhook secret was not provided")
 		}
-		if subtle.ConstantTimeCompare([]byte(secret), []byte(app.WebhookSecret)) != 1 {
+		matched := false
+		for _, valid := range app.ValidWebhookSecrets(time.Now()) {
+			if subtle.ConstantTimeCompare([]byte(secret), []byte(valid)) == 1 {
+				matched = true
+			}
+		}
+		if !matched {
 			return utils.NewInvalidError("webhook secret mismatched")
 		}
 
@@ -101,3 +265,85 @@ func (p *Proxy) validateWebhookAuthentication(app *apps.App, httpCallRequest app
 
 	return nil
 }
+
+// checkWebhookReplay verifies the timestamp and the signature of an incoming
+// webhook request, and makes sure that it has not been received before. It is
+// a no-op unless the app has RemoteWebhookReplayProtection configured.
+func (p *Proxy) checkWebhookReplay(app *apps.App, httpCallRequest apps.HTTPCallRequest) error {
+	rp := app.RemoteWebhookReplayProtection
+	if rp == nil {
+		return nil
+	}
+
+	now := time.Now()
+	nonces, err := verifyWebhookSignature(*rp, app.ValidWebhookSecrets(now), httpCallRequest, now)
+	if err != nil {
+		return err
+	}
+
+	// Timestamps are accepted within the window on either side of the current
+	// time, so the nonces must be remembered for twice as long.
+	for _, nonce := range nonces {
+		if err = p.store.Webhook.RememberNonce(app.AppID, nonce, 2*rp.Window()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyWebhookSignature validates the timestamp and the signature headers of
+// httpCallRequest, and returns the nonces that identify the request: the
+// normalized signature, and the ID if the app uses an ID header. The ID is not
+// signed, so the signature is recorded as well. The signature is accepted if
+// it matches any of the secrets.
+func verifyWebhookSignature(rp apps.RemoteWebhookReplayProtection, secrets []string, httpCallRequest apps.HTTPCallRequest, now time.Time) ([]string, error) {
+	if len(secrets) == 0 {
+		return nil, utils.NewInvalidError("app has no webhook secret to verify signatures with")
+	}
+
+	tsHeader := httpCallRequest.Headers[rp.GetTimestampHeader()]
+	if tsHeader == "" {
+		return nil, utils.NewForbiddenError("webhook timestamp header %s was not provided", rp.GetTimestampHeader())
+	}
+	ts, err := strconv.ParseInt(tsHeader, 10, 64)
+	if err != nil {
+		return nil, utils.NewForbiddenError("invalid webhook timestamp %q", tsHeader)
+	}
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > rp.Window() {
+		return nil, utils.NewForbiddenError("webhook timestamp is outside of the allowed window of %s", rp.Window())
+	}
+
+	signature := httpCallRequest.Headers[rp.GetSignatureHeader()]
+	if signature == "" {
+		return nil, utils.NewForbiddenError("webhook signature header %s was not provided", rp.GetSignatureHeader())
+	}
+	given, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, utils.NewForbiddenError("invalid webhook signature")
+	}
+	matched := false
+	for _, secret := range secrets {
+		expected, _ := hex.DecodeString(apps.SignRemoteWebhook(secret, ts, httpCallRequest.Body))
+		if hmac.Equal(given, expected) {
+			matched = true
+		}
+	}
+	if !matched {
+		return nil, utils.NewForbiddenError("webhook signature mismatched")
+	}
+
+	// hex.DecodeString accepts either case, the nonce must not depend on it.
+	nonces := []string{"sig:" + hex.EncodeToString(given)}
+	if idHeader := rp.GetIDHeader(); idHeader != "" {
+		id := httpCallRequest.Headers[idHeader]
+		if id == "" {
+			return nil, utils.NewForbiddenError("webhook ID header %s was not provided", idHeader)
+		}
+		nonces = append(nonces, "id:"+id)
+	}
+	return nonces, nil
+}
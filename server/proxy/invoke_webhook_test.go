@@ -0,0 +1,256 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "webhook-secret"
+	const body = `{"event":"test"}`
+	now := time.Unix(1700000000, 0)
+
+	signed := func(ts int64, body string, extra map[string]string) apps.HTTPCallRequest {
+		headers := map[string]string{
+			apps.DefaultRemoteWebhookTimestampHeader: strconv.FormatInt(ts, 10),
+			apps.DefaultRemoteWebhookSignatureHeader: apps.SignRemoteWebhook(secret, ts, body),
+		}
+		for k, v := range extra {
+			headers[k] = v
+		}
+		return apps.HTTPCallRequest{
+			Body:    body,
+			Headers: headers,
+		}
+	}
+
+	for _, tc := range []struct {
+		name           string
+		rp             apps.RemoteWebhookReplayProtection
+		req            apps.HTTPCallRequest
+		expectedNonces []string
+		expectedError  string
+	}{
+		{
+			name:           "valid",
+			req:            signed(now.Unix(), body, nil),
+			expectedNonces: []string{"sig:" + apps.SignRemoteWebhook(secret, now.Unix(), body)},
+		},
+		{
+			name:           "valid with skew",
+			req:            signed(now.Unix()+60, body, nil),
+			expectedNonces: []string{"sig:" + apps.SignRemoteWebhook(secret, now.Unix()+60, body)},
+		},
+		{
+			name: "upper case signature",
+			req: func() apps.HTTPCallRequest {
+				req := signed(now.Unix(), body, nil)
+				req.Headers[apps.DefaultRemoteWebhookSignatureHeader] = strings.ToUpper(req.Headers[apps.DefaultRemoteWebhookSignatureHeader])
+				return req
+			}(),
+			expectedNonces: []string{"sig:" + apps.SignRemoteWebhook(secret, now.Unix(), body)},
+		},
+		{
+			name: "valid with ID and custom headers",
+			rp: apps.RemoteWebhookReplayProtection{
+				TimestampHeader: "x-ts",
+				SignatureHeader: "x-sig",
+				IDHeader:        "x-id",
+			},
+			req: apps.HTTPCallRequest{
+				Body: body,
+				Headers: map[string]string{
+					"X-Ts":  strconv.FormatInt(now.Unix(), 10),
+					"X-Sig": apps.SignRemoteWebhook(secret, now.Unix(), body),
+					"X-Id":  "msg-1",
+				},
+			},
+			expectedNonces: []string{"sig:" + apps.SignRemoteWebhook(secret, now.Unix(), body), "id:msg-1"},
+		},
+		{
+			name:          "expired",
+			req:           signed(now.Add(-10*time.Minute).Unix(), body, nil),
+			expectedError: "webhook timestamp is outside of the allowed window of 5m0s: forbidden",
+		},
+		{
+			name:          "in the future",
+			rp:            apps.RemoteWebhookReplayProtection{WindowSeconds: 30},
+			req:           signed(now.Add(time.Minute).Unix(), body, nil),
+			expectedError: "webhook timestamp is outside of the allowed window of 30s: forbidden",
+		},
+		{
+			name: "tampered body",
+			req: func() apps.HTTPCallRequest {
+				req := signed(now.Unix(), body, nil)
+				req.Body = `{"event":"other"}`
+				return req
+			}(),
+			expectedError: "webhook signature mismatched: forbidden",
+		},
+		{
+			name:          "missing timestamp",
+			req:           apps.HTTPCallRequest{Body: body, Headers: map[string]string{}},
+			expectedError: "webhook timestamp header Mattermost-Webhook-Timestamp was not provided: forbidden",
+		},
+		{
+			name:          "missing ID",
+			rp:            apps.RemoteWebhookReplayProtection{IDHeader: "X-Id"},
+			req:           signed(now.Unix(), body, nil),
+			expectedError: "webhook ID header X-Id was not provided: forbidden",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			nonces, err := verifyWebhookSignature(tc.rp, []string{"new-secret", secret}, tc.req, now)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				require.ErrorIs(t, err, utils.ErrForbidden)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedNonces, nonces)
+		})
+	}
+}
+
+func TestCheckWebhookStatusCode(t *testing.T) {
+	for _, status := range []int{200, 302, 404, 599} {
+		resp, err := checkWebhookStatusCode(&apps.HTTPCallResponse{StatusCode: status, Body: "body"})
+		require.NoError(t, err)
+		require.Equal(t, status, resp.StatusCode)
+		require.Equal(t, "body", resp.Body)
+	}
+	for _, status := range []int{-1, 101, 199, 600, 1000} {
+		resp, err := checkWebhookStatusCode(&apps.HTTPCallResponse{StatusCode: status, Body: "body"})
+		require.EqualError(t, err, fmt.Sprintf("app returned an invalid status code %v", status))
+		require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		require.Equal(t, "Bad Gateway", resp.Body)
+	}
+}
+
+func TestWebhookHTTPResponse(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		cresp         apps.CallResponse
+		expected      *apps.HTTPCallResponse
+		expectedError string
+	}{
+		{
+			name:     "empty",
+			cresp:    apps.CallResponse{Type: apps.CallResponseTypeOK},
+			expected: &apps.HTTPCallResponse{StatusCode: 200},
+		},
+		{
+			name:  "text",
+			cresp: apps.CallResponse{Type: apps.CallResponseTypeOK, Text: "challenge-value"},
+			expected: &apps.HTTPCallResponse{
+				StatusCode: 200,
+				Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+				Body:       "challenge-value",
+			},
+		},
+		{
+			name:  "JSON data",
+			cresp: apps.CallResponse{Data: map[string]string{"challenge": "abc"}},
+			expected: &apps.HTTPCallResponse{
+				StatusCode: 200,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       `{"challenge":"abc"}`,
+			},
+		},
+		{
+			name: "HTTP response",
+			cresp: apps.CallResponse{
+				Type: apps.CallResponseTypeOK,
+				Data: apps.HTTPCallResponse{
+					StatusCode: 202,
+					Headers:    map[string]string{"X-Test": "1"},
+					Body:       "accepted",
+				},
+			},
+			expected: &apps.HTTPCallResponse{
+				StatusCode: 202,
+				Headers:    map[string]string{"X-Test": "1"},
+				Body:       "accepted",
+			},
+		},
+		{
+			name: "HTTP response with unsafe headers",
+			cresp: apps.CallResponse{
+				Type: apps.CallResponseTypeOK,
+				Data: apps.HTTPCallResponse{
+					StatusCode: 302,
+					Headers: map[string]string{
+						"content-type":                 "application/json",
+						"Cache-Control":                "no-store",
+						"X-Custom":                     "1",
+						"Set-Cookie":                   "MMAUTHTOKEN=x",
+						"Content-Security-Policy":      "default-src *",
+						"Access-Control-Allow-Origin":  "*",
+						"Location":                     "https://example.com",
+						"X-Frame-Options":              "ALLOWALL",
+						"Strict-Transport-Security":    "max-age=0",
+						"access-control-allow-headers": "*",
+					},
+				},
+			},
+			expected: &apps.HTTPCallResponse{
+				StatusCode: 302,
+				Headers: map[string]string{
+					"Content-Type":  "application/json",
+					"Cache-Control": "no-store",
+					"X-Custom":      "1",
+				},
+			},
+		},
+		{
+			name: "HTTP response, base64 and default status",
+			cresp: apps.CallResponse{
+				Type: apps.CallResponseTypeOK,
+				Data: map[string]interface{}{
+					"body":            "dG9rZW4=",
+					"isBase64Encoded": true,
+				},
+			},
+			expected: &apps.HTTPCallResponse{
+				StatusCode: 200,
+				Body:       "token",
+			},
+		},
+		{
+			name:  "error",
+			cresp: apps.CallResponse{Type: apps.CallResponseTypeError, Text: "boom"},
+			expected: &apps.HTTPCallResponse{
+				StatusCode: 500,
+				Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+				Body:       "boom",
+			},
+		},
+		{
+			name:          "form",
+			cresp:         apps.CallResponse{Type: apps.CallResponseTypeForm},
+			expectedError: `unexpected response type "form" for a synchronous webhook: invalid input`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := webhookHTTPResponse(tc.cresp)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, resp)
+		})
+	}
+}
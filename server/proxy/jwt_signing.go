@@ -0,0 +1,267 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/uphttp"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const (
+	// RetiredJWTSigningKeyTTL is how long a signing key remains published
+	// after it is replaced by a new one, so that the apps can verify the JWTs
+	// already issued, and refresh their cached key sets.
+	RetiredJWTSigningKeyTTL = 24 * time.Hour
+
+	// jwtSigningKeysCacheTTL is how long the signing keys are cached by each
+	// Mattermost server. A key rotated on another server is used after at most
+	// that long.
+	jwtSigningKeysCacheTTL = time.Minute
+
+	rsaSigningKeyBits = 2048
+)
+
+var _ uphttp.JWTSigner = (*Proxy)(nil)
+
+// jwtSigningKeys caches the parsed signing keys.
+type jwtSigningKeys struct {
+	mutex   sync.Mutex
+	loaded  time.Time
+	keys    []store.SigningKey
+	signers map[string]crypto.Signer
+}
+
+// SignJWT signs claims with the current server-held key for alg, generating
+// the key if there is none yet. The key ID is set as the "kid" JWT header, and
+// the site URL as the issuer. All apps verify the JWTs with the same keys, so
+// the audience must be set to the app the JWT is sent to.
+func (p *Proxy) SignJWT(alg string, claims apps.JWTClaims) (string, error) {
+	method := jwt.GetSigningMethod(alg)
+	if method == nil || (alg != apps.JWTSigningMethodRS256 && alg != apps.JWTSigningMethodEdDSA) {
+		return "", utils.NewInvalidError("unsupported JWT signing method %q", alg)
+	}
+	if claims.Audience == "" {
+		return "", utils.NewInvalidError("JWT audience must be set")
+	}
+	claims.Issuer = p.conf.Get().MattermostSiteURL
+
+	key, signer, err := p.currentSigningKey(alg)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(signer)
+}
+
+// GetJWKS returns the public keys that the outgoing JWTs are signed with,
+// including the recently retired ones.
+func (p *Proxy) GetJWKS() (*apps.JSONWebKeySet, error) {
+	keys, signers, err := p.loadSigningKeys(false)
+	if err != nil {
+		return nil, err
+	}
+	set := &apps.JSONWebKeySet{
+		Keys: []apps.JSONWebKey{},
+	}
+	for _, key := range keys {
+		jwk, err := apps.NewJSONWebKey(key.ID, signers[key.ID].Public())
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, *jwk)
+	}
+	return set, nil
+}
+
+// RotateJWTSigningKey generates a new signing key for alg, and retires the
+// current one. The retired key remains published for RetiredJWTSigningKeyTTL.
+// The ID of the new key is returned.
+func (p *Proxy) RotateJWTSigningKey(r *incoming.Request, alg string) (kid string, err error) {
+	defer func() {
+		if err != nil {
+			r.Log.WithError(err).Errorf("RotateJWTSigningKey failed")
+		} else {
+			r.Log.Infof("Rotated %s JWT signing key, new key ID: %s", alg, kid)
+		}
+	}()
+
+	if err = r.Check(
+		r.RequireActingUser,
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return "", err
+	}
+	if alg != apps.JWTSigningMethodRS256 && alg != apps.JWTSigningMethodEdDSA {
+		return "", utils.NewInvalidError("unsupported JWT signing method %q, must be %s or %s",
+			alg, apps.JWTSigningMethodRS256, apps.JWTSigningMethodEdDSA)
+	}
+
+	newKey, err := newSigningKey(alg)
+	if err != nil {
+		return "", err
+	}
+	_, err = p.store.SigningKeys.Update(func(keys *store.SigningKeys) error {
+		now := time.Now().UnixMilli()
+		for i := range keys.Keys {
+			if keys.Keys[i].Algorithm == alg && keys.Keys[i].RetiredAt == 0 {
+				keys.Keys[i].RetiredAt = now
+			}
+		}
+		newKey.CreatedAt = now
+		keys.Keys = append(pruneSigningKeys(keys.Keys), *newKey)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if _, _, err = p.loadSigningKeys(true); err != nil {
+		return "", err
+	}
+	p.store.Audit.Record(r, apps.AuditJWTSigningKeyRotated, "", map[string]string{
+		"algorithm": alg,
+		"kid":       newKey.ID,
+	})
+	return newKey.ID, nil
+}
+
+func (p *Proxy) currentSigningKey(alg string) (*store.SigningKey, crypto.Signer, error) {
+	keys, signers, err := p.loadSigningKeys(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key := findCurrentSigningKey(keys, alg); key != nil {
+		return key, signers[key.ID], nil
+	}
+
+	// No key for alg yet, generate it unless another server just did.
+	newKey, err := newSigningKey(alg)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = p.store.SigningKeys.Update(func(keys *store.SigningKeys) error {
+		if findCurrentSigningKey(keys.Keys, alg) == nil {
+			newKey.CreatedAt = time.Now().UnixMilli()
+			keys.Keys = append(pruneSigningKeys(keys.Keys), *newKey)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys, signers, err = p.loadSigningKeys(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := findCurrentSigningKey(keys, alg)
+	if key == nil {
+		return nil, nil, errors.Errorf("failed to generate a %s JWT signing key", alg)
+	}
+	return key, signers[key.ID], nil
+}
+
+// loadSigningKeys returns the unexpired signing keys, and their parsed private
+// keys. The keys are reloaded from the store if the cache is stale, or if
+// reload is set.
+func (p *Proxy) loadSigningKeys(reload bool) ([]store.SigningKey, map[string]crypto.Signer, error) {
+	c := &p.jwtSigningKeys
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !reload && c.signers != nil && time.Since(c.loaded) < jwtSigningKeysCacheTTL {
+		return c.keys, c.signers, nil
+	}
+
+	stored, err := p.store.SigningKeys.Get()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load JWT signing keys")
+	}
+	keys := pruneSigningKeys(stored.Keys)
+	signers := map[string]crypto.Signer{}
+	for _, key := range keys {
+		// Reuse the keys parsed already, parsing RSA keys is not free.
+		if signer := c.signers[key.ID]; signer != nil {
+			signers[key.ID] = signer
+			continue
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(key.PrivateKey)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse JWT signing key %s", key.ID)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, nil, errors.Errorf("unsupported JWT signing key type %T", parsed)
+		}
+		signers[key.ID] = signer
+	}
+
+	c.keys = keys
+	c.signers = signers
+	c.loaded = time.Now()
+	return keys, signers, nil
+}
+
+func newSigningKey(alg string) (*store.SigningKey, error) {
+	var privateKey interface{}
+	var err error
+	switch alg {
+	case apps.JWTSigningMethodRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, rsaSigningKeyBits)
+	case apps.JWTSigningMethodEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, utils.NewInvalidError("unsupported JWT signing method %q", alg)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate a %s JWT signing key", alg)
+	}
+	data, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &store.SigningKey{
+		ID:         model.NewId(),
+		Algorithm:  alg,
+		PrivateKey: data,
+	}, nil
+}
+
+func findCurrentSigningKey(keys []store.SigningKey, alg string) *store.SigningKey {
+	for i := range keys {
+		if keys[i].Algorithm == alg && keys[i].RetiredAt == 0 {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// pruneSigningKeys removes the keys retired longer than
+// RetiredJWTSigningKeyTTL ago.
+func pruneSigningKeys(keys []store.SigningKey) []store.SigningKey {
+	pruned := []store.SigningKey{}
+	for _, key := range keys {
+		if key.RetiredAt != 0 && time.Since(time.UnixMilli(key.RetiredAt)) > RetiredJWTSigningKeyTTL {
+			continue
+		}
+		pruned = append(pruned, key)
+	}
+	return pruned
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+)
+
+type testSigningKeyStore struct {
+	keys store.SigningKeys
+}
+
+func (s *testSigningKeyStore) Get() (*store.SigningKeys, error) {
+	keys := store.SigningKeys{Keys: append([]store.SigningKey{}, s.keys.Keys...)}
+	return &keys, nil
+}
+
+func (s *testSigningKeyStore) Update(update func(*store.SigningKeys) error) (*store.SigningKeys, error) {
+	keys, _ := s.Get()
+	if err := update(keys); err != nil {
+		return nil, err
+	}
+	s.keys = *keys
+	return keys, nil
+}
+
+func TestSignJWT(t *testing.T) {
+	keyStore := &testSigningKeyStore{}
+	p := &Proxy{
+		conf:  config.NewTestConfigService(&config.Config{MattermostSiteURL: "https://test.mattermost.test"}),
+		store: &store.Service{SigningKeys: keyStore},
+	}
+
+	verify := func(token string) string {
+		set, err := p.GetJWKS()
+		require.NoError(t, err)
+		claims := apps.JWTClaims{}
+		parsed, err := jwt.ParseWithClaims(token, &claims, set.Keyfunc())
+		require.NoError(t, err)
+		require.Equal(t, "user1", claims.ActingUserID)
+		require.NoError(t, claims.Verify("app1", "https://test.mattermost.test"))
+		require.EqualError(t, claims.Verify("app2", "https://test.mattermost.test"),
+			`JWT audience "app1" does not match app2: unauthorized`)
+		return parsed.Header["kid"].(string)
+	}
+	sign := func(alg string) string {
+		token, err := p.SignJWT(alg, apps.JWTClaims{
+			StandardClaims: jwt.StandardClaims{
+				ExpiresAt: time.Now().Add(time.Minute).Unix(),
+				Audience:  "app1",
+			},
+			ActingUserID: "user1",
+		})
+		require.NoError(t, err)
+		return token
+	}
+
+	// Keys are generated on first use.
+	edKID := verify(sign(apps.JWTSigningMethodEdDSA))
+	rsaKID := verify(sign(apps.JWTSigningMethodRS256))
+	require.Len(t, keyStore.keys.Keys, 2)
+	require.Equal(t, edKID, verify(sign(apps.JWTSigningMethodEdDSA)))
+
+	_, err := p.SignJWT(apps.JWTSigningMethodHS256, apps.JWTClaims{})
+	require.EqualError(t, err, `unsupported JWT signing method "HS256": invalid input`)
+	_, err = p.SignJWT(apps.JWTSigningMethodEdDSA, apps.JWTClaims{})
+	require.EqualError(t, err, `JWT audience must be set: invalid input`)
+
+	// A token signed before a rotation is still verified, new tokens use the
+	// new key.
+	oldToken := sign(apps.JWTSigningMethodEdDSA)
+	newKey, err := newSigningKey(apps.JWTSigningMethodEdDSA)
+	require.NoError(t, err)
+	keyStore.keys.Keys[0].RetiredAt = time.Now().UnixMilli()
+	keyStore.keys.Keys = append(keyStore.keys.Keys, *newKey)
+	_, _, err = p.loadSigningKeys(true)
+	require.NoError(t, err)
+	require.Equal(t, edKID, verify(oldToken))
+	require.Equal(t, newKey.ID, verify(sign(apps.JWTSigningMethodEdDSA)))
+	require.Equal(t, rsaKID, verify(sign(apps.JWTSigningMethodRS256)))
+
+	// Expired retired keys are no longer published.
+	keyStore.keys.Keys[0].RetiredAt = time.Now().Add(-RetiredJWTSigningKeyTTL - time.Minute).UnixMilli()
+	_, _, err = p.loadSigningKeys(true)
+	require.NoError(t, err)
+	set, err := p.GetJWKS()
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 2)
+	for _, k := range set.Keys {
+		require.NotEqual(t, edKID, k.KeyID)
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upws"
 )
 
 const (
@@ -40,8 +42,11 @@ func (p *Proxy) GetApp(r *incoming.Request) (*apps.App, error) {
 	if err = r.RequireSysadminOrPlugin(); err != nil {
 		// Sanitize for non-sysadmins.
 		app.WebhookSecret = ""
+		app.PreviousWebhookSecret = ""
+		app.PreviousWebhookSecretExpiresAt = 0
 		app.MattermostOAuth2 = nil
 		app.RemoteOAuth2 = apps.OAuth2App{}
+		app.TLS = nil
 	}
 	return app, nil
 }
@@ -59,43 +64,77 @@ func (p *Proxy) GetInstalledApp(appID apps.AppID, checkEnabled bool) (*apps.App,
 	return app, nil
 }
 
-func (p *Proxy) PingInstalledApps(ctx context.Context) (installed []apps.App, reachable map[apps.AppID]bool) {
+// PingInstalledApps pings all installed apps. For the apps with several
+// endpoints, each endpoint is pinged, and the app is reachable if any of them
+// is healthy. For the WebSocket apps, the status of their connections is
+// returned, the apps connected to other servers in the cluster are reachable
+// if they are connected.
+func (p *Proxy) PingInstalledApps(ctx context.Context) (installed []apps.App, reachable map[apps.AppID]bool, endpoints map[apps.AppID][]upstream.EndpointStatus, connections map[apps.AppID]*upws.Status) {
 	all := p.store.App.AsMap(store.AllApps)
 	if len(all) == 0 {
-		return nil, nil
+		return nil, nil, nil, nil
 	}
 
-	// all ping requests must respond, unreachable respond with "".
-	reachableCh := make(chan apps.AppID)
-	defer close(reachableCh)
+	type pingResult struct {
+		appID      apps.AppID
+		reachable  bool
+		endpoints  []upstream.EndpointStatus
+		connection *upws.Status
+	}
+
+	// all ping requests must respond.
+	resultCh := make(chan pingResult)
+	defer close(resultCh)
 	for _, app := range all {
 		go func(a apps.App) {
-			var reachable bool
+			result := pingResult{appID: a.AppID}
+
+			if a.DeployType == apps.DeployWebSocket {
+				result.connection = p.getWebSocketStatus(&a)
+			}
 
 			if a.DeployType == apps.DeployBuiltin {
 				// Builtin apps are always rechable
-				reachable = true
+				result.reachable = true
+			} else if c := result.connection; !a.Disabled && c != nil && c.Connected && c.Node != p.nodeID {
+				// Connected to another server, can not be pinged from here.
+				result.reachable = true
 			} else if !a.Disabled {
-				if p.pingApp(ctx, &a) == nil {
-					reachable = true
+				result.endpoints = p.pingEndpoints(ctx, &a)
+				if result.endpoints != nil {
+					for _, s := range result.endpoints {
+						if s.Healthy {
+							result.reachable = true
+						}
+					}
+				} else if p.pingApp(ctx, &a) == nil {
+					result.reachable = true
 				}
 			}
-			if reachable {
-				reachableCh <- a.AppID
-			} else {
-				reachableCh <- ""
-			}
+			resultCh <- result
 		}(app)
 	}
 
 	for _, app := range all {
 		installed = append(installed, app)
-		appID := <-reachableCh
-		if appID != "" {
+		result := <-resultCh
+		if result.reachable {
 			if reachable == nil {
 				reachable = map[apps.AppID]bool{}
 			}
-			reachable[appID] = true
+			reachable[result.appID] = true
+		}
+		if result.endpoints != nil {
+			if endpoints == nil {
+				endpoints = map[apps.AppID][]upstream.EndpointStatus{}
+			}
+			endpoints[result.appID] = result.endpoints
+		}
+		if result.connection != nil {
+			if connections == nil {
+				connections = map[apps.AppID]*upws.Status{}
+			}
+			connections[result.appID] = result.connection
 		}
 	}
 
@@ -104,7 +143,7 @@ func (p *Proxy) PingInstalledApps(ctx context.Context) (installed []apps.App, re
 		return strings.ToLower(installed[i].DisplayName) < strings.ToLower(installed[j].DisplayName)
 	})
 
-	return installed, reachable
+	return installed, reachable, endpoints, connections
 }
 
 func (p *Proxy) GetInstalledApps() []apps.App {
@@ -0,0 +1,85 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+)
+
+func TestGetApp(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).UnixMilli()
+	newApp := func() *apps.App {
+		return &apps.App{
+			Manifest: apps.Manifest{
+				AppID: "app1",
+			},
+			WebhookSecret:                  "secret",
+			PreviousWebhookSecret:          "previous",
+			PreviousWebhookSecretExpiresAt: expiresAt,
+			MattermostOAuth2:               &model.OAuthApp{Id: "oauth_app_id"},
+			RemoteOAuth2:                   apps.OAuth2App{ClientID: "client_id", ClientSecret: "client_secret"},
+			TLS: &apps.TLSConfig{
+				ClientCertificate: "certificate",
+				RootCAs:           "root_cas",
+				ServerName:        "app.internal",
+			},
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		sysadmin bool
+		expected *apps.App
+	}{
+		"sysadmin": {
+			sysadmin: true,
+			expected: newApp(),
+		},
+		"non-sysadmin": {
+			sysadmin: false,
+			expected: &apps.App{
+				Manifest: apps.Manifest{
+					AppID: "app1",
+				},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			testAPI := &plugintest.API{}
+			testAPI.On("HasPermissionTo", "user1", model.PermissionManageSystem).Return(tc.sysadmin)
+			conf := config.NewTestConfigService(nil).WithMattermostAPI(pluginapi.NewClient(testAPI, &plugintest.Driver{}))
+			s, err := store.MakeService(conf, nil)
+			require.NoError(t, err)
+			appStore := mock_store.NewMockAppStore(ctrl)
+			appStore.EXPECT().Get(apps.AppID("app1")).Return(newApp(), nil)
+			s.App = appStore
+
+			p := &Proxy{
+				store: s,
+				conf:  conf,
+			}
+
+			r := incoming.NewRequest(conf, nil).WithDestination("app1").WithActingUserID("user1")
+			app, err := p.GetApp(r)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, app)
+			if !tc.sysadmin {
+				require.Nil(t, app.TLS, "no TLS material for non-sysadmins")
+			}
+		})
+	}
+}
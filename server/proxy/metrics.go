@@ -0,0 +1,22 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+// GetMetricsHandler returns the handler that serves the Prometheus metrics.
+// Only available to system administrators and plugins.
+func (p *Proxy) GetMetricsHandler(r *incoming.Request) (http.Handler, error) {
+	if err := r.Check(
+		r.RequireActingUser,
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, err
+	}
+	return p.metrics.Handler(), nil
+}
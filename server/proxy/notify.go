@@ -9,7 +9,6 @@ import (
 	"github.com/mattermost/mattermost/server/public/model"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
-	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
 )
@@ -224,11 +223,15 @@ func (p *Proxy) notifyAll(event apps.Event, uac apps.UserAgentContext, match fun
 		return
 	}
 
+	timeout := p.conf.Get().IncomingRequestTimeout()
+	notified := 0
+	defer func() { p.metrics.AddNotifications(event.Subject, notified) }()
 	for _, sub := range subs {
 		if match == nil || match(sub) {
 			sub := sub
+			notified++
 			go func() {
-				ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
 				defer cancel()
 				r := p.NewIncomingRequest().WithCtx(ctx)
 				r.Log = log
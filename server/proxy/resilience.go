@@ -0,0 +1,54 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+)
+
+const (
+	DefaultUpstreamMaxRetries       = 2
+	DefaultUpstreamBreakerThreshold = 5
+	DefaultUpstreamBreakerCooldown  = 30 * time.Second
+
+	upstreamInitialBackoff = 200 * time.Millisecond
+	upstreamMaxBackoff     = 2 * time.Second
+)
+
+func retryPolicy(conf config.Config) upstream.RetryPolicy {
+	maxRetries := conf.UpstreamMaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultUpstreamMaxRetries
+	}
+	return upstream.RetryPolicy{
+		MaxRetries:     maxRetries,
+		InitialBackoff: upstreamInitialBackoff,
+		MaxBackoff:     upstreamMaxBackoff,
+	}
+}
+
+func breakerPolicy(conf config.Config) upstream.BreakerPolicy {
+	threshold := conf.UpstreamBreakerThreshold
+	if threshold == 0 {
+		threshold = DefaultUpstreamBreakerThreshold
+	}
+	cooldown := DefaultUpstreamBreakerCooldown
+	if conf.UpstreamBreakerCooldownSeconds > 0 {
+		cooldown = time.Duration(conf.UpstreamBreakerCooldownSeconds) * time.Second
+	}
+	return upstream.BreakerPolicy{
+		FailureThreshold: threshold,
+		Cooldown:         cooldown,
+	}
+}
+
+// GetCircuitBreakerStatus returns the state of the app's circuit breaker on
+// this Mattermost server.
+func (p *Proxy) GetCircuitBreakerStatus(appID apps.AppID) upstream.BreakerStatus {
+	return p.breakers.Status(appID)
+}
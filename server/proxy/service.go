@@ -5,9 +5,11 @@ package proxy
 
 import (
 	"context"
-	"io"
+	"net/http"
 	"sync"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost/server/public/model"
@@ -20,19 +22,29 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/httpout"
 	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/metrics"
 	"github.com/mattermost/mattermost-plugin-apps/server/session"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
 	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/upstream/upaws"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upexec"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upgrpc"
 	"github.com/mattermost/mattermost-plugin-apps/upstream/uphttp"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upkubernetes"
 	"github.com/mattermost/mattermost-plugin-apps/upstream/upopenfaas"
 	"github.com/mattermost/mattermost-plugin-apps/upstream/upplugin"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upwasm"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upws"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
 type Proxy struct {
 	callOnceMutex *cluster.Mutex
 
+	// nodeID identifies this server in the cluster, it changes each time the
+	// plugin is started.
+	nodeID string
+
 	builtinUpstreams map[apps.AppID]upstream.Upstream
 
 	conf           config.Service
@@ -41,6 +53,17 @@ type Proxy struct {
 	upstreams      sync.Map // key: apps.AppID, value upstream.Upstream
 	sessionService session.Service
 	appservices    appservices.Service
+	webhookLog     *webhookLog
+	breakers       *upstream.Breakers
+	httpEndpoints  *uphttp.Endpoints
+	metrics        *metrics.Metrics
+	staticCache    *staticCache
+	bindingsCache  *bindingsCache
+	jwtSigningKeys jwtSigningKeys
+
+	// webSocketForwards are the requests forwarded to the WebSocket apps
+	// connected to the other servers, waiting for the responses.
+	webSocketForwards sync.Map // key: request ID, value: chan webSocketResponseEvent
 }
 
 // Admin defines the REST API methods to manipulate Apps. Since they operate in
@@ -49,7 +72,15 @@ type Proxy struct {
 type Admin interface {
 	DisableApp(*incoming.Request, apps.Context, apps.AppID) (string, error)
 	EnableApp(*incoming.Request, apps.Context, apps.AppID) (string, error)
+	GetAuditLog(*incoming.Request, apps.AuditQuery) ([]apps.AuditRecord, error)
+	GetMetricsHandler(*incoming.Request) (http.Handler, error)
+	GetWebhookDeliveries(*incoming.Request, apps.AppID) ([]apps.WebhookDelivery, error)
 	InstallApp(_ *incoming.Request, _ apps.Context, _ apps.AppID, _ apps.DeployType, trustedApp bool, secret string) (*apps.App, string, error)
+	RotateJWTSigningKey(_ *incoming.Request, alg string) (kid string, err error)
+	RotateWebhookSecret(_ *incoming.Request, _ apps.AppID, gracePeriod time.Duration) (*apps.App, string, error)
+	SetAppTLS(*incoming.Request, apps.AppID, apps.TLSConfig) (string, error)
+	SetWebhookAllowlist(*incoming.Request, apps.AppID, apps.RemoteWebhookAllowlist) (string, error)
+	StoreWasmModule(_ *incoming.Request, _ apps.AppID, _ apps.AppVersion, data []byte) (string, error)
 	UpdateAppListing(*incoming.Request, appclient.UpdateAppListingRequest) (*apps.Manifest, error)
 	UninstallApp(*incoming.Request, apps.Context, apps.AppID, bool) (string, error)
 }
@@ -59,14 +90,17 @@ type Admin interface {
 // request.
 type API interface {
 	// REST API methods used by user agents (mobile, desktop, web).
+	ConnectApp(_ *incoming.Request, token string, upgrade func() (*websocket.Conn, error)) error
 	GetApp(*incoming.Request) (*apps.App, error)
-	GetBindings(*incoming.Request, apps.Context) ([]apps.Binding, error)
+	GetBindings(*incoming.Request, apps.Context) (*apps.BindingsResponse, error)
+	GetJWKS() (*apps.JSONWebKeySet, error)
 	InvokeCall(*incoming.Request, apps.CallRequest) (*apps.App, apps.CallResponse)
 	InvokeCompleteRemoteOAuth2(_ *incoming.Request, urlValues map[string]interface{}) error
 	InvokeGetBindings(*incoming.Request, apps.Context) ([]apps.Binding, error)
 	InvokeGetRemoteOAuth2ConnectURL(*incoming.Request) (string, error)
-	InvokeGetStatic(_ *incoming.Request, path string) (io.ReadCloser, int, error)
-	InvokeRemoteWebhook(*incoming.Request, apps.HTTPCallRequest) error
+	InvokeGetStatic(_ *incoming.Request, path string) (*upstream.StaticAsset, int, error)
+	InvokeRemoteWebhook(*incoming.Request, apps.HTTPCallRequest) (*apps.HTTPCallResponse, error)
+	RecordRejectedWebhook(_ *incoming.Request, _ apps.HTTPCallRequest, reason error)
 	ValidateWebhookAuthentication(*incoming.Request, apps.HTTPCallRequest) error
 }
 
@@ -84,15 +118,18 @@ type Notifier interface {
 // parameters passed in explicitly, using request for logging.
 type Internal interface {
 	AddBuiltinUpstream(apps.AppID, upstream.Upstream)
+	OnPluginClusterEvent(model.PluginClusterEvent)
 	CanDeploy(apps.DeployType) (allowed, usable bool)
 	NewIncomingRequest() *incoming.Request
 	SynchronizeInstalledApps() error
 
 	GetInstalledApp(_ apps.AppID, checkEnabled bool) (*apps.App, error)
 	GetInstalledApps() []apps.App
-	PingInstalledApps(context.Context) (installed []apps.App, reachable map[apps.AppID]bool)
+	PingInstalledApps(context.Context) (installed []apps.App, reachable map[apps.AppID]bool, endpoints map[apps.AppID][]upstream.EndpointStatus, connections map[apps.AppID]*upws.Status)
+	GetCircuitBreakerStatus(apps.AppID) upstream.BreakerStatus
 	GetListedApps(filter string, includePluginApps bool) []apps.ListedApp
 	GetManifest(apps.AppID) (*apps.Manifest, error)
+	InvalidateCachedBindings(_ apps.AppID, userID string)
 }
 
 type Service interface {
@@ -107,8 +144,9 @@ type Service interface {
 
 var _ Service = (*Proxy)(nil)
 
-func NewService(conf config.Service, store *store.Service, mutex *cluster.Mutex, httpOut httpout.Service, session session.Service, appservices appservices.Service) *Proxy {
+func NewService(conf config.Service, store *store.Service, mutex *cluster.Mutex, httpOut httpout.Service, session session.Service, appservices appservices.Service, metrics *metrics.Metrics) *Proxy {
 	return &Proxy{
+		nodeID:           model.NewId(),
 		builtinUpstreams: map[apps.AppID]upstream.Upstream{},
 		conf:             conf,
 		store:            store,
@@ -116,17 +154,28 @@ func NewService(conf config.Service, store *store.Service, mutex *cluster.Mutex,
 		httpOut:          httpOut,
 		sessionService:   session,
 		appservices:      appservices,
+		webhookLog:       newWebhookLog(),
+		breakers:         upstream.NewBreakers(),
+		httpEndpoints:    uphttp.NewEndpoints(),
+		metrics:          metrics,
+		staticCache:      newStaticCache(),
+		bindingsCache:    newBindingsCache(),
 	}
 }
 
 func (p *Proxy) Configure(conf config.Config, log utils.Logger) error {
 	mm := p.conf.MattermostAPI()
+	p.staticCache.configure(conf)
+	p.bindingsCache.configure(conf)
 
 	p.initUpstream(apps.DeployHTTP, conf, log, func() (upstream.Upstream, error) {
-		return uphttp.NewUpstream(p.httpOut, conf.DeveloperMode, uphttp.AppRootURL), nil
+		return uphttp.NewUpstream(p.httpOut, conf.DeveloperMode, uphttp.AppRootURL).
+			WithJWTSigner(p).
+			WithClientKeys(p).
+			WithEndpoints(p.httpEndpoints), nil
 	})
 	p.initUpstream(apps.DeployAWSLambda, conf, log, func() (upstream.Upstream, error) {
-		return upaws.MakeUpstream(conf.AWSAccessKey, conf.AWSSecretKey, conf.AWSRegion, conf.AWSS3Bucket, log)
+		return upaws.MakeUpstream(conf.AWSAccessKey, conf.AWSSecretKey, conf.AWSRegion, conf.AWSS3Bucket, conf.AWSEndpoints, log)
 	})
 	p.initUpstream(apps.DeployPlugin, conf, log, func() (upstream.Upstream, error) {
 		return upplugin.NewUpstream(&mm.Plugin), nil
@@ -134,6 +183,45 @@ func (p *Proxy) Configure(conf config.Config, log utils.Logger) error {
 	p.initUpstream(apps.DeployOpenFAAS, conf, log, func() (upstream.Upstream, error) {
 		return upopenfaas.MakeUpstream(p.httpOut, conf.DeveloperMode)
 	})
+	p.initUpstream(apps.DeployKubernetes, conf, log, func() (upstream.Upstream, error) {
+		return upkubernetes.MakeUpstream(p.httpOut)
+	})
+	p.initUpstream(apps.DeployGRPC, conf, log, func() (upstream.Upstream, error) {
+		// Keep the app connections across configuration changes.
+		if upv, ok := p.upstreams.Load(apps.DeployGRPC); ok {
+			return upv.(upstream.Upstream), nil
+		}
+		return upgrpc.NewUpstream(), nil
+	})
+	p.initUpstream(apps.DeployExec, conf, log, func() (upstream.Upstream, error) {
+		// Keep the running app processes across configuration changes.
+		if upv, ok := p.upstreams.Load(apps.DeployExec); ok {
+			return upv.(upstream.Upstream), nil
+		}
+		return upexec.MakeUpstream(p.conf.NewBaseLogger())
+	})
+	p.initUpstream(apps.DeployWebSocket, conf, log, func() (upstream.Upstream, error) {
+		// Keep the app connections across configuration changes.
+		if upv, ok := p.upstreams.Load(apps.DeployWebSocket); ok {
+			return upv.(upstream.Upstream), nil
+		}
+		return upws.NewUpstream(p.conf.NewBaseLogger()).
+			WithStatusStore(p.store.WebSocket, p.nodeID).
+			WithForwarder(webSocketForwarder{p}), nil
+	})
+	p.initUpstream(apps.DeployWasm, conf, log, func() (upstream.Upstream, error) {
+		limits := upwasm.Limits{
+			MaxTimeout:             conf.MaxCallTimeout(),
+			MaxMemoryMB:            conf.WasmMaxMemoryMB,
+			MaxConcurrentInstances: conf.WasmMaxConcurrentInstances,
+		}
+		// Keep the compiled modules across configuration changes.
+		if upv, ok := p.upstreams.Load(apps.DeployWasm); ok {
+			return upv.(*upwasm.Upstream).WithLimits(limits), nil
+		}
+		return upwasm.NewUpstream(wasmModules{store: p.store.Wasm}, p.conf.NewBaseLogger()).
+			WithLimits(limits), nil
+	})
 	return nil
 }
 
@@ -155,12 +243,15 @@ func (p *Proxy) canDeploy(conf config.Config, deployType apps.DeployType) (allow
 		apps.DeployAWSLambda,
 		apps.DeployBuiltin,
 		apps.DeployPlugin,
+		// Wasm apps run sandboxed, with no access to the network or the
+		// file system.
+		apps.DeployWasm,
 	}
 	if conf.AllowHTTPApps {
-		supportedTypes = append(supportedTypes, apps.DeployHTTP)
+		supportedTypes = append(supportedTypes, apps.DeployHTTP, apps.DeployGRPC, apps.DeployWebSocket)
 	}
 	if !conf.MattermostCloudMode {
-		supportedTypes = append(supportedTypes, apps.DeployOpenFAAS)
+		supportedTypes = append(supportedTypes, apps.DeployOpenFAAS, apps.DeployKubernetes, apps.DeployExec)
 	}
 
 	for _, t := range supportedTypes {
@@ -190,7 +281,25 @@ func (p *Proxy) AddBuiltinUpstream(appID apps.AppID, up upstream.Upstream) {
 	p.store.App.InitBuiltin()
 }
 
+// upstreamForApp returns the app's upstream, wrapped with the retries, the
+// circuit breaker, and tracing.
 func (p *Proxy) upstreamForApp(app *apps.App) (upstream.Upstream, error) {
+	up, err := p.rawUpstreamForApp(app)
+	if err != nil {
+		return nil, err
+	}
+	if app.DeployType == apps.DeployBuiltin {
+		return upstream.Chain(up, upstream.WithTracing()), nil
+	}
+	conf := p.conf.Get()
+	return upstream.Chain(up,
+		upstream.WithCircuitBreaker(p.breakers, breakerPolicy(conf)),
+		upstream.WithRetry(retryPolicy(conf)),
+		upstream.WithTracing(),
+	), nil
+}
+
+func (p *Proxy) rawUpstreamForApp(app *apps.App) (upstream.Upstream, error) {
 	if app.DeployType == apps.DeployBuiltin {
 		u, ok := p.builtinUpstreams[app.AppID]
 		if !ok {
@@ -215,6 +324,26 @@ func (p *Proxy) upstreamForApp(app *apps.App) (upstream.Upstream, error) {
 	return up, nil
 }
 
+// stopUpstream releases the resources that the app's upstream keeps for it,
+// e.g. stops the app's process, and purges its cached static assets and
+// bindings.
+func (p *Proxy) stopUpstream(app *apps.App) {
+	p.staticCache.purge(app.AppID)
+	p.purgeCachedBindings(app.AppID, "")
+	if app.DeployType == apps.DeployBuiltin {
+		return
+	}
+	p.breakers.Reset(app.AppID)
+	p.httpEndpoints.Reset(app.AppID)
+	upv, ok := p.upstreams.Load(app.DeployType)
+	if !ok {
+		return
+	}
+	if stopper, ok := upv.(upstream.AppStopper); ok {
+		stopper.StopApp(app.AppID)
+	}
+}
+
 func (p *Proxy) initUpstream(typ apps.DeployType, newConfig config.Config, log utils.Logger, makef func() (upstream.Upstream, error)) {
 	if allowed, _ := p.canDeploy(newConfig, typ); allowed {
 		up, err := makef()
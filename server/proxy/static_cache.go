@@ -0,0 +1,228 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+const (
+	DefaultStaticCacheSize = 64 * 1024 * 1024
+	DefaultStaticCacheTTL  = 5 * time.Minute
+
+	// maxStaticCacheItemSize is the size of the largest asset that is cached,
+	// larger ones are streamed from the app.
+	maxStaticCacheItemSize = 4 * 1024 * 1024
+)
+
+// staticCache is an in-memory LRU cache of the apps' static assets. Entries
+// older than the TTL are revalidated with the app before they are used.
+type staticCache struct {
+	now func() time.Time
+
+	mutex   sync.Mutex
+	maxSize int
+	ttl     time.Duration
+	size    int
+	lru     *list.List // of *staticCacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type staticCacheEntry struct {
+	key          string
+	appID        apps.AppID
+	data         []byte
+	etag         string
+	lastModified time.Time
+	contentType  string
+	validated    time.Time
+
+	// upstreamETag is the ETag provided by the app, if any. It is used to
+	// revalidate the entry.
+	upstreamETag string
+}
+
+func newStaticCache() *staticCache {
+	return &staticCache{
+		now:     time.Now,
+		maxSize: DefaultStaticCacheSize,
+		ttl:     DefaultStaticCacheTTL,
+		lru:     list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func staticCacheKey(app *apps.App, assetPath string) string {
+	return path.Join(string(app.AppID), string(app.Version), string(app.DeployType), assetPath)
+}
+
+// configure applies the cache settings from conf, evicting the entries that
+// no longer fit.
+func (c *staticCache) configure(conf config.Config) {
+	maxSize := DefaultStaticCacheSize
+	switch {
+	case conf.StaticCacheSizeMB < 0:
+		maxSize = 0
+	case conf.StaticCacheSizeMB > 0:
+		maxSize = conf.StaticCacheSizeMB * 1024 * 1024
+	}
+	ttl := DefaultStaticCacheTTL
+	if conf.StaticCacheTTLSeconds > 0 {
+		ttl = time.Duration(conf.StaticCacheTTLSeconds) * time.Second
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maxSize = maxSize
+	c.ttl = ttl
+	c.evict()
+}
+
+func (c *staticCache) enabled() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.maxSize > 0
+}
+
+func (c *staticCache) maxItemSize() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.maxSize < maxStaticCacheItemSize {
+		return c.maxSize
+	}
+	return maxStaticCacheItemSize
+}
+
+// get returns the cached entry, and whether it needs to be revalidated.
+func (c *staticCache) get(key string) (entry *staticCacheEntry, stale bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem := c.entries[key]
+	if elem == nil {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	entry = elem.Value.(*staticCacheEntry)
+	return entry, c.now().Sub(entry.validated) >= c.ttl
+}
+
+// revalidated marks the entry as fresh, after the app confirmed it has not
+// changed.
+func (c *staticCache) revalidated(entry *staticCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if elem := c.entries[entry.key]; elem != nil && elem.Value == entry {
+		fresh := *entry
+		fresh.validated = c.now()
+		elem.Value = &fresh
+	}
+}
+
+func (c *staticCache) put(entry *staticCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(entry.data) > c.maxSize {
+		return
+	}
+	if elem := c.entries[entry.key]; elem != nil {
+		c.remove(elem)
+	}
+	entry.validated = c.now()
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	c.size += len(entry.data)
+	c.evict()
+}
+
+// purge removes all cached assets of the app.
+func (c *staticCache) purge(appID apps.AppID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*staticCacheEntry).appID == appID {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+func (c *staticCache) evict() {
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *staticCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*staticCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= len(entry.data)
+}
+
+func (e *staticCacheEntry) asset() *upstream.StaticAsset {
+	return upstream.NewCachedStaticAsset(e.data, e.etag, e.lastModified, e.contentType)
+}
+
+// cacheStaticAsset reads the asset, and caches it if it is small enough. The
+// returned asset must be used instead of the original one.
+func (p *Proxy) cacheStaticAsset(key string, app *apps.App, assetPath string, asset *upstream.StaticAsset) (*upstream.StaticAsset, error) {
+	if asset.NoStore || !p.staticCache.enabled() {
+		return asset, nil
+	}
+
+	maxSize := p.staticCache.maxItemSize()
+	data, err := io.ReadAll(io.LimitReader(asset, int64(maxSize)+1))
+	if err != nil {
+		asset.Close()
+		return nil, err
+	}
+	if len(data) > maxSize {
+		// Too large to cache, stream the rest of it from the app.
+		return &upstream.StaticAsset{
+			ReadCloser: struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(data), asset.ReadCloser), asset.ReadCloser},
+			ETag:         asset.ETag,
+			LastModified: asset.LastModified,
+			ContentType:  asset.ContentType,
+		}, nil
+	}
+	_ = asset.Close()
+
+	entry := &staticCacheEntry{
+		key:          key,
+		appID:        app.AppID,
+		data:         data,
+		etag:         asset.ETag,
+		lastModified: asset.LastModified,
+		contentType:  asset.ContentType,
+		upstreamETag: asset.ETag,
+	}
+	if entry.etag == "" {
+		sum := sha256.Sum256(data)
+		entry.etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+	if entry.contentType == "" {
+		entry.contentType = mime.TypeByExtension(path.Ext(assetPath))
+		if entry.contentType == "" {
+			entry.contentType = httputils.PassiveContentType(http.DetectContentType(data))
+		}
+	}
+	p.staticCache.put(entry)
+	return entry.asset(), nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+)
+
+type testStaticUpstream struct {
+	data        map[string]string
+	etag        string
+	fetched     int
+	revalidated int
+	tls         *apps.TLSConfig
+}
+
+func (u *testStaticUpstream) Roundtrip(context.Context, apps.App, apps.CallRequest, bool) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func (u *testStaticUpstream) GetStatic(ctx context.Context, app apps.App, path string) (io.ReadCloser, int, error) {
+	u.tls = app.TLS
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/"+path, nil)
+	upstream.SetConditionalHeaders(ctx, req)
+	if u.etag != "" && req.Header.Get("If-None-Match") == u.etag {
+		u.revalidated++
+		return nil, http.StatusNotModified, nil
+	}
+
+	u.fetched++
+	data, ok := u.data[path]
+	if !ok {
+		return io.NopCloser(strings.NewReader("not found")), http.StatusNotFound, nil
+	}
+	header := http.Header{}
+	if u.etag != "" {
+		header.Set("ETag", u.etag)
+	}
+	return upstream.NewStaticAsset(io.NopCloser(strings.NewReader(data)), header), http.StatusOK, nil
+}
+
+func TestStaticCache(t *testing.T) {
+	now := time.Now()
+	c := newStaticCache()
+	c.now = func() time.Time { return now }
+	c.configure(config.Config{StoredConfig: config.StoredConfig{StaticCacheSizeMB: 1, StaticCacheTTLSeconds: 10}})
+
+	put := func(appID apps.AppID, key string, size int) {
+		c.put(&staticCacheEntry{key: key, appID: appID, data: make([]byte, size)})
+	}
+	put("app1", "a", 400*1024)
+	put("app1", "b", 400*1024)
+	put("app2", "c", 100*1024)
+
+	// Use a, so that b is evicted first.
+	entry, stale := c.get("a")
+	require.NotNil(t, entry)
+	require.False(t, stale)
+	put("app2", "d", 300*1024)
+	entry, _ = c.get("b")
+	require.Nil(t, entry)
+	require.Equal(t, 800*1024, c.size)
+
+	now = now.Add(10 * time.Second)
+	_, stale = c.get("a")
+	require.True(t, stale)
+
+	c.purge("app2")
+	require.Equal(t, 400*1024, c.size)
+	entry, _ = c.get("c")
+	require.Nil(t, entry)
+
+	c.configure(config.Config{StoredConfig: config.StoredConfig{StaticCacheSizeMB: -1}})
+	require.False(t, c.enabled())
+	require.Equal(t, 0, c.size)
+}
+
+func TestGetStatic(t *testing.T) {
+	for name, etag := range map[string]string{
+		"upstream ETag": `"v1"`,
+		"computed ETag": "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			up := &testStaticUpstream{
+				data: map[string]string{
+					"icon.png":  "icon",
+					"large.png": strings.Repeat("x", maxStaticCacheItemSize+1),
+					"page":      "<html><script>alert(1)</script></html>",
+				},
+				etag: etag,
+			}
+			p := &Proxy{
+				builtinUpstreams: map[apps.AppID]upstream.Upstream{"app1": up},
+				staticCache:      newStaticCache(),
+			}
+			p.staticCache.now = func() time.Time { return now }
+			app := &apps.App{
+				DeployType: apps.DeployBuiltin,
+				Manifest:   apps.Manifest{AppID: "app1", Version: "v1.0.0"},
+			}
+			r := incoming.NewRequest(config.NewTestConfigService(nil), nil)
+
+			get := func(path string) (*upstream.StaticAsset, string) {
+				asset, status, err := p.getStatic(r, app, path)
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, status)
+				data, err := io.ReadAll(asset)
+				require.NoError(t, err)
+				return asset, string(data)
+			}
+
+			asset, data := get("icon.png")
+			require.Equal(t, "icon", data)
+			require.Equal(t, "image/png", asset.ContentType)
+			require.NotEmpty(t, asset.ETag)
+			if etag != "" {
+				require.Equal(t, etag, asset.ETag)
+			}
+			_, seekable := asset.ReadCloser.(io.ReadSeeker)
+			require.True(t, seekable)
+
+			_, data = get("icon.png")
+			require.Equal(t, "icon", data)
+			require.Equal(t, 1, up.fetched)
+
+			// Stale, revalidated if the app provides an ETag, fetched again
+			// otherwise.
+			now = now.Add(DefaultStaticCacheTTL)
+			_, data = get("icon.png")
+			require.Equal(t, "icon", data)
+			if etag != "" {
+				require.Equal(t, 1, up.fetched)
+				require.Equal(t, 1, up.revalidated)
+			} else {
+				require.Equal(t, 2, up.fetched)
+			}
+			fetched := up.fetched
+			_, _ = get("icon.png")
+			require.Equal(t, fetched, up.fetched)
+
+			// Detected HTML is not served as such.
+			asset, _ = get("page")
+			require.Equal(t, "text/plain", asset.ContentType)
+			fetched++
+
+			// Too large to cache.
+			_, data = get("large.png")
+			require.Len(t, data, maxStaticCacheItemSize+1)
+			_, _ = get("large.png")
+			require.Equal(t, fetched+2, up.fetched)
+
+			// Errors are not cached.
+			_, status, err := p.getStatic(r, app, "missing.png")
+			require.NoError(t, err)
+			require.Equal(t, http.StatusNotFound, status)
+			_, status, _ = p.getStatic(r, app, "missing.png")
+			require.Equal(t, http.StatusNotFound, status)
+			require.Equal(t, fetched+4, up.fetched)
+		})
+	}
+}
+
+func TestInvokeGetStaticUsesTLS(t *testing.T) {
+	app := apps.App{
+		DeployType: apps.DeployBuiltin,
+		Manifest:   apps.Manifest{AppID: "app1", Version: "v1.0.0"},
+		TLS:        &apps.TLSConfig{ClientCertificate: "cert"},
+	}
+	ctrl := gomock.NewController(t)
+	appStore := mock_store.NewMockAppStore(ctrl)
+	appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+	up := &testStaticUpstream{data: map[string]string{"icon.png": "icon"}}
+	p := &Proxy{
+		store:            &store.Service{App: appStore},
+		builtinUpstreams: map[apps.AppID]upstream.Upstream{app.AppID: up},
+		staticCache:      newStaticCache(),
+	}
+
+	// A regular user, who can not see the app's TLS configuration.
+	r := incoming.NewRequest(config.NewTestConfigService(nil), nil).
+		WithDestination(app.AppID).
+		WithActingUserID("user1")
+	_, status, err := p.InvokeGetStatic(r, "icon.png")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, app.TLS, up.tls)
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
-	"github.com/mattermost/mattermost-plugin-apps/server/config"
 	"github.com/mattermost/mattermost-plugin-apps/server/store"
 )
 
@@ -19,7 +18,7 @@ const PrevVersion = "prev_version"
 // SynchronizeInstalledApps synchronizes installed apps with known manifests,
 // performing OnVersionChanged call on the App as needed.
 func (p *Proxy) SynchronizeInstalledApps() error {
-	ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), p.conf.Get().IncomingRequestTimeout())
 	defer cancel()
 
 	r := p.NewIncomingRequest().WithCtx(ctx)
@@ -49,6 +48,7 @@ func (p *Proxy) SynchronizeInstalledApps() error {
 		if err != nil {
 			return err
 		}
+		p.purgeCachedBindings(app.AppID, "")
 
 		// Call OnVersionChanged the function of the app. It should be called only once
 		if app.OnVersionChanged != nil {
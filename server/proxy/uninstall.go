@@ -5,6 +5,7 @@ package proxy
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/pkg/errors"
 
@@ -79,12 +80,22 @@ func (p *Proxy) UninstallApp(r *incoming.Request, cc apps.Context, appID apps.Ap
 		return "", errors.Wrapf(err, "failed to clear subscriptions for %s, the app is left disabled", appID)
 	}
 
+	// Remove the TLS client key.
+	if err = p.store.TLSKey.Delete(appID); err != nil {
+		return "", errors.Wrapf(err, "failed to delete the TLS client key for %s, the app is left disabled", appID)
+	}
+
 	// Delete the main record of the app.
 	if err = p.store.App.Delete(r, app.AppID); err != nil {
 		return "", errors.Wrapf(err, "can't delete app %s, the app is left disabled", appID)
 	}
+	p.webhookLog.clear(app.AppID)
+	p.stopUpstream(app)
 
 	r.Log.Infof("Uninstalled app %s.", appID)
+	p.store.Audit.Record(r, apps.AuditAppUninstalled, app.AppID, map[string]string{
+		"force": strconv.FormatBool(force),
+	})
 
 	p.conf.Telemetry().TrackUninstall(string(app.AppID), string(app.DeployType))
 
@@ -39,6 +39,9 @@ func (p *Proxy) UpdateAppListing(r *incoming.Request, req appclient.UpdateAppLis
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to update listed manifest")
 	}
+	p.store.Audit.Record(r, apps.AuditAppListingUpdated, req.Manifest.AppID, map[string]string{
+		"version": string(req.Manifest.Version),
+	})
 
 	return &req.Manifest, nil
 }
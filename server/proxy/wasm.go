@@ -0,0 +1,83 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upwasm"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// wasmMagic is the header of a binary WebAssembly module.
+var wasmMagic = []byte("\x00asm")
+
+// StoreWasmModule uploads the WebAssembly module for a version of a wasm app.
+// Only the most recently uploaded module is kept for an app; the app's running
+// instances on this server are terminated, and the new module is used for the
+// subsequent requests. The other servers in the cluster reload the module when
+// they find that its hash has changed.
+func (p *Proxy) StoreWasmModule(r *incoming.Request, appID apps.AppID, version apps.AppVersion, data []byte) (string, error) {
+	if err := r.Check(
+		r.RequireActingUser,
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return "", err
+	}
+	if err := appID.Validate(); err != nil {
+		return "", err
+	}
+	if err := version.Validate(); err != nil {
+		return "", err
+	}
+	if !bytes.HasPrefix(data, wasmMagic) {
+		return "", utils.NewInvalidError("not a WebAssembly module")
+	}
+
+	m, err := p.store.Wasm.Save(appID, version, data)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to store WebAssembly module")
+	}
+
+	p.stopUpstream(&apps.App{
+		Manifest:   apps.Manifest{AppID: appID},
+		DeployType: apps.DeployWasm,
+	})
+
+	r.Log.Infow("Stored WebAssembly module", "app_id", appID, "version", version, "sha256", m.SHA256, "size", len(data))
+	p.store.Audit.Record(r, apps.AuditWasmModuleStored, appID, map[string]string{
+		"version": string(version),
+		"sha256":  m.SHA256,
+	})
+	return fmt.Sprintf("Stored WebAssembly module for %s %s, %v bytes, sha256 %s.", appID, version, len(data), m.SHA256), nil
+}
+
+// wasmModules provides the stored WebAssembly modules to the wasm upstream.
+type wasmModules struct {
+	store store.WasmStore
+}
+
+var _ upwasm.ModuleStore = wasmModules{}
+
+func (w wasmModules) ModuleHash(appID apps.AppID, version apps.AppVersion) (string, error) {
+	m, err := w.store.GetInfo(appID, version)
+	if err != nil {
+		return "", err
+	}
+	return m.SHA256, nil
+}
+
+func (w wasmModules) LoadModule(appID apps.AppID, version apps.AppVersion) ([]byte, string, error) {
+	m, err := w.store.Get(appID, version)
+	if err != nil {
+		return nil, "", err
+	}
+	return m.Data, m.SHA256, nil
+}
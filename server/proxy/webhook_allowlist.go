@@ -0,0 +1,52 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+// SetWebhookAllowlist replaces the source IP allowlist for an app's remote
+// webhooks. An empty allowlist removes the restriction.
+func (p *Proxy) SetWebhookAllowlist(r *incoming.Request, appID apps.AppID, allowlist apps.RemoteWebhookAllowlist) (string, error) {
+	if err := r.Check(
+		r.RequireActingUser,
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return "", err
+	}
+	if err := allowlist.Validate(); err != nil {
+		return "", err
+	}
+
+	app, err := p.GetInstalledApp(appID, false)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get app. appID: %s", appID)
+	}
+
+	message := fmt.Sprintf("Removed the webhook allowlist for %s.", app.DisplayName)
+	if allowlist.IsEmpty() {
+		app.WebhookAllowlist = nil
+	} else {
+		app.WebhookAllowlist = &allowlist
+		message = fmt.Sprintf("Updated the webhook allowlist for %s.", app.DisplayName)
+	}
+
+	err = p.store.App.Save(r, *app)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to save app. appID: %s", appID)
+	}
+
+	r.Log.With("allowlist", allowlist).Infof("Updated webhook allowlist for app %s", appID)
+	p.store.Audit.Record(r, apps.AuditWebhookAllowlistUpdated, appID, map[string]string{
+		"cidrs": strings.Join(allowlist.CIDRs, ","),
+	})
+	return message, nil
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+)
+
+// WebhookLogSize is the maximum number of deliveries kept per app.
+const WebhookLogSize = 50
+
+const redactedValue = "[redacted]"
+
+// redactedHeaderSubstrings are matched against lowercased header names to
+// detect the headers that may contain credentials.
+var redactedHeaderSubstrings = []string{
+	"auth",
+	"cookie",
+	"key",
+	"password",
+	"secret",
+	"signature",
+	"token",
+}
+
+// webhookLog keeps the most recent remote webhook deliveries for each app, in
+// memory. Each Mattermost server in a cluster keeps its own log.
+type webhookLog struct {
+	mutex sync.Mutex
+	byApp map[apps.AppID]*webhookRing
+}
+
+type webhookRing struct {
+	entries []apps.WebhookDelivery
+	next    int
+}
+
+func newWebhookLog() *webhookLog {
+	return &webhookLog{
+		byApp: map[apps.AppID]*webhookRing{},
+	}
+}
+
+func (l *webhookLog) add(d apps.WebhookDelivery) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	ring := l.byApp[d.AppID]
+	if ring == nil {
+		ring = &webhookRing{}
+		l.byApp[d.AppID] = ring
+	}
+	if len(ring.entries) < WebhookLogSize {
+		ring.entries = append(ring.entries, d)
+		return
+	}
+	ring.entries[ring.next] = d
+	ring.next = (ring.next + 1) % WebhookLogSize
+}
+
+// list returns the deliveries for the app, most recent first.
+func (l *webhookLog) list(appID apps.AppID) []apps.WebhookDelivery {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	out := []apps.WebhookDelivery{}
+	ring := l.byApp[appID]
+	if ring == nil {
+		return out
+	}
+	n := len(ring.entries)
+	for i := 0; i < n; i++ {
+		out = append(out, ring.entries[(ring.next+n-1-i)%n])
+	}
+	return out
+}
+
+func (l *webhookLog) clear(appID apps.AppID) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.byApp, appID)
+}
+
+// GetWebhookDeliveries returns the most recent remote webhook deliveries for
+// an app, most recent first.
+func (p *Proxy) GetWebhookDeliveries(r *incoming.Request, appID apps.AppID) ([]apps.WebhookDelivery, error) {
+	if err := r.Check(
+		r.RequireActingUser,
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, err
+	}
+	if _, err := p.GetInstalledApp(appID, false); err != nil {
+		return nil, err
+	}
+	return p.webhookLog.list(appID), nil
+}
+
+// RecordRejectedWebhook records a remote webhook request that was rejected
+// before it reached the app, e.g. by the app's source IP allowlist, in the
+// webhook log. The reason is recorded as the delivery's AuthError.
+func (p *Proxy) RecordRejectedWebhook(r *incoming.Request, httpCallRequest apps.HTTPCallRequest, reason error) {
+	app, err := p.GetInstalledApp(r.Destination(), false)
+	if err != nil {
+		return
+	}
+	delivery := newWebhookDelivery(app, httpCallRequest, time.Now())
+	delivery.AuthError = reason.Error()
+	finishWebhookDelivery(&delivery, nil, reason, 0)
+	p.webhookLog.add(delivery)
+}
+
+func newWebhookDelivery(app *apps.App, httpCallRequest apps.HTTPCallRequest, start time.Time) apps.WebhookDelivery {
+	var extraRedacted []string
+	if rp := app.RemoteWebhookReplayProtection; rp != nil {
+		extraRedacted = append(extraRedacted, rp.GetSignatureHeader())
+	}
+	return apps.WebhookDelivery{
+		Time:     start,
+		AppID:    app.AppID,
+		Path:     httpCallRequest.Path,
+		Method:   httpCallRequest.HTTPMethod,
+		Query:    redactQuery(httpCallRequest.RawQuery),
+		Headers:  redactHeaders(httpCallRequest.Headers, extraRedacted...),
+		BodySize: len(httpCallRequest.Body),
+	}
+}
+
+func finishWebhookDelivery(d *apps.WebhookDelivery, resp *apps.HTTPCallResponse, err error, elapsed time.Duration) {
+	d.Elapsed = elapsed.String()
+	switch {
+	case err != nil:
+		d.StatusCode = httputils.ErrorToStatus(err)
+		if d.AuthError == "" {
+			d.Error = err.Error()
+		}
+	case resp != nil:
+		d.StatusCode = resp.StatusCode
+	default:
+		d.StatusCode = http.StatusOK
+	}
+}
+
+func redactHeaders(in map[string]string, extra ...string) map[string]string {
+	out := map[string]string{}
+	for key, value := range in {
+		if isSecretHeader(key, extra) {
+			value = redactedValue
+		}
+		out[key] = value
+	}
+	return out
+}
+
+func isSecretHeader(key string, extra []string) bool {
+	for _, e := range extra {
+		if strings.EqualFold(key, e) {
+			return true
+		}
+	}
+	lower := strings.ToLower(key)
+	for _, s := range redactedHeaderSubstrings {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redactedValue
+	}
+	for key := range q {
+		if isSecretHeader(key, nil) {
+			q[key] = []string{redactedValue}
+		}
+	}
+	return q.Encode()
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestWebhookLog(t *testing.T) {
+	l := newWebhookLog()
+	require.Empty(t, l.list("app1"))
+
+	for i := 0; i < WebhookLogSize+5; i++ {
+		l.add(apps.WebhookDelivery{AppID: "app1", Path: strconv.Itoa(i)})
+	}
+	l.add(apps.WebhookDelivery{AppID: "app2", Path: "other"})
+
+	list := l.list("app1")
+	require.Len(t, list, WebhookLogSize)
+	require.Equal(t, strconv.Itoa(WebhookLogSize+4), list[0].Path)
+	require.Equal(t, "5", list[WebhookLogSize-1].Path)
+	require.Len(t, l.list("app2"), 1)
+
+	l.clear("app1")
+	require.Empty(t, l.list("app1"))
+	require.Len(t, l.list("app2"), 1)
+}
+
+func TestNewWebhookDelivery(t *testing.T) {
+	app := &apps.App{
+		Manifest: apps.Manifest{
+			AppID: "app1",
+			RemoteWebhookReplayProtection: &apps.RemoteWebhookReplayProtection{
+				SignatureHeader: "X-Hub-Sig",
+			},
+		},
+	}
+	d := newWebhookDelivery(app, apps.HTTPCallRequest{
+		Path:       "events",
+		HTTPMethod: "POST",
+		RawQuery:   "secret=abc&kind=push",
+		Body:       "12345",
+		Headers: map[string]string{
+			"Authorization": "Bearer xyz",
+			"Content-Type":  "application/json",
+			"X-Api-Key":     "xyz",
+			"X-Hub-Sig":     "xyz",
+		},
+	}, time.Unix(1700000000, 0))
+
+	require.Equal(t, apps.AppID("app1"), d.AppID)
+	require.Equal(t, 5, d.BodySize)
+	require.Equal(t, "kind=push&secret=%5Bredacted%5D", d.Query)
+	require.Equal(t, map[string]string{
+		"Authorization": redactedValue,
+		"Content-Type":  "application/json",
+		"X-Api-Key":     redactedValue,
+		"X-Hub-Sig":     redactedValue,
+	}, d.Headers)
+}
+
+func TestRecordRejectedWebhook(t *testing.T) {
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "app1",
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	conf := config.NewTestConfigService(nil)
+	s, err := store.MakeService(conf, nil)
+	require.NoError(t, err)
+	appStore := mock_store.NewMockAppStore(ctrl)
+	appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+	s.App = appStore
+
+	p := &Proxy{
+		store:      s,
+		conf:       conf,
+		webhookLog: newWebhookLog(),
+	}
+
+	r := incoming.NewRequest(conf, nil).WithDestination(app.AppID)
+	reason := utils.NewForbiddenError("source IP address 10.0.0.1 is not allowed to send webhooks to app1")
+	p.RecordRejectedWebhook(r, apps.HTTPCallRequest{Path: "events", HTTPMethod: "POST"}, reason)
+
+	list := p.webhookLog.list(app.AppID)
+	require.Len(t, list, 1)
+	require.Equal(t, "events", list[0].Path)
+	require.False(t, list[0].Authenticated)
+	require.Equal(t, reason.Error(), list[0].AuthError)
+	require.Empty(t, list[0].Error)
+	require.Equal(t, http.StatusForbidden, list[0].StatusCode)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// DefaultWebhookSecretGracePeriod is how long the previous webhook secret is
+// accepted after a rotation, unless specified otherwise.
+const DefaultWebhookSecretGracePeriod = 24 * time.Hour
+
+// RotateWebhookSecret generates a new webhook secret for an app. The previous
+// secret remains valid for gracePeriod, a zero gracePeriod revokes it
+// immediately. The updated, unsanitized app record is returned.
+func (p *Proxy) RotateWebhookSecret(r *incoming.Request, appID apps.AppID, gracePeriod time.Duration) (_ *apps.App, _ string, err error) {
+	start := time.Now()
+	defer func() {
+		log := r.Log.With("elapsed", time.Since(start).String(), "grace_period", gracePeriod.String())
+		if err != nil {
+			log.WithError(err).Errorf("RotateWebhookSecret failed")
+		} else {
+			log.Infof("Rotated webhook secret for app %s", appID)
+		}
+	}()
+
+	if err = r.Check(
+		r.RequireActingUser,
+		r.RequireSysadminOrPlugin,
+	); err != nil {
+		return nil, "", err
+	}
+	if gracePeriod < 0 {
+		return nil, "", utils.NewInvalidError("grace period must not be negative")
+	}
+
+	app, err := p.GetInstalledApp(appID, false)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to get app. appID: %s", appID)
+	}
+	if app.RemoteWebhookAuthType != "" && app.RemoteWebhookAuthType != apps.SecretAuth {
+		return nil, "", utils.NewInvalidError("%s does not use %q webhook authentication", appID, apps.SecretAuth)
+	}
+
+	app.PreviousWebhookSecret = ""
+	app.PreviousWebhookSecretExpiresAt = 0
+	if gracePeriod > 0 && app.WebhookSecret != "" {
+		app.PreviousWebhookSecret = app.WebhookSecret
+		app.PreviousWebhookSecretExpiresAt = start.Add(gracePeriod).UnixMilli()
+	}
+	app.WebhookSecret = model.NewId()
+
+	err = p.store.App.Save(r, *app)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to save app. appID: %s", appID)
+	}
+
+	p.store.Audit.Record(r, apps.AuditWebhookSecretRotated, app.AppID, map[string]string{
+		"grace_period": gracePeriod.String(),
+	})
+
+	message := fmt.Sprintf("Rotated the webhook secret for %s.", app.DisplayName)
+	if app.PreviousWebhookSecret != "" {
+		message += fmt.Sprintf(" The previous secret will be accepted until %s.", time.UnixMilli(app.PreviousWebhookSecretExpiresAt).UTC().Format(time.RFC1123))
+	} else {
+		message += " The previous secret is no longer accepted."
+	}
+	return app, message, nil
+}
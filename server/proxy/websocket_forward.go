@@ -0,0 +1,170 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upws"
+)
+
+const (
+	// ClusterEventWebSocketRequest is the ID of the plugin cluster event that
+	// forwards a request to a WebSocket app connected to another server.
+	ClusterEventWebSocketRequest = "websocket_request"
+
+	// ClusterEventWebSocketResponse is the ID of the plugin cluster event that
+	// returns the app's response to the server that forwarded the request.
+	ClusterEventWebSocketResponse = "websocket_response"
+
+	// webSocketForwardGracePeriod is added to the request timeout, to allow
+	// for the cluster round trip.
+	webSocketForwardGracePeriod = 5 * time.Second
+)
+
+// webSocketRequestEvent is broadcast to all servers, only the server To
+// handles it.
+type webSocketRequestEvent struct {
+	ID      string                 `json:"id"`
+	From    string                 `json:"from"`
+	To      string                 `json:"to"`
+	AppID   apps.AppID             `json:"app_id"`
+	Request upstream.StreamRequest `json:"request"`
+	Timeout time.Duration          `json:"timeout"`
+}
+
+type webSocketResponseEvent struct {
+	ID       string                  `json:"id"`
+	To       string                  `json:"to"`
+	Response upstream.StreamResponse `json:"response"`
+	Error    string                  `json:"error,omitempty"`
+}
+
+// webSocketForwarder forwards the requests to the WebSocket apps connected to
+// the other servers in the cluster, using plugin cluster events.
+type webSocketForwarder struct {
+	*Proxy
+}
+
+var _ upws.Forwarder = webSocketForwarder{}
+
+func (f webSocketForwarder) Forward(ctx context.Context, node string, appID apps.AppID, req upstream.StreamRequest, timeout time.Duration) (upstream.StreamResponse, error) {
+	ev := webSocketRequestEvent{
+		ID:      model.NewId(),
+		From:    f.nodeID,
+		To:      node,
+		AppID:   appID,
+		Request: req,
+		Timeout: timeout,
+	}
+
+	// Notifications are not responded to.
+	var respC chan webSocketResponseEvent
+	if req.Type != upstream.StreamMessageTypeNotify {
+		respC = make(chan webSocketResponseEvent, 1)
+		f.webSocketForwards.Store(ev.ID, respC)
+		defer f.webSocketForwards.Delete(ev.ID)
+	}
+
+	err := f.publishClusterEvent(ClusterEventWebSocketRequest, ev)
+	if err != nil {
+		return upstream.StreamResponse{}, errors.Wrapf(err, "failed to forward the request to Mattermost server %s", node)
+	}
+	if respC == nil {
+		return upstream.StreamResponse{}, nil
+	}
+
+	wait := timeout + webSocketForwardGracePeriod
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case resp := <-respC:
+		if resp.Error != "" {
+			return upstream.StreamResponse{}, errors.New(resp.Error)
+		}
+		return resp.Response, nil
+	case <-ctx.Done():
+		return upstream.StreamResponse{}, ctx.Err()
+	case <-timer.C:
+		return upstream.StreamResponse{}, errors.Errorf("Mattermost server %s did not respond in %s", node, wait)
+	}
+}
+
+// handleWebSocketRequest sends a request forwarded by another server to the
+// app connected to this server, and broadcasts the response back.
+func (p *Proxy) handleWebSocketRequest(ev webSocketRequestEvent) {
+	if ev.To != p.nodeID {
+		return
+	}
+	log := p.conf.NewBaseLogger().With("app_id", ev.AppID)
+
+	resp, err := func() (upstream.StreamResponse, error) {
+		// The server that forwarded the request checked that the app is
+		// enabled.
+		app, err := p.GetInstalledApp(ev.AppID, false)
+		if err != nil {
+			return upstream.StreamResponse{}, err
+		}
+		wsUp, err := p.webSocketUpstream(app)
+		if err != nil {
+			return upstream.StreamResponse{}, err
+		}
+		return wsUp.HandleForwarded(context.Background(), ev.AppID, ev.Request, ev.Timeout)
+	}()
+	if ev.Request.Type == upstream.StreamMessageTypeNotify {
+		if err != nil {
+			log.WithError(err).Debugf("Failed to notify app forwarded from Mattermost server %s.", ev.From)
+		}
+		return
+	}
+
+	respEv := webSocketResponseEvent{
+		ID:       ev.ID,
+		To:       ev.From,
+		Response: resp,
+	}
+	if err != nil {
+		respEv.Error = err.Error()
+	}
+	if err = p.publishClusterEvent(ClusterEventWebSocketResponse, respEv); err != nil {
+		log.WithError(err).Warnf("Failed to return the response to Mattermost server %s.", ev.From)
+	}
+}
+
+// handleWebSocketResponse passes a response from another server to the
+// pending Forward.
+func (p *Proxy) handleWebSocketResponse(ev webSocketResponseEvent) {
+	if ev.To != p.nodeID {
+		return
+	}
+	v, ok := p.webSocketForwards.Load(ev.ID)
+	if !ok {
+		return
+	}
+	select {
+	case v.(chan webSocketResponseEvent) <- ev:
+	default:
+	}
+}
+
+// publishClusterEvent broadcasts v as the data of a plugin cluster event to
+// the other servers in the cluster.
+func (p *Proxy) publishClusterEvent(id string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return p.conf.MattermostAPI().Cluster.PublishPluginEvent(
+		model.PluginClusterEvent{Id: id, Data: data},
+		model.PluginClusterEventSendOptions{SendType: model.PluginClusterEventSendTypeReliable},
+	)
+}
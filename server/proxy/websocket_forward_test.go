@@ -0,0 +1,133 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/mocks/mock_store"
+	"github.com/mattermost/mattermost-plugin-apps/server/store"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upws"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestWebSocketForward(t *testing.T) {
+	app := apps.App{
+		DeployType: apps.DeployWebSocket,
+		Manifest: apps.Manifest{
+			AppID: "app1",
+			Deploy: apps.Deploy{
+				WebSocket: &apps.WebSocket{},
+			},
+		},
+	}
+	ctrl := gomock.NewController(t)
+
+	// Two servers in a cluster, the plugin cluster events of each are
+	// delivered to the other.
+	newNode := func(nodeID string) (*Proxy, *plugintest.API) {
+		testAPI := &plugintest.API{}
+		conf := config.NewTestConfigService(&config.Config{AllowHTTPApps: true}).
+			WithMattermostAPI(pluginapi.NewClient(testAPI, &plugintest.Driver{}))
+		s, err := store.MakeService(conf, nil)
+		require.NoError(t, err)
+		appStore := mock_store.NewMockAppStore(ctrl)
+		appStore.EXPECT().Get(app.AppID).Return(&app, nil).AnyTimes()
+		s.App = appStore
+		p := &Proxy{
+			nodeID: nodeID,
+			conf:   conf,
+			store:  s,
+		}
+		p.upstreams.Store(apps.DeployWebSocket, upws.NewUpstream(utils.NewTestLogger()))
+		return p, testAPI
+	}
+	p1, testAPI1 := newNode("node1")
+	p2, testAPI2 := newNode("node2")
+	deliver := func(from *plugintest.API, to *Proxy) {
+		from.On("PublishPluginClusterEvent", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			go to.OnPluginClusterEvent(args.Get(0).(model.PluginClusterEvent))
+		})
+	}
+	deliver(testAPI1, p2)
+	deliver(testAPI2, p1)
+
+	// The app is connected to node1.
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		require.NoError(t, err)
+		up, _ := p1.webSocketUpstream(&app)
+		up.Serve(app.AppID, ws)
+	}))
+	defer server.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	go func() {
+		for {
+			req := upstream.StreamRequest{}
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+			data, _ := json.Marshal(apps.NewTextResponse("called %s", req.CallRequest.Path))
+			_ = ws.WriteJSON(upstream.StreamResponse{ID: req.ID, CallResponse: data})
+		}
+	}()
+	up1, _ := p1.webSocketUpstream(&app)
+	require.Eventually(t, func() bool {
+		s := up1.Status(app.AppID)
+		return s != nil && s.Connected
+	}, time.Second, 10*time.Millisecond)
+
+	ctx := context.Background()
+	f := webSocketForwarder{p2}
+
+	t.Run("call", func(t *testing.T) {
+		resp, err := f.Forward(ctx, "node1", app.AppID, upstream.StreamRequest{
+			Type:        upstream.StreamMessageTypeCall,
+			CallRequest: &apps.CallRequest{Call: *apps.NewCall("/hello")},
+		}, time.Second)
+		require.NoError(t, err)
+		cresp := apps.CallResponse{}
+		require.NoError(t, json.Unmarshal(resp.CallResponse, &cresp))
+		require.Equal(t, "called /hello", cresp.Text)
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		_, err := webSocketForwarder{p1}.Forward(ctx, "node2", app.AppID, upstream.StreamRequest{
+			Type:        upstream.StreamMessageTypeCall,
+			CallRequest: &apps.CallRequest{Call: *apps.NewCall("/hello")},
+		}, time.Second)
+		require.EqualError(t, err, "app app1 is not connected to this Mattermost server")
+	})
+
+	t.Run("ignored by the other servers", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err := f.Forward(ctx, "node3", app.AppID, upstream.StreamRequest{
+			Type:        upstream.StreamMessageTypeCall,
+			CallRequest: &apps.CallRequest{Call: *apps.NewCall("/hello")},
+		}, time.Second)
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+}
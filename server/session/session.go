@@ -112,6 +112,10 @@ func (s *service) createSession(r *incoming.Request, appID apps.AppID, userID st
 	}
 
 	r.Log.Debugw("created new access token", "app_id", appID, "user_id", userID, "session_id", session.Id, "token", utils.LastN(session.Token, 3))
+	s.store.Audit.Record(r, apps.AuditSessionCreated, appID, map[string]string{
+		"session_user_id": userID,
+		"session_id":      session.Id,
+	})
 
 	return session, nil
 }
@@ -26,12 +26,15 @@ func setUpBasics(ctrl *gomock.Controller) (session.Service,
 	*incoming.Request,
 	*mock_store.MockSessionStore,
 	*mock_store.MockAppStore,
+	*mock_store.MockAuditStore,
 	*plugintest.API) {
 	appStore := mock_store.NewMockAppStore(ctrl)
 	sessionStore := mock_store.NewMockSessionStore(ctrl)
+	auditStore := mock_store.NewMockAuditStore(ctrl)
 	mockStore := &store.Service{
 		App:     appStore,
 		Session: sessionStore,
+		Audit:   auditStore,
 	}
 
 	conf, api := config.NewTestService(nil)
@@ -39,7 +42,7 @@ func setUpBasics(ctrl *gomock.Controller) (session.Service,
 
 	sessionService := session.NewService(conf.MattermostAPI(), mockStore)
 
-	return sessionService, r, sessionStore, appStore, api
+	return sessionService, r, sessionStore, appStore, auditStore, api
 }
 
 func TestGetOrCreate(t *testing.T) {
@@ -48,7 +51,7 @@ func TestGetOrCreate(t *testing.T) {
 	t.Run("Valid, long lasting session found", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 
-		sessionService, r, sessionStore, _, _ := setUpBasics(ctrl)
+		sessionService, r, sessionStore, _, _, _ := setUpBasics(ctrl)
 
 		appID := apps.AppID("foo")
 		userID := model.NewId()
@@ -73,7 +76,7 @@ func TestGetOrCreate(t *testing.T) {
 	t.Run("Valid, short session found", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 
-		sessionService, r, sessionStore, _, api := setUpBasics(ctrl)
+		sessionService, r, sessionStore, _, _, api := setUpBasics(ctrl)
 
 		appID := apps.AppID("foo")
 		userID := model.NewId()
@@ -108,7 +111,7 @@ func TestGetOrCreate(t *testing.T) {
 	t.Run("No session found", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 
-		sessionService, r, sessionStore, appStore, api := setUpBasics(ctrl)
+		sessionService, r, sessionStore, appStore, auditStore, api := setUpBasics(ctrl)
 
 		appID := apps.AppID("foo")
 		userID := model.NewId()
@@ -152,6 +155,7 @@ func TestGetOrCreate(t *testing.T) {
 		appStore.EXPECT().Get(appID).Times(1).Return(&apps.App{
 			MattermostOAuth2: oAuthApp,
 		}, nil)
+		auditStore.EXPECT().Record(gomock.Any(), apps.AuditSessionCreated, appID, gomock.Any()).Times(1)
 
 		r = r.WithDestination(appID)
 		rSession, err := sessionService.GetOrCreate(r, userID)
@@ -165,7 +169,7 @@ func TestListForUser(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 
-	sessionService, r, sessionStore, _, _ := setUpBasics(ctrl)
+	sessionService, r, sessionStore, _, _, _ := setUpBasics(ctrl)
 
 	userID := model.NewId()
 	sessionStore.EXPECT().ListForUser(r, userID).Times(1).Return([]*model.Session{}, nil)
@@ -180,7 +184,7 @@ func TestRevokeSessionsForApp(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
 
-	sessionService, r, sessionStore, _, api := setUpBasics(ctrl)
+	sessionService, r, sessionStore, _, _, api := setUpBasics(ctrl)
 
 	appID := apps.AppID("foo")
 	userID1 := model.NewId()
@@ -0,0 +1,296 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// DefaultAuditRetentionDays is how long the audit log records are kept, unless
+// configured otherwise with config.StoredConfig.AuditRetentionDays.
+const DefaultAuditRetentionDays = 90
+
+const (
+	// auditBucket is the time span of the records stored under the same key
+	// prefix. The records of a bucket are numbered in sequence, so that the
+	// queries read the keys of the buckets in their time range, instead of
+	// listing all the keys in the KV store.
+	auditBucket = time.Hour
+
+	// maxAuditBucketRecords limits the number of records in a bucket.
+	maxAuditBucketRecords = 1000000
+
+	// maxAuditQueryScan limits the number of keys a query reads.
+	maxAuditQueryScan = 10000
+)
+
+// AuditStore is the append-only audit log of administrative and privileged
+// actions. Each record is stored under its own key, made of its hourly bucket
+// and its sequence number in the bucket, so appending never needs to update an
+// existing value. The records of a bucket expire together, after the
+// configured retention period.
+type AuditStore interface {
+	// Record appends a record of the action to the log, attributed to the
+	// acting user (and source plugin) of the request. Failures are logged, and
+	// never fail the action itself.
+	Record(r *incoming.Request, action apps.AuditAction, appID apps.AppID, details map[string]string)
+
+	// RecordOnce is like Record, but records the action at most once per
+	// period for the app and the acting user, for frequent actions such as
+	// the expansions of the acting user's token.
+	RecordOnce(r *incoming.Request, action apps.AuditAction, appID apps.AppID, period time.Duration)
+
+	// Append stores the record, setting its ID and Time if they are not set.
+	Append(*apps.AuditRecord) error
+
+	// Query returns the records matching q, most recent first. At most
+	// maxAuditQueryScan keys are read, past that the records found so far
+	// are returned.
+	Query(context.Context, apps.AuditQuery) ([]apps.AuditRecord, error)
+}
+
+type auditStore struct {
+	*Service
+
+	now func() time.Time
+
+	mutex sync.Mutex
+
+	// recorded keeps the times of the recent RecordOnce records made by this
+	// server, so that most of them are skipped without accessing the KV store.
+	recorded   map[string]time.Time
+	lastPruned time.Time
+
+	// nextBucket and nextSeq are the bucket and the sequence number of the
+	// next record appended by this server, unless another server used it.
+	nextBucket time.Time
+	nextSeq    int
+}
+
+var _ AuditStore = (*auditStore)(nil)
+
+// auditKey is KVAuditPrefix, followed by the start of the record's bucket in
+// seconds, and the record's sequence number in the bucket.
+func auditKey(bucket time.Time, seq int) string {
+	return fmt.Sprintf("%s%d.%d", KVAuditPrefix, bucket.Unix(), seq)
+}
+
+func (s *auditStore) timeNow() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func (s *auditStore) retention() time.Duration {
+	days := s.conf.Get().AuditRetentionDays
+	if days <= 0 {
+		days = DefaultAuditRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func (s *auditStore) Record(r *incoming.Request, action apps.AuditAction, appID apps.AppID, details map[string]string) {
+	rec := apps.AuditRecord{
+		Action:   action,
+		AppID:    appID,
+		UserID:   r.ActingUserID(),
+		PluginID: r.SourcePluginID(),
+		Details:  details,
+	}
+	if err := s.Append(&rec); err != nil {
+		r.Log.WithError(err).Errorw("failed to record audit log entry", "action", action, "app_id", appID)
+	}
+}
+
+func (s *auditStore) RecordOnce(r *incoming.Request, action apps.AuditAction, appID apps.AppID, period time.Duration) {
+	key := fmt.Sprintf("%s%s.%s.%s", KVAuditOncePrefix, action, appID, r.ActingUserID())
+	now := s.timeNow()
+
+	s.mutex.Lock()
+	if s.recorded == nil || now.Sub(s.lastPruned) > period {
+		s.recorded = map[string]time.Time{}
+		s.lastPruned = now
+	}
+	if t, ok := s.recorded[key]; ok && now.Sub(t) < period {
+		s.mutex.Unlock()
+		return
+	}
+	s.recorded[key] = now
+	s.mutex.Unlock()
+
+	// Another server may have recorded the action already.
+	set, err := s.conf.MattermostAPI().KV.Set(key, now.UnixMilli(), pluginapi.SetAtomic(nil), pluginapi.SetExpiry(period))
+	if err != nil {
+		r.Log.WithError(err).Errorw("failed to record audit log entry", "action", action, "app_id", appID)
+		return
+	}
+	if set {
+		s.Record(r, action, appID, nil)
+	}
+}
+
+func (s *auditStore) Append(rec *apps.AuditRecord) error {
+	if rec.Action == "" {
+		return utils.NewInvalidError("audit record must have an action")
+	}
+	if rec.ID == "" {
+		rec.ID = model.NewId()
+	}
+	now := s.timeNow()
+	if rec.Time.IsZero() {
+		rec.Time = now
+	}
+	rec.Time = rec.Time.UTC()
+	bucket := rec.Time.Truncate(auditBucket)
+	mm := s.conf.MattermostAPI()
+
+	// The records of a bucket expire together, so that a bucket never has
+	// gaps in its sequence.
+	ttl := bucket.Add(auditBucket + s.retention()).Sub(now)
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+
+	seq, err := s.firstSeq(bucket)
+	if err != nil {
+		return errors.Wrap(err, "failed to store audit record")
+	}
+	for ; ; seq++ {
+		if seq >= maxAuditBucketRecords {
+			return errors.Errorf("failed to store audit record: more than %v records in %s", maxAuditBucketRecords, bucket)
+		}
+		// An atomic set with a nil old value never overwrites an existing
+		// record. If another server took seq, the next one is tried.
+		set, err := mm.KV.Set(auditKey(bucket, seq), rec, pluginapi.SetAtomic(nil), pluginapi.SetExpiry(ttl))
+		if err != nil {
+			return errors.Wrap(err, "failed to store audit record")
+		}
+		if set {
+			break
+		}
+	}
+
+	s.mutex.Lock()
+	if !bucket.Before(s.nextBucket) {
+		s.nextBucket, s.nextSeq = bucket, seq+1
+	}
+	s.mutex.Unlock()
+	return nil
+}
+
+// firstSeq returns the sequence number to try first for a new record in the
+// bucket: the next one after the last record appended by this server, or the
+// first free one, found with an exponential, then a binary search.
+func (s *auditStore) firstSeq(bucket time.Time) (int, error) {
+	s.mutex.Lock()
+	if bucket.Equal(s.nextBucket) {
+		defer s.mutex.Unlock()
+		return s.nextSeq, nil
+	}
+	s.mutex.Unlock()
+
+	mm := s.conf.MattermostAPI()
+	exists := func(seq int) (bool, error) {
+		var data []byte
+		if err := mm.KV.Get(auditKey(bucket, seq), &data); err != nil {
+			return false, err
+		}
+		return len(data) > 0, nil
+	}
+
+	// lo is taken, hi is free.
+	lo, hi := -1, 0
+	for {
+		taken, err := exists(hi)
+		if err != nil {
+			return 0, err
+		}
+		if !taken || hi >= maxAuditBucketRecords {
+			break
+		}
+		lo, hi = hi, 2*hi+1
+	}
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		taken, err := exists(mid)
+		if err != nil {
+			return 0, err
+		}
+		if taken {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi, nil
+}
+
+// Query reads the records of the hourly buckets in the query's time range,
+// within the retention period, most recent first, until the limit is reached.
+func (s *auditStore) Query(ctx context.Context, q apps.AuditQuery) ([]apps.AuditRecord, error) {
+	mm := s.conf.MattermostAPI()
+	now := s.timeNow()
+	since := now.Add(-s.retention())
+	if q.Since.After(since) {
+		since = q.Since
+	}
+	until := now
+	if !q.Until.IsZero() && q.Until.Before(until) {
+		until = q.Until
+	}
+
+	limit := q.EffectiveLimit()
+	out := []apps.AuditRecord{}
+	scanned := 0
+	for bucket := until.Truncate(auditBucket); !bucket.Before(since.Truncate(auditBucket)); bucket = bucket.Add(-auditBucket) {
+		records := []apps.AuditRecord{}
+		for seq := 0; ; seq++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if scanned >= maxAuditQueryScan {
+				return out, nil
+			}
+			scanned++
+
+			rec := apps.AuditRecord{}
+			if err := mm.KV.Get(auditKey(bucket, seq), &rec); err != nil {
+				return nil, errors.Wrapf(err, "failed to load audit record %s", auditKey(bucket, seq))
+			}
+			// The end of the bucket, or an expired bucket.
+			if rec.ID == "" {
+				break
+			}
+			if rec.Time.Before(since) || (!q.Until.IsZero() && !rec.Time.Before(q.Until)) || !q.Matches(rec) {
+				continue
+			}
+			records = append(records, rec)
+		}
+
+		sort.SliceStable(records, func(i, j int) bool {
+			return records[i].Time.After(records[j].Time)
+		})
+		for _, rec := range records {
+			out = append(out, rec)
+			if len(out) >= limit {
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/incoming"
+)
+
+// mockKV backs the KV methods of the plugin API with a map.
+func mockKV(api *plugintest.API) {
+	var mu sync.Mutex
+	kv := map[string][]byte{}
+
+	api.On("KVSetWithOptions", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(key string, value []byte, opts model.PluginKVSetOptions) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, exists := kv[key]; exists && opts.Atomic && opts.OldValue == nil {
+				return false
+			}
+			kv[key] = value
+			return true
+		}, nil)
+	api.On("KVGet", mock.Anything).Return(
+		func(key string) []byte {
+			mu.Lock()
+			defer mu.Unlock()
+			return kv[key]
+		}, nil)
+	api.On("KVList", mock.Anything, mock.Anything).Return(
+		func(page, perPage int) []string {
+			mu.Lock()
+			defer mu.Unlock()
+			keys := []string{}
+			for k := range kv {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			start := page * perPage
+			if start >= len(keys) {
+				return []string{}
+			}
+			end := start + perPage
+			if end > len(keys) {
+				end = len(keys)
+			}
+			return keys[start:end]
+		}, nil)
+}
+
+func TestAuditKey(t *testing.T) {
+	bucket := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	key := auditKey(bucket, 12)
+	require.Equal(t, "audit.1688212800.12", key)
+	require.LessOrEqual(t, len(auditKey(bucket, maxAuditBucketRecords)), model.KeyValueKeyMaxRunes)
+}
+
+func TestAudit(t *testing.T) {
+	conf, api := config.NewTestService(nil)
+	mockKV(api)
+	// Unrelated keys must be ignored.
+	_, err := conf.MattermostAPI().KV.Set(KVInstalledAppPrefix+"app1", apps.App{})
+	require.NoError(t, err)
+
+	start := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	s := &auditStore{
+		Service: &Service{conf: conf},
+		now: func() time.Time {
+			now = now.Add(time.Minute)
+			return now
+		},
+	}
+
+	r := incoming.NewRequest(conf, nil).WithActingUserID("user1")
+	s.Record(r, apps.AuditAppInstalled, "app1", map[string]string{"deploy_type": "http"})
+	s.Record(r, apps.AuditPermissionsGranted, "app1", map[string]string{"permissions": "act_as_bot"})
+	s.Record(r.WithActingUserID("user2"), apps.AuditAppInstalled, "app2", nil)
+	s.Record(r.WithSourcePluginID("com.example.plugin"), apps.AuditAppDisabled, "app1", nil)
+
+	err = s.Append(&apps.AuditRecord{})
+	require.Error(t, err)
+
+	// Records in an earlier hour, and past the retention period.
+	require.NoError(t, s.Append(&apps.AuditRecord{Action: apps.AuditAppEnabled, AppID: "app2", Time: start.Add(-3 * time.Hour)}))
+	require.NoError(t, s.Append(&apps.AuditRecord{Action: apps.AuditAppEnabled, AppID: "app2", Time: start.Add(-(DefaultAuditRetentionDays + 1) * 24 * time.Hour)}))
+
+	for name, tc := range map[string]struct {
+		q        apps.AuditQuery
+		expected []apps.AuditAction
+	}{
+		"all": {
+			expected: []apps.AuditAction{apps.AuditAppDisabled, apps.AuditAppInstalled, apps.AuditPermissionsGranted, apps.AuditAppInstalled, apps.AuditAppEnabled},
+		},
+		"by app": {
+			q:        apps.AuditQuery{AppID: "app1"},
+			expected: []apps.AuditAction{apps.AuditAppDisabled, apps.AuditPermissionsGranted, apps.AuditAppInstalled},
+		},
+		"by app, earlier hour": {
+			q:        apps.AuditQuery{AppID: "app2", Until: start},
+			expected: []apps.AuditAction{apps.AuditAppEnabled},
+		},
+		"by user": {
+			q:        apps.AuditQuery{UserID: "user2"},
+			expected: []apps.AuditAction{apps.AuditAppInstalled},
+		},
+		"by action": {
+			q:        apps.AuditQuery{Action: apps.AuditAppInstalled},
+			expected: []apps.AuditAction{apps.AuditAppInstalled, apps.AuditAppInstalled},
+		},
+		"by time": {
+			q:        apps.AuditQuery{Since: start.Add(2 * time.Minute), Until: start.Add(4 * time.Minute)},
+			expected: []apps.AuditAction{apps.AuditAppInstalled, apps.AuditPermissionsGranted},
+		},
+		"limit": {
+			q:        apps.AuditQuery{Limit: 1},
+			expected: []apps.AuditAction{apps.AuditAppDisabled},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			records, err := s.Query(context.Background(), tc.q)
+			require.NoError(t, err)
+			actions := []apps.AuditAction{}
+			for _, rec := range records {
+				actions = append(actions, rec.Action)
+			}
+			require.Equal(t, tc.expected, actions)
+		})
+	}
+
+	records, err := s.Query(context.Background(), apps.AuditQuery{Action: apps.AuditAppDisabled})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	rec := records[0]
+	require.NotEmpty(t, rec.ID)
+	require.Equal(t, start.Add(4*time.Minute), rec.Time)
+	require.Equal(t, apps.AppID("app1"), rec.AppID)
+	require.Equal(t, "user1", rec.UserID)
+	require.Equal(t, "com.example.plugin", rec.PluginID)
+
+	// The queries only read their own keys.
+	api.AssertNotCalled(t, "KVList", mock.Anything, mock.Anything)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.Query(ctx, apps.AuditQuery{})
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestAuditSequence(t *testing.T) {
+	conf, api := config.NewTestService(nil)
+	mockKV(api)
+	now := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	newStore := func() *auditStore {
+		return &auditStore{
+			Service: &Service{conf: conf},
+			now:     func() time.Time { return now },
+		}
+	}
+
+	// Two servers appending to the same bucket.
+	s1, s2 := newStore(), newStore()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s1.Append(&apps.AuditRecord{Action: apps.AuditAppEnabled, AppID: apps.AppID(fmt.Sprintf("app%v", i))}))
+		require.NoError(t, s2.Append(&apps.AuditRecord{Action: apps.AuditAppDisabled, AppID: apps.AppID(fmt.Sprintf("app%v", i))}))
+	}
+	// A new server finds the first free sequence number.
+	seq, err := newStore().firstSeq(now.Truncate(auditBucket))
+	require.NoError(t, err)
+	require.Equal(t, 20, seq)
+
+	records, err := s1.Query(context.Background(), apps.AuditQuery{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, records, 20)
+}
+
+func TestAuditRecordOnce(t *testing.T) {
+	conf, api := config.NewTestService(nil)
+	mockKV(api)
+
+	now := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	newStore := func() *auditStore {
+		return &auditStore{
+			Service: &Service{conf: conf},
+			now: func() time.Time {
+				now = now.Add(time.Second)
+				return now
+			},
+		}
+	}
+	count := func(s *auditStore) int {
+		records, err := s.Query(context.Background(), apps.AuditQuery{Action: apps.AuditActingUserTokenExpanded})
+		require.NoError(t, err)
+		return len(records)
+	}
+
+	s := newStore()
+	r := incoming.NewRequest(conf, nil).WithActingUserID("user1")
+	for i := 0; i < 3; i++ {
+		s.RecordOnce(r, apps.AuditActingUserTokenExpanded, "app1", time.Hour)
+	}
+	require.Equal(t, 1, count(s))
+
+	s.RecordOnce(r, apps.AuditActingUserTokenExpanded, "app2", time.Hour)
+	s.RecordOnce(r.WithActingUserID("user2"), apps.AuditActingUserTokenExpanded, "app1", time.Hour)
+	require.Equal(t, 3, count(s))
+
+	// Another server sees the action recorded already.
+	other := newStore()
+	other.RecordOnce(r, apps.AuditActingUserTokenExpanded, "app1", time.Hour)
+	require.Equal(t, 3, count(other))
+}
@@ -110,7 +110,15 @@ func (s *Service) GetDebugKVInfo(log utils.Logger) (*KVDebugInfo, error) {
 			case strings.HasPrefix(key, KVLocalManifestPrefix):
 				info.ManifestCount++
 
-			case key == "mmi_botid":
+			case key == "mmi_botid",
+				key == KVSigningKeysKey,
+				strings.HasPrefix(key, KVWebhookNoncePrefix),
+				strings.HasPrefix(key, KVWasmModulePrefix),
+				strings.HasPrefix(key, KVWasmChunkPrefix),
+				strings.HasPrefix(key, KVAuditPrefix),
+				strings.HasPrefix(key, KVAuditOncePrefix),
+				strings.HasPrefix(key, KVWebSocketStatusPrefix),
+				strings.HasPrefix(key, KVTLSKeyPrefix):
 				info.Other++
 
 			case strings.HasPrefix(key, KVDebugPrefix):
@@ -55,7 +55,7 @@ var _ ManifestStore = (*manifestStore)(nil)
 
 func (s *Service) makeManifestStore(conf config.Config) (*manifestStore, error) {
 	log := s.conf.NewBaseLogger().With("purpose", "Manifest store")
-	awsClient, err := upaws.MakeClient(conf.AWSAccessKey, conf.AWSSecretKey, conf.AWSRegion, log)
+	awsClient, err := upaws.MakeClient(conf.AWSAccessKey, conf.AWSSecretKey, conf.AWSRegion, conf.AWSEndpoints, log)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize AWS access")
 	}
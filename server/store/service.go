@@ -59,6 +59,37 @@ const (
 
 	KVTokenPrefix = ".t"
 
+	// KVWebhookNoncePrefix is used to store the recently seen remote webhook
+	// nonces, for replay protection.
+	KVWebhookNoncePrefix = ".w"
+
+	// KVWasmModulePrefix is used to store the WebAssembly modules of wasm
+	// apps.
+	KVWasmModulePrefix = "wasm."
+
+	// KVWasmChunkPrefix is used to store the chunks of the WebAssembly
+	// modules, keyed by the app ID and the module's hash.
+	KVWasmChunkPrefix = "wasmc."
+
+	// KVWebSocketStatusPrefix is used to store the connection status of
+	// WebSocket apps, shared across the cluster.
+	KVWebSocketStatusPrefix = "ws."
+
+	// KVTLSKeyPrefix is used to store the private keys of the apps' TLS
+	// client certificates.
+	KVTLSKeyPrefix = "tlskey."
+
+	// KVSigningKeysKey is used to store the keys that the outgoing JWTs are
+	// signed with.
+	KVSigningKeysKey = "jwt_signing_keys"
+
+	// KVAuditPrefix is used to store the audit log records, one per key.
+	KVAuditPrefix = "audit."
+
+	// KVAuditOncePrefix is used to store the markers of the recently recorded
+	// frequent actions, see AuditStore.RecordOnce.
+	KVAuditOncePrefix = "audito."
+
 	KVDebugPrefix = ".debug."
 
 	// KVCallOnceKey and KVClusterMutexKey are used for invoking App Calls once,
@@ -78,6 +109,12 @@ type Service struct {
 	AppKV        AppKVStore
 	OAuth2       OAuth2Store
 	Session      SessionStore
+	Webhook      WebhookStore
+	Wasm         WasmStore
+	SigningKeys  SigningKeyStore
+	Audit        AuditStore
+	WebSocket    WebSocketStatusStore
+	TLSKey       TLSKeyStore
 
 	conf    config.Service
 	httpOut httpout.Service
@@ -92,6 +129,12 @@ func MakeService(confService config.Service, httpOut httpout.Service) (*Service,
 	s.OAuth2 = &oauth2Store{Service: s}
 	s.Subscription = &subscriptionStore{Service: s}
 	s.Session = &sessionStore{Service: s}
+	s.Webhook = &webhookStore{Service: s}
+	s.Wasm = &wasmStore{Service: s}
+	s.SigningKeys = &signingKeyStore{Service: s}
+	s.Audit = &auditStore{Service: s}
+	s.WebSocket = &webSocketStatusStore{Service: s}
+	s.TLSKey = &tlsKeyStore{Service: s}
 
 	conf := confService.Get()
 	var err error
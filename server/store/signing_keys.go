@@ -0,0 +1,79 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// SigningKey is a private key that the Mattermost server signs the outgoing
+// JWTs with.
+type SigningKey struct {
+	ID        string `json:"kid"`
+	Algorithm string `json:"alg"`
+
+	// PrivateKey is PKCS #8, ASN.1 DER encoded.
+	PrivateKey []byte `json:"private_key"`
+
+	CreatedAt int64 `json:"created_at"`
+
+	// RetiredAt is set when the key is replaced by a newer one. Retired keys
+	// are no longer used for signing, but remain published until they expire.
+	RetiredAt int64 `json:"retired_at,omitempty"`
+}
+
+// SigningKeys are the JWT signing keys of the Mattermost server, shared by all
+// the servers in the cluster.
+type SigningKeys struct {
+	Keys []SigningKey `json:"keys"`
+}
+
+// SigningKeyStore keeps the JWT signing keys.
+type SigningKeyStore interface {
+	// Get returns the stored keys, empty if there are none.
+	Get() (*SigningKeys, error)
+
+	// Update atomically applies update to the stored keys, and returns the
+	// result. update may be invoked multiple times if there are concurrent
+	// updates.
+	Update(update func(*SigningKeys) error) (*SigningKeys, error)
+}
+
+type signingKeyStore struct {
+	*Service
+}
+
+var _ SigningKeyStore = (*signingKeyStore)(nil)
+
+func (s *signingKeyStore) Get() (*SigningKeys, error) {
+	keys := SigningKeys{}
+	err := s.conf.MattermostAPI().KV.Get(KVSigningKeysKey, &keys)
+	if err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+func (s *signingKeyStore) Update(update func(*SigningKeys) error) (*SigningKeys, error) {
+	var updated *SigningKeys
+	err := s.conf.MattermostAPI().KV.SetAtomicWithRetries(KVSigningKeysKey, func(oldValue []byte) (interface{}, error) {
+		keys := SigningKeys{}
+		if len(oldValue) > 0 {
+			if err := json.Unmarshal(oldValue, &keys); err != nil {
+				return nil, err
+			}
+		}
+		if err := update(&keys); err != nil {
+			return nil, err
+		}
+		updated = &keys
+		return keys, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update JWT signing keys")
+	}
+	return updated, nil
+}
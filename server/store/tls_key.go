@@ -0,0 +1,55 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// TLSKeyStore keeps the private keys of the apps' TLS client certificates,
+// see apps.TLSConfig. They are stored separately from the App records, so that
+// they are never returned with the apps.
+type TLSKeyStore interface {
+	// Get returns the PEM-encoded client key of the app, or utils.ErrNotFound.
+	Get(apps.AppID) (string, error)
+	Save(apps.AppID, string) error
+	Delete(apps.AppID) error
+}
+
+type tlsKeyStore struct {
+	*Service
+}
+
+var _ TLSKeyStore = (*tlsKeyStore)(nil)
+
+func (s *tlsKeyStore) Get(appID apps.AppID) (string, error) {
+	var key string
+	err := s.conf.MattermostAPI().KV.Get(KVTLSKeyPrefix+string(appID), &key)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", utils.NewNotFoundError("no TLS client key for %s", appID)
+	}
+	return key, nil
+}
+
+func (s *tlsKeyStore) Save(appID apps.AppID, key string) error {
+	_, err := s.conf.MattermostAPI().KV.Set(KVTLSKeyPrefix+string(appID), key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store the TLS client key of %s", appID)
+	}
+	return nil
+}
+
+func (s *tlsKeyStore) Delete(appID apps.AppID) error {
+	err := s.conf.MattermostAPI().KV.Delete(KVTLSKeyPrefix + string(appID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete the TLS client key of %s", appID)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// MaxWasmModuleSize is the largest WebAssembly module that can be stored.
+const MaxWasmModuleSize = 8 * 1024 * 1024
+
+// WasmModuleChunkSize is the size of the KV records the modules are split
+// into, so that no single record is too large for the database.
+const WasmModuleChunkSize = 512 * 1024
+
+// WasmModule is a WebAssembly module uploaded for a version of an App.
+type WasmModule struct {
+	AppID   apps.AppID      `json:"app_id"`
+	Version apps.AppVersion `json:"version"`
+	SHA256  string          `json:"sha256"`
+	Size    int             `json:"size,omitempty"`
+	Chunks  int             `json:"chunks,omitempty"`
+
+	// Data is the content of the module. It is not set by GetInfo. It is
+	// stored inline only by the older versions of the plugin, the newer ones
+	// store it in Chunks separate records.
+	Data []byte `json:"data,omitempty"`
+}
+
+// WasmStore keeps the WebAssembly modules of the wasm Apps. Only the module of
+// the most recently uploaded version is kept for an App.
+type WasmStore interface {
+	// Get returns the module of the app's version, or utils.ErrNotFound.
+	Get(apps.AppID, apps.AppVersion) (*WasmModule, error)
+	// GetInfo returns the module of the app's version without its Data, or
+	// utils.ErrNotFound. It reads a single small record, and can be used to
+	// check if the module has changed.
+	GetInfo(apps.AppID, apps.AppVersion) (*WasmModule, error)
+	Save(apps.AppID, apps.AppVersion, []byte) (*WasmModule, error)
+}
+
+type wasmStore struct {
+	*Service
+}
+
+var _ WasmStore = (*wasmStore)(nil)
+
+func (s *wasmStore) GetInfo(appID apps.AppID, version apps.AppVersion) (*WasmModule, error) {
+	m := WasmModule{}
+	err := s.conf.MattermostAPI().KV.Get(KVWasmModulePrefix+string(appID), &m)
+	if err != nil {
+		return nil, err
+	}
+	if m.AppID == "" {
+		return nil, utils.NewNotFoundError("no WebAssembly module for %s", appID)
+	}
+	if m.Version != version {
+		return nil, utils.NewNotFoundError("no WebAssembly module for %s version %s, have %s", appID, version, m.Version)
+	}
+	return &m, nil
+}
+
+func (s *wasmStore) Get(appID apps.AppID, version apps.AppVersion) (*WasmModule, error) {
+	m, err := s.GetInfo(appID, version)
+	if err != nil {
+		return nil, err
+	}
+	if m.Chunks == 0 {
+		return m, nil
+	}
+
+	data := make([]byte, 0, m.Size)
+	for i := 0; i < m.Chunks; i++ {
+		var chunk []byte
+		err = s.conf.MattermostAPI().KV.Get(wasmChunkKey(appID, m.SHA256, i), &chunk)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load WebAssembly module for %s", appID)
+		}
+		if chunk == nil {
+			return nil, utils.NewNotFoundError("WebAssembly module for %s is missing chunk %v", appID, i)
+		}
+		data = append(data, chunk...)
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != m.SHA256 {
+		return nil, errors.Errorf("WebAssembly module for %s is corrupted, sha256 mismatch", appID)
+	}
+	m.Data = data
+	return m, nil
+}
+
+// Save stores the chunks of the module before its info record, so that the
+// module is never read partially. The chunks of the previous module are
+// removed after it is replaced.
+func (s *wasmStore) Save(appID apps.AppID, version apps.AppVersion, data []byte) (*WasmModule, error) {
+	if len(data) > MaxWasmModuleSize {
+		return nil, utils.NewInvalidError("WebAssembly module is too large: %v bytes, max %v", len(data), MaxWasmModuleSize)
+	}
+	mm := s.conf.MattermostAPI()
+	prev := WasmModule{}
+	_ = mm.KV.Get(KVWasmModulePrefix+string(appID), &prev)
+
+	sum := sha256.Sum256(data)
+	m := WasmModule{
+		AppID:   appID,
+		Version: version,
+		SHA256:  hex.EncodeToString(sum[:]),
+		Size:    len(data),
+	}
+	for start := 0; start < len(data); start += WasmModuleChunkSize {
+		end := start + WasmModuleChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err := mm.KV.Set(wasmChunkKey(appID, m.SHA256, m.Chunks), data[start:end])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to store WebAssembly module for %s", appID)
+		}
+		m.Chunks++
+	}
+
+	_, err := mm.KV.Set(KVWasmModulePrefix+string(appID), m)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to store WebAssembly module for %s", appID)
+	}
+
+	if prev.SHA256 != m.SHA256 {
+		for i := 0; i < prev.Chunks; i++ {
+			_ = mm.KV.Delete(wasmChunkKey(appID, prev.SHA256, i))
+		}
+	}
+	return &m, nil
+}
+
+func wasmChunkKey(appID apps.AppID, sha256 string, i int) string {
+	if len(sha256) > 16 {
+		sha256 = sha256[:16]
+	}
+	return fmt.Sprintf("%s%s.%s.%v", KVWasmChunkPrefix, appID, sha256, i)
+}
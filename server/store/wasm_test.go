@@ -0,0 +1,55 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestWasmStore(t *testing.T) {
+	conf, api := config.NewTestService(nil)
+	mockKV(api)
+	s := &wasmStore{Service: &Service{conf: conf}}
+
+	v1 := bytes.Repeat([]byte("1"), 2*WasmModuleChunkSize+10)
+	m, err := s.Save("app1", "v1.0.0", v1)
+	require.NoError(t, err)
+	require.Equal(t, 3, m.Chunks)
+	require.Equal(t, len(v1), m.Size)
+
+	info, err := s.GetInfo("app1", "v1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, m.SHA256, info.SHA256)
+	require.Nil(t, info.Data)
+
+	loaded, err := s.Get("app1", "v1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, v1, loaded.Data)
+
+	_, err = s.Get("app1", "v2.0.0")
+	require.ErrorIs(t, err, utils.ErrNotFound)
+
+	// Replacing the module removes the chunks of the previous one.
+	v2 := []byte("2")
+	m2, err := s.Save("app1", "v2.0.0", v2)
+	require.NoError(t, err)
+	require.Equal(t, 1, m2.Chunks)
+	loaded, err = s.Get("app1", "v2.0.0")
+	require.NoError(t, err)
+	require.Equal(t, v2, loaded.Data)
+	for i := 0; i < m.Chunks; i++ {
+		var chunk []byte
+		require.NoError(t, conf.MattermostAPI().KV.Get(wasmChunkKey("app1", m.SHA256, i), &chunk))
+		require.Nil(t, chunk)
+	}
+
+	_, err = s.Save("app1", "v3.0.0", make([]byte, MaxWasmModuleSize+1))
+	require.ErrorIs(t, err, utils.ErrInvalid)
+}
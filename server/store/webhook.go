@@ -0,0 +1,56 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"encoding/hex"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// WebhookStore keeps short-lived, cluster-wide state used to process incoming
+// remote webhooks.
+type WebhookStore interface {
+	// RememberNonce records that a webhook request identified by nonce has
+	// been received by the app. It returns utils.ErrAlreadyExists if the nonce
+	// has already been recorded within ttl.
+	RememberNonce(appID apps.AppID, nonce string, ttl time.Duration) error
+}
+
+type webhookStore struct {
+	*Service
+}
+
+var _ WebhookStore = (*webhookStore)(nil)
+
+func webhookNonceKey(appID apps.AppID, nonce string) string {
+	h := make([]byte, 16)
+	sha3.ShakeSum128(h, []byte(nonce))
+	return KVWebhookNoncePrefix + string(appID) + "." + hex.EncodeToString(h)
+}
+
+func (s *webhookStore) RememberNonce(appID apps.AppID, nonce string, ttl time.Duration) error {
+	if appID == "" || nonce == "" {
+		return utils.NewInvalidError("app ID and nonce must be provided")
+	}
+
+	// An atomic set with a nil old value only succeeds if the key does not
+	// exist (or has expired), which makes the check safe across the cluster.
+	set, err := s.conf.MattermostAPI().KV.Set(webhookNonceKey(appID, nonce), true,
+		pluginapi.SetAtomic(nil),
+		pluginapi.SetExpiry(ttl))
+	if err != nil {
+		return err
+	}
+	if !set {
+		return utils.NewAlreadyExistsError("webhook request has already been received")
+	}
+	return nil
+}
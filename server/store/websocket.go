@@ -0,0 +1,48 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package store
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/pluginapi"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/upws"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// WebSocketStatusStore shares the status of the WebSocket apps' connections
+// across the servers in the cluster, see upws.StatusStore.
+type WebSocketStatusStore interface {
+	upws.StatusStore
+}
+
+type webSocketStatusStore struct {
+	*Service
+}
+
+var _ WebSocketStatusStore = (*webSocketStatusStore)(nil)
+
+func (s *webSocketStatusStore) Get(appID apps.AppID) (*upws.Status, error) {
+	var status *upws.Status
+	err := s.conf.MattermostAPI().KV.Get(KVWebSocketStatusPrefix+string(appID), &status)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, utils.NewNotFoundError("no connection status for %s", appID)
+	}
+	return status, nil
+}
+
+func (s *webSocketStatusStore) Save(appID apps.AppID, status upws.Status, ttl time.Duration) error {
+	_, err := s.conf.MattermostAPI().KV.Set(KVWebSocketStatusPrefix+string(appID), status, pluginapi.SetExpiry(ttl))
+	if err != nil {
+		return errors.Wrapf(err, "failed to store the connection status of %s", appID)
+	}
+	return nil
+}
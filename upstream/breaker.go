@@ -0,0 +1,200 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upstream
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// ErrCircuitOpen is returned for the requests to an app that are rejected
+// because its circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type BreakerState string
+
+const (
+	// BreakerClosed lets all requests through to the app.
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen rejects all requests to the app, until the cooldown has
+	// elapsed.
+	BreakerOpen BreakerState = "open"
+
+	// BreakerHalfOpen lets a single trial request through to the app. If it
+	// succeeds, the breaker closes, otherwise it opens again.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerPolicy configures the per-app circuit breakers.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failed requests that opens
+	// the breaker, 0 disables the circuit breakers.
+	FailureThreshold int
+
+	// Cooldown is how long the breaker stays open before a trial request is
+	// let through.
+	Cooldown time.Duration
+}
+
+// BreakerStatus is the state of an app's circuit breaker.
+type BreakerStatus struct {
+	State BreakerState `json:"state"`
+
+	// Failures is the number of consecutive failed requests.
+	Failures  int       `json:"failures,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	OpenedAt  time.Time `json:"opened_at,omitempty"`
+}
+
+// Breakers keeps the circuit breakers of the apps. The breakers are local to
+// the Mattermost server node.
+type Breakers struct {
+	mutex sync.Mutex
+	apps  map[apps.AppID]*breaker
+
+	now func() time.Time
+}
+
+type breaker struct {
+	BreakerStatus
+	trialInProgress bool
+}
+
+func NewBreakers() *Breakers {
+	return &Breakers{
+		apps: map[apps.AppID]*breaker{},
+		now:  time.Now,
+	}
+}
+
+// Status returns the state of the app's circuit breaker.
+func (b *Breakers) Status(appID apps.AppID) BreakerStatus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if s := b.apps[appID]; s != nil {
+		return s.BreakerStatus
+	}
+	return BreakerStatus{State: BreakerClosed}
+}
+
+// Reset closes the app's circuit breaker.
+func (b *Breakers) Reset(appID apps.AppID) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.apps, appID)
+}
+
+// allow returns an error if the request should be rejected. If trial is
+// false, the request does not report its outcome, so it is not used as the
+// trial request of a half-open breaker.
+func (b *Breakers) allow(appID apps.AppID, policy BreakerPolicy, trial bool) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	s := b.apps[appID]
+	if s == nil {
+		return nil
+	}
+
+	switch s.State {
+	case BreakerOpen:
+		if b.now().Before(s.OpenedAt.Add(policy.Cooldown)) || !trial {
+			return errors.Wrapf(ErrCircuitOpen, "app %s failed %v times, last error: %s", appID, s.Failures, s.LastError)
+		}
+		s.State = BreakerHalfOpen
+		s.trialInProgress = true
+		return nil
+
+	case BreakerHalfOpen:
+		if s.trialInProgress || !trial {
+			return errors.Wrapf(ErrCircuitOpen, "app %s is being retried", appID)
+		}
+		s.trialInProgress = true
+	}
+	return nil
+}
+
+// record updates the app's breaker with the outcome of a request, err is the
+// error it failed with.
+func (b *Breakers) record(ctx context.Context, appID apps.AppID, policy BreakerPolicy, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	s := b.apps[appID]
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		// Inconclusive, the request was canceled by the caller; let another
+		// trial through.
+		if s != nil {
+			s.trialInProgress = false
+		}
+		return
+	case !isFailure(ctx, err):
+		delete(b.apps, appID)
+		return
+	}
+
+	if s == nil {
+		s = &breaker{BreakerStatus: BreakerStatus{State: BreakerClosed}}
+		b.apps[appID] = s
+	}
+	s.Failures++
+	s.LastError = err.Error()
+	s.trialInProgress = false
+	if s.State == BreakerHalfOpen || s.Failures >= policy.FailureThreshold {
+		s.State = BreakerOpen
+		s.OpenedAt = b.now()
+	}
+}
+
+type breakerUpstream struct {
+	Upstream
+	breakers *Breakers
+	policy   BreakerPolicy
+}
+
+// WithCircuitBreaker fails the requests to an app fast, without reaching it,
+// while the app is failing, according to policy.
+func WithCircuitBreaker(breakers *Breakers, policy BreakerPolicy) Middleware {
+	return func(up Upstream) Upstream {
+		if breakers == nil || policy.FailureThreshold <= 0 {
+			return up
+		}
+		return &breakerUpstream{
+			Upstream: up,
+			breakers: breakers,
+			policy:   policy,
+		}
+	}
+}
+
+func (u *breakerUpstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
+	if err := u.breakers.allow(app.AppID, u.policy, !async); err != nil {
+		return nil, err
+	}
+	r, err := u.Upstream.Roundtrip(ctx, app, creq, async)
+	if !async {
+		u.breakers.record(ctx, app.AppID, u.policy, err)
+	}
+	return r, err
+}
+
+func (u *breakerUpstream) GetStatic(ctx context.Context, app apps.App, path string) (io.ReadCloser, int, error) {
+	if err := u.breakers.allow(app.AppID, u.policy, true); err != nil {
+		return nil, http.StatusServiceUnavailable, err
+	}
+	r, status, err := u.Upstream.GetStatic(ctx, app, path)
+	recordErr := err
+	if err == nil && status >= http.StatusInternalServerError {
+		recordErr = errors.Errorf("failed to fetch %s: status %v", path, status)
+	}
+	u.breakers.record(ctx, app.AppID, u.policy, recordErr)
+	return r, status, err
+}
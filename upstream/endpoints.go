@@ -0,0 +1,34 @@
+// Copyright (c) 2020-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upstream
+
+import (
+	"context"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// EndpointStatus is the health of one of the endpoints of an app that has
+// several, e.g. one of the root URLs of an HTTP app.
+type EndpointStatus struct {
+	URL     string `json:"url"`
+	Healthy bool   `json:"healthy"`
+
+	// Failures is the number of consecutive failed requests to the endpoint.
+	Failures int `json:"failures,omitempty"`
+
+	// EjectedUntil is set while the endpoint is skipped after a failure.
+	EjectedUntil time.Time `json:"ejected_until,omitempty"`
+
+	// LastError is the error of the last failed request.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// EndpointPinger is implemented by upstreams that can check each of the
+// endpoints of an app. PingEndpoints returns nil if the app has a single
+// endpoint.
+type EndpointPinger interface {
+	PingEndpoints(context.Context, apps.App) []EndpointStatus
+}
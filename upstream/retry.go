@@ -0,0 +1,98 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upstream
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// RetryPolicy configures the retries of the idempotent requests, see
+// IsIdempotent. Static asset requests are always idempotent; notifications and
+// other calls are never retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a failed request is retried, 0
+	// disables the retries.
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry, it is doubled for
+	// each subsequent one, up to MaxBackoff. A random jitter of up to 20% is
+	// added.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type retryUpstream struct {
+	Upstream
+	policy RetryPolicy
+}
+
+// WithRetry retries the failed idempotent requests, according to policy.
+func WithRetry(policy RetryPolicy) Middleware {
+	return func(up Upstream) Upstream {
+		if policy.MaxRetries <= 0 {
+			return up
+		}
+		return &retryUpstream{
+			Upstream: up,
+			policy:   policy,
+		}
+	}
+}
+
+func (u *retryUpstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
+	if async || !IsIdempotent(app, creq) {
+		return u.Upstream.Roundtrip(ctx, app, creq, async)
+	}
+
+	for attempt := 0; ; attempt++ {
+		r, err := u.Upstream.Roundtrip(ctx, app, creq, false)
+		if !isFailure(ctx, err) || attempt >= u.policy.MaxRetries {
+			return r, err
+		}
+		if !u.wait(ctx, attempt) {
+			return r, err
+		}
+	}
+}
+
+func (u *retryUpstream) GetStatic(ctx context.Context, app apps.App, path string) (io.ReadCloser, int, error) {
+	for attempt := 0; ; attempt++ {
+		r, status, err := u.Upstream.GetStatic(ctx, app, path)
+		failed := isFailure(ctx, err) || (err == nil && status >= http.StatusInternalServerError)
+		if !failed || attempt >= u.policy.MaxRetries {
+			return r, status, err
+		}
+		if !u.wait(ctx, attempt) {
+			return r, status, err
+		}
+		if r != nil {
+			r.Close()
+		}
+	}
+}
+
+// wait sleeps before the retry that follows attempt. It returns false if ctx
+// is done before the delay has elapsed.
+func (u *retryUpstream) wait(ctx context.Context, attempt int) bool {
+	delay := u.policy.InitialBackoff << attempt
+	if delay <= 0 || (u.policy.MaxBackoff > 0 && delay > u.policy.MaxBackoff) {
+		delay = u.policy.MaxBackoff
+	}
+	delay += time.Duration(rand.Int63n(int64(delay)/5 + 1)) // nolint:gosec
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
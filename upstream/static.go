@@ -0,0 +1,89 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StaticAsset is returned by GetStatic by the upstreams that provide the
+// asset's metadata, e.g. from the HTTP response headers.
+type StaticAsset struct {
+	io.ReadCloser
+
+	ETag         string
+	LastModified time.Time
+	ContentType  string
+
+	// NoStore is set if the upstream does not allow the asset to be cached.
+	NoStore bool
+}
+
+// NewStaticAsset returns the asset with the metadata from header.
+func NewStaticAsset(body io.ReadCloser, header http.Header) *StaticAsset {
+	asset := &StaticAsset{
+		ReadCloser:  body,
+		ETag:        header.Get("ETag"),
+		ContentType: header.Get("Content-Type"),
+		NoStore:     strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-store"),
+	}
+	if lastModified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		asset.LastModified = lastModified
+	}
+	return asset
+}
+
+// NewCachedStaticAsset returns an asset with data as its content. The content
+// implements io.ReadSeeker.
+func NewCachedStaticAsset(data []byte, etag string, lastModified time.Time, contentType string) *StaticAsset {
+	return &StaticAsset{
+		ReadCloser:   readSeekNopCloser{bytes.NewReader(data)},
+		ETag:         etag,
+		LastModified: lastModified,
+		ContentType:  contentType,
+	}
+}
+
+type readSeekNopCloser struct {
+	*bytes.Reader
+}
+
+func (readSeekNopCloser) Close() error { return nil }
+
+type revalidateKey struct{}
+
+type revalidation struct {
+	etag         string
+	lastModified time.Time
+}
+
+// WithRevalidation requests the upstreams that support it to revalidate a
+// cached static asset. They make a conditional request, and return
+// http.StatusNotModified if the asset has not changed.
+func WithRevalidation(ctx context.Context, etag string, lastModified time.Time) context.Context {
+	return context.WithValue(ctx, revalidateKey{}, revalidation{
+		etag:         etag,
+		lastModified: lastModified,
+	})
+}
+
+// SetConditionalHeaders adds the If-None-Match and If-Modified-Since headers to
+// req, if ctx was created with WithRevalidation.
+func SetConditionalHeaders(ctx context.Context, req *http.Request) {
+	rv, ok := ctx.Value(revalidateKey{}).(revalidation)
+	if !ok {
+		return
+	}
+	if rv.etag != "" {
+		req.Header.Set("If-None-Match", rv.etag)
+	}
+	if !rv.lastModified.IsZero() {
+		req.Header.Set("If-Modified-Since", rv.lastModified.UTC().Format(http.TimeFormat))
+	}
+}
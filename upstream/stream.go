@@ -0,0 +1,63 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upstream
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// Apps that keep a persistent connection to Mattermost (exec, websocket)
+// exchange JSON messages with it over the connection. Mattermost sends
+// StreamRequests, the app sends back StreamResponses. Requests may be sent
+// before the responses to the previous ones are received, the app can respond
+// to them in any order, matching by ID.
+//
+// Notifications have no ID, the app must not respond to them.
+
+const (
+	// StreamMessageTypeCall requests the app to execute CallRequest, and
+	// respond with a CallResponse.
+	StreamMessageTypeCall = "call"
+
+	// StreamMessageTypeNotify requests the app to execute CallRequest, no
+	// response is expected.
+	StreamMessageTypeNotify = "notify"
+
+	// StreamMessageTypeStatic requests the content of the static asset at
+	// Path, relative to the app's static folder, may be nested, e.g.
+	// "img/logo.png".
+	StreamMessageTypeStatic = "static"
+
+	// StreamMessageTypePing checks that the app is responsive, the app must
+	// respond with an empty StreamResponse. Exec apps are pinged when a request
+	// times out, and are restarted if they fail to respond to the ping.
+	StreamMessageTypePing = "ping"
+)
+
+// MaxStreamMessageSize is the maximum size of a single message sent by the app.
+const MaxStreamMessageSize = 32 * 1024 * 1024
+
+type StreamRequest struct {
+	ID          uint64            `json:"id,omitempty"`
+	Type        string            `json:"type"`
+	CallRequest *apps.CallRequest `json:"call_request,omitempty"`
+	Path        string            `json:"path,omitempty"`
+}
+
+type StreamResponse struct {
+	ID uint64 `json:"id"`
+
+	// CallResponse is the response to a "call" request.
+	CallResponse json.RawMessage `json:"call_response,omitempty"`
+
+	// Data is the content of the static asset for a "static" request. NotFound
+	// must be set if the asset does not exist.
+	Data     []byte `json:"data,omitempty"`
+	NotFound bool   `json:"not_found,omitempty"`
+
+	// Error is set if the request failed.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upstream
+
+import (
+	"context"
+	"time"
+)
+
+type callTimeoutKey struct{}
+
+// WithCallTimeout returns a context that expires after timeout, and carries
+// it so that the upstreams with their own response timers (e.g. exec,
+// websocket, wasm) use it instead of their defaults.
+func WithCallTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx = context.WithValue(ctx, callTimeoutKey{}, timeout)
+	return context.WithTimeout(ctx, timeout)
+}
+
+// CallTimeout returns the call timeout set with WithCallTimeout, or
+// defaultTimeout if there is none.
+func CallTimeout(ctx context.Context, defaultTimeout time.Duration) time.Duration {
+	if timeout, ok := ctx.Value(callTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+		return timeout
+	}
+	return defaultTimeout
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upstream
+
+import (
+	"context"
+	"io"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
+)
+
+type tracingUpstream struct {
+	Upstream
+}
+
+// WithTracing creates a span for each request to the app. It should be the
+// innermost middleware, so that each retry is traced.
+func WithTracing() Middleware {
+	return func(up Upstream) Upstream {
+		return &tracingUpstream{
+			Upstream: up,
+		}
+	}
+}
+
+func (u *tracingUpstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (_ io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "Upstream.Roundtrip",
+		tracing.AppIDKey.String(string(app.AppID)),
+		tracing.DeployTypeKey.String(string(app.DeployType)),
+		tracing.CallPathKey.String(creq.Path),
+		tracing.AsyncKey.Bool(async),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	return u.Upstream.Roundtrip(ctx, app, creq, async)
+}
+
+func (u *tracingUpstream) GetStatic(ctx context.Context, app apps.App, path string) (_ io.ReadCloser, _ int, err error) {
+	ctx, span := tracing.Start(ctx, "Upstream.GetStatic",
+		tracing.AppIDKey.String(string(app.AppID)),
+		tracing.DeployTypeKey.String(string(app.DeployType)),
+		tracing.StaticPathKey.String(path),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	return u.Upstream.GetStatic(ctx, app, path)
+}
@@ -2,6 +2,7 @@ package upaws
 
 import (
 	"os"
+	"strconv"
 	"text/template"
 )
 
@@ -21,6 +22,15 @@ const (
 
 	RegionEnvVar  = "MM_APPS_AWS_REGION"
 	DefaultRegion = "us-east-1"
+
+	// S3EndpointEnvVar, LambdaEndpointEnvVar, and IAMEndpointEnvVar override
+	// the AWS service endpoints, to use S3 and Lambda-compatible services such
+	// as MinIO or LocalStack. S3ForcePathStyleEnvVar set to "true" addresses
+	// the S3 buckets in the URL path rather than in the host name.
+	S3EndpointEnvVar       = "MM_APPS_AWS_S3_ENDPOINT"
+	S3ForcePathStyleEnvVar = "MM_APPS_AWS_S3_FORCE_PATH_STYLE"
+	LambdaEndpointEnvVar   = "MM_APPS_AWS_LAMBDA_ENDPOINT"
+	IAMEndpointEnvVar      = "MM_APPS_AWS_IAM_ENDPOINT"
 )
 
 const LambdaExecutionPolicyARN = ARN(`arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole`)
@@ -99,6 +109,17 @@ func DefaultAllowLambdaStatement(in PolicyStatement) PolicyStatement {
 	return in
 }
 
+// EndpointsFromEnv returns the AWS endpoint overrides set in the environment.
+func EndpointsFromEnv() Endpoints {
+	pathStyle, _ := strconv.ParseBool(os.Getenv(S3ForcePathStyleEnvVar))
+	return Endpoints{
+		S3:               os.Getenv(S3EndpointEnvVar),
+		S3ForcePathStyle: pathStyle,
+		Lambda:           os.Getenv(LambdaEndpointEnvVar),
+		IAM:              os.Getenv(IAMEndpointEnvVar),
+	}
+}
+
 func Region() string {
 	name := os.Getenv(RegionEnvVar)
 	if name != "" {
@@ -54,6 +54,10 @@ type Client interface {
 	CreateLambda(zipFile io.Reader, function, handler, runtime string, role ARN) (ARN, error)
 	CreateOrUpdateLambda(zipFile io.Reader, function, handler, runtime string, role ARN) (ARN, error)
 	SetLambdaEnvironmentVariables(arn string, started time.Time, vars map[string]*string) error
+	PublishLambdaVersion(function string, started time.Time) (string, error)
+	ListLambdaVersions(function string) ([]string, error)
+	GetLambdaAlias(function, alias string) (string, error)
+	SetLambdaAlias(function, alias, version string) error
 	CreatePolicy(name Name, data string) (ARN, error)
 	CreateRole(name Name) (ARN, error)
 	CreateS3Bucket(bucket string) error
@@ -82,11 +86,30 @@ type client struct {
 	s3Uploader s3manageriface.UploaderAPI
 	s3         s3iface.S3API
 
-	log    utils.Logger
-	region string
+	log       utils.Logger
+	region    string
+	endpoints Endpoints
 }
 
-func MakeClient(awsAccessKeyID, awsSecretAccessKey, region string, log utils.Logger) (Client, error) {
+// Endpoints overrides the AWS service endpoints, to use S3 and
+// Lambda-compatible services such as MinIO or LocalStack. The empty values
+// use the default AWS endpoints for the region.
+type Endpoints struct {
+	S3     string `json:"s3,omitempty"`
+	Lambda string `json:"lambda,omitempty"`
+	IAM    string `json:"iam,omitempty"`
+
+	// S3ForcePathStyle addresses the S3 buckets in the URL path
+	// (endpoint/bucket/key) rather than in the host name, as required by
+	// most S3-compatible services.
+	S3ForcePathStyle bool `json:"s3_force_path_style,omitempty"`
+}
+
+func (e Endpoints) IsEmpty() bool {
+	return e == Endpoints{}
+}
+
+func MakeClient(awsAccessKeyID, awsSecretAccessKey, region string, endpoints Endpoints, log utils.Logger) (Client, error) {
 	awsConfig := &aws.Config{
 		Region:      aws.String(region),
 		Credentials: credentials.NewStaticCredentials(awsAccessKeyID, awsSecretAccessKey, ""),
@@ -108,18 +131,34 @@ func MakeClient(awsAccessKeyID, awsSecretAccessKey, region string, log utils.Log
 		}
 	})
 
+	lambdaConfig := awsConfig.Copy()
+	if endpoints.Lambda != "" {
+		lambdaConfig.Endpoint = aws.String(endpoints.Lambda)
+	}
+	iamConfig := aws.NewConfig()
+	if endpoints.IAM != "" {
+		iamConfig.Endpoint = aws.String(endpoints.IAM)
+	}
+	s3Config := aws.NewConfig().WithS3ForcePathStyle(endpoints.S3ForcePathStyle)
+	if endpoints.S3 != "" {
+		s3Config.Endpoint = aws.String(endpoints.S3)
+	}
+	s3Client := s3.New(awsSession, s3Config)
+
 	c := &client{
-		lambda:     lambda.New(awsSession, awsConfig),
-		iam:        iam.New(awsSession),
-		s3Down:     s3manager.NewDownloader(awsSession),
-		s3Uploader: s3manager.NewUploader(awsSession),
-		s3:         s3.New(awsSession),
+		lambda:     lambda.New(awsSession, lambdaConfig),
+		iam:        iam.New(awsSession, iamConfig),
+		s3Down:     s3manager.NewDownloaderWithClient(s3Client),
+		s3Uploader: s3manager.NewUploaderWithClient(s3Client),
+		s3:         s3Client,
 		log:        log,
 		region:     region,
+		endpoints:  endpoints,
 	}
 
 	log.Debugw("created an AWS client",
 		"region", region,
+		"endpoints", endpoints,
 		"access", utils.LastN(awsAccessKeyID, 7),
 		"secret", utils.LastN(awsSecretAccessKey, 4))
 
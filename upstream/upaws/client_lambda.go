@@ -9,6 +9,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -105,42 +107,150 @@ const updateWaitTime = 2 * time.Minute
 // function. It waits until an function code deployment succeeds before updating
 // the configuration.
 func (c *client) SetLambdaEnvironmentVariables(arn string, started time.Time, vars map[string]*string) error {
-	deadline := started.Add(updateWaitTime)
-	retry := 5 * time.Second
+	if err := c.waitForLambdaUpdate(arn, started.Add(updateWaitTime)); err != nil {
+		return errors.Wrap(err, "can't set environment variables")
+	}
+
+	_, err := c.lambda.UpdateFunctionConfiguration(&lambda.UpdateFunctionConfigurationInput{
+		FunctionName: aws.String(arn),
+		Environment: &lambda.Environment{
+			Variables: vars,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update function configuration for %s", arn)
+	}
+
+	c.log.Infof("set %v environment variables on %s", len(vars), arn)
+	return nil
+}
+
+// PublishLambdaVersion publishes a new version of a lambda function from its
+// current code and configuration, and returns the version number. It waits
+// until the pending updates of the function complete.
+func (c *client) PublishLambdaVersion(function string, started time.Time) (string, error) {
+	if err := c.waitForLambdaUpdate(function, started.Add(updateWaitTime)); err != nil {
+		return "", errors.Wrap(err, "can't publish a version")
+	}
+
+	fc, err := c.lambda.PublishVersion(&lambda.PublishVersionInput{
+		FunctionName: aws.String(function),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to publish a version of function %s", function)
+	}
+	c.log.Infow("published function version", "function", function, "version", *fc.Version)
+	return *fc.Version, nil
+}
+
+// ListLambdaVersions returns the published versions of a lambda function, in
+// ascending order.
+func (c *client) ListLambdaVersions(function string) ([]string, error) {
+	var numbers []int
+	err := c.lambda.ListVersionsByFunctionPages(&lambda.ListVersionsByFunctionInput{
+		FunctionName: aws.String(function),
+	}, func(out *lambda.ListVersionsByFunctionOutput, _ bool) bool {
+		for _, fc := range out.Versions {
+			// Skip $LATEST.
+			if n, err := strconv.Atoi(aws.StringValue(fc.Version)); err == nil {
+				numbers = append(numbers, n)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list versions of function %s", function)
+	}
+
+	sort.Ints(numbers)
+	versions := []string{}
+	for _, n := range numbers {
+		versions = append(versions, strconv.Itoa(n))
+	}
+	return versions, nil
+}
+
+// GetLambdaAlias returns the function version the alias points to.
+func (c *client) GetLambdaAlias(function, alias string) (string, error) {
+	out, err := c.lambda.GetAlias(&lambda.GetAliasInput{
+		FunctionName: aws.String(function),
+		Name:         aws.String(alias),
+	})
+	if err != nil {
+		if _, ok := err.(*lambda.ResourceNotFoundException); ok {
+			return "", utils.NewNotFoundError("alias %s of function %s", alias, function)
+		}
+		return "", errors.Wrapf(err, "failed to get alias %s of function %s", alias, function)
+	}
+	return *out.FunctionVersion, nil
+}
+
+// SetLambdaAlias points the alias to the function version, creating the alias
+// if it does not exist.
+func (c *client) SetLambdaAlias(function, alias, version string) error {
+	_, err := c.GetLambdaAlias(function, alias)
+	switch {
+	case errors.Cause(err) == utils.ErrNotFound:
+		_, err = c.lambda.CreateAlias(&lambda.CreateAliasInput{
+			FunctionName:    aws.String(function),
+			Name:            aws.String(alias),
+			FunctionVersion: aws.String(version),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create alias %s of function %s", alias, function)
+		}
+
+	case err != nil:
+		return err
+
+	default:
+		_, err = c.lambda.UpdateAlias(&lambda.UpdateAliasInput{
+			FunctionName:    aws.String(function),
+			Name:            aws.String(alias),
+			FunctionVersion: aws.String(version),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to update alias %s of function %s", alias, function)
+		}
+	}
+
+	c.log.Infow("set function alias", "function", function, "alias", alias, "version", version)
+	return nil
+}
 
-RETRY:
+// waitForLambdaUpdate waits until a function code or configuration update
+// succeeds, or the deadline.
+func (c *client) waitForLambdaUpdate(function string, deadline time.Time) error {
+	retry := 5 * time.Second
 	for time.Now().Before(deadline) {
 		fc, err := c.lambda.GetFunctionConfiguration(&lambda.GetFunctionConfigurationInput{
-			FunctionName: aws.String(arn),
+			FunctionName: aws.String(function),
 		})
 		if err != nil {
-			return errors.Wrapf(err, "failed to get function configuration for %s", arn)
+			return errors.Wrapf(err, "failed to get function configuration for %s", function)
 		}
 
-		switch *fc.LastUpdateStatus {
-		case "Successful":
-			break RETRY
+		switch aws.StringValue(fc.LastUpdateStatus) {
+		case "", "Successful":
+			return nil
 		case "Failed":
-			return errors.New("can't set environment variables after a failed deployment")
+			return errors.Errorf("function %s deployment failed", function)
 		default:
 			c.log.Infof("function deployment %s, will wait %v", *fc.LastUpdateStatus, retry)
 			time.Sleep(retry)
 			retry *= 2
 		}
 	}
+	return nil
+}
 
-	_, err := c.lambda.UpdateFunctionConfiguration(&lambda.UpdateFunctionConfigurationInput{
-		FunctionName: aws.String(arn),
-		Environment: &lambda.Environment{
-			Variables: vars,
-		},
-	})
-	if err != nil {
-		return errors.Wrapf(err, "failed to update function configuration for %s", arn)
+// LambdaQualifiedName returns the name to invoke the function with the alias,
+// or the function name if alias is empty.
+func LambdaQualifiedName(function, alias string) string {
+	if alias == "" {
+		return function
 	}
-
-	c.log.Infof("set %v environment variables on %s", len(vars), arn)
-	return nil
+	return function + ":" + alias
 }
 
 // LambdaName generates function name for a specific app, name can be 64
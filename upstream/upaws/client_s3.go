@@ -51,7 +51,7 @@ func (c *client) GetS3(ctx context.Context, bucket, item string) ([]byte, error)
 
 // UploadS3 uploads file to a specific S3 bucket
 func (c *client) UploadS3(bucket, key string, body io.Reader, publicRead bool) (string, error) {
-	_, err := c.s3Uploader.Upload(&s3manager.UploadInput{
+	out, err := c.s3Uploader.Upload(&s3manager.UploadInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 		Body:   body,
@@ -71,6 +71,11 @@ func (c *client) UploadS3(bucket, key string, body io.Reader, publicRead bool) (
 		}
 	}
 
+	if c.endpoints.S3 != "" {
+		// The uploader resolves the location with the custom endpoint and
+		// addressing style.
+		return out.Location, nil
+	}
 	u := url.URL{
 		Scheme: `https`,
 		Host:   fmt.Sprintf(`s3-%s.amazonaws.com`, c.region),
@@ -136,7 +141,9 @@ func ParseS3ManifestName(key string) (apps.AppID, apps.AppVersion, error) {
 }
 
 // S3StaticName generates key for a specific asset in S3,
-// key can be 1024 characters long.
+// key can be 1024 characters long. Nested asset paths, e.g. "img/logo.png",
+// are kept as nested keys under the app's static prefix. name is expected
+// to be clean, see utils.CleanStaticPath.
 func S3StaticName(appID apps.AppID, version apps.AppVersion, name string) string {
 	sanitizedName := strings.ReplaceAll(name, " ", "-")
 	return fmt.Sprintf("%s/%s_%s_app/%s", path.StaticFolder, appID, version, sanitizedName)
@@ -0,0 +1,60 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upaws
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func TestClientEndpoints(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/test-bucket/manifests/app.json":
+			_, _ = w.Write([]byte(`{"app_id":"app"}`))
+		case req.Method == http.MethodPut && req.URL.Path == "/test-bucket/static/icon.png":
+			_, _ = io.Copy(io.Discard, req.Body)
+		case req.Method == http.MethodPost && req.URL.Path == "/2015-03-31/functions/test-function/invocations":
+			_, _ = io.Copy(w, req.Body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := MakeClient("access", "secret", DefaultRegion, Endpoints{
+		S3:               server.URL,
+		S3ForcePathStyle: true,
+		Lambda:           server.URL,
+	}, utils.NewTestLogger())
+	require.NoError(t, err)
+
+	data, err := c.GetS3(context.Background(), "test-bucket", "manifests/app.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"app_id":"app"}`, string(data))
+
+	location, err := c.UploadS3("test-bucket", "static/icon.png", bytes.NewReader([]byte("icon")), false)
+	require.NoError(t, err)
+	require.Equal(t, server.URL+"/test-bucket/static/icon.png", location)
+
+	data, err = c.InvokeLambda(context.Background(), "test-function", "RequestResponse", []byte(`{"path":"/ping"}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"path":"/ping"}`, string(data))
+
+	require.Equal(t, []string{
+		"GET /test-bucket/manifests/app.json",
+		"PUT /test-bucket/static/icon.png",
+		"POST /2015-03-31/functions/test-function/invocations",
+	}, requests)
+}
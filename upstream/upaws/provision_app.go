@@ -22,14 +22,25 @@ type DeployAppParams struct {
 	ExecuteRoleName  Name
 	ShouldUpdate     bool
 	Environment      map[string]string
+
+	// Alias is the AWS Lambda alias to move to the newly published function
+	// versions. It defaults to the alias in the manifest, or DefaultLambdaAlias.
+	// The alias is then pinned in the deployed manifest.
+	Alias string
 }
 
+// DefaultLambdaAlias is the alias the deployed functions are invoked with,
+// unless the manifest specifies one.
+const DefaultLambdaAlias = "live"
+
 type DeployAppResult struct {
 	InvokePolicyDoc  string
 	InvokePolicyARN  ARN
 	ExecuteRoleARN   ARN
 	ExecutePolicyARN ARN
 	LambdaARNs       []ARN
+	LambdaAlias      string
+	LambdaVersions   map[string]string
 	StaticARNs       []ARN
 	ManifestURL      string
 	Manifest         apps.Manifest
@@ -107,7 +118,17 @@ func deployLambdaFunctions(c Client, log utils.Logger, pd *DeployData, params De
 	}
 	log.Infow("found execute role, deploying functions", "ARN", executeRoleARN)
 
+	alias := params.Alias
+	if alias == "" {
+		alias = pd.Manifest.AWSLambda.Alias
+	}
+	if alias == "" {
+		alias = DefaultLambdaAlias
+	}
+
 	createdARNs := []ARN{}
+	invokeARNs := []ARN{}
+	functionARNs := map[string]ARN{}
 	for _, function := range pd.LambdaFunctions {
 		lambdaARN := ARN("")
 		if params.ShouldUpdate {
@@ -122,6 +143,9 @@ func deployLambdaFunctions(c Client, log utils.Logger, pd *DeployData, params De
 			}
 		}
 		createdARNs = append(createdARNs, lambdaARN)
+		// Allow invoking the function both with and without the alias.
+		invokeARNs = append(invokeARNs, lambdaARN, lambdaARN+":"+ARN(alias))
+		functionARNs[function.Name] = lambdaARN
 		function.Bundle.Close()
 	}
 
@@ -132,18 +156,18 @@ func deployLambdaFunctions(c Client, log utils.Logger, pd *DeployData, params De
 	invokePolicyARN := ARN(*invokePolicy.Arn)
 	log.Infow("found invoke policy, updating", "ARN", invokePolicyARN)
 
-	newDoc, err := c.AddResourcesToPolicyDocument(invokePolicy, createdARNs)
+	newDoc, err := c.AddResourcesToPolicyDocument(invokePolicy, invokeARNs)
 	if err != nil {
 		return err
 	}
 
+	started := time.Now()
 	if len(params.Environment) > 0 {
-		started := time.Now()
 		awsVars := map[string]*string{}
 		for k, v := range params.Environment {
 			awsVars[k] = aws.String(v)
 		}
-		for _, arn := range createdARNs {
+		for _, arn := range functionARNs {
 			err = c.SetLambdaEnvironmentVariables(string(arn), started, awsVars)
 			if err != nil {
 				return err
@@ -151,7 +175,25 @@ func deployLambdaFunctions(c Client, log utils.Logger, pd *DeployData, params De
 		}
 	}
 
+	// Publish the new versions and move the alias to them, the previous
+	// versions remain available for a rollback.
+	versions := map[string]string{}
+	for name := range functionARNs {
+		var version string
+		version, err = c.PublishLambdaVersion(name, started)
+		if err != nil {
+			return err
+		}
+		if err = c.SetLambdaAlias(name, alias, version); err != nil {
+			return err
+		}
+		versions[name] = version
+	}
+	pd.Manifest.AWSLambda.Alias = alias
+
 	out.LambdaARNs = createdARNs
+	out.LambdaAlias = alias
+	out.LambdaVersions = versions
 	out.InvokePolicyDoc = newDoc
 	out.InvokePolicyARN = invokePolicyARN
 	out.ExecuteRoleARN = executeRoleARN
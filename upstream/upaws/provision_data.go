@@ -104,8 +104,12 @@ func getDeployData(b []byte, log utils.Logger) (*DeployData, error) {
 			log.Infow("found lambda function bundle", "file", file.Name)
 
 		case strings.HasPrefix(file.Name, path.StaticFolder+"/"):
-			assetName := strings.TrimPrefix(file.Name, path.StaticFolder+"/")
-			if assetName == "" {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			assetName, err := utils.CleanStaticPath(strings.TrimPrefix(file.Name, path.StaticFolder+"/"))
+			if err != nil {
+				log.WithError(err).Infow("ignored invalid static asset path", "file", file.Name)
 				continue
 			}
 			assetFile, err := file.Open()
@@ -1,6 +1,8 @@
 package upaws
 
 import (
+	"archive/zip"
+	"bytes"
 	"path/filepath"
 	"testing"
 
@@ -84,3 +86,37 @@ func TestGetDeployData(t *testing.T) {
 		require.Equal(t, tc.value, asset.Key)
 	}
 }
+
+func TestGetDeployDataNestedStatic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, data := range map[string]string{
+		"manifest.json":              `{"app_id":"test","display_name":"Test","version":"v1.0.0","homepage_url":"https://test.test","aws_lambda":{"functions":[{"path":"/","name":"f","handler":"h","runtime":"go1.x"}]}}`,
+		"f.zip":                      "function",
+		"static/":                    "",
+		"static/img/":                "",
+		"static/img/logo.png":        "logo",
+		"static/web/js/app.js":       "js",
+		"static/../manifest.json":    "traversal",
+		"static/icon with space.png": "icon",
+	} {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	deployData, err := getDeployData(buf.Bytes(), utils.NewTestLogger())
+	require.NoError(t, err)
+	require.Len(t, deployData.StaticFiles, 3)
+	for key, value := range map[string]string{
+		"img/logo.png":        "static/test_v1.0.0_app/img/logo.png",
+		"web/js/app.js":       "static/test_v1.0.0_app/web/js/app.js",
+		"icon with space.png": "static/test_v1.0.0_app/icon-with-space.png",
+	} {
+		asset, ok := deployData.StaticFiles[key]
+		require.True(t, ok, key)
+		require.Equal(t, value, asset.Key)
+	}
+}
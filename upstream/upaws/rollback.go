@@ -0,0 +1,121 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upaws
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type RollbackAppParams struct {
+	Bucket  string
+	AppID   apps.AppID
+	Version apps.AppVersion
+
+	// Alias to move, defaults to the alias pinned in the deployed manifest.
+	Alias string
+
+	// ToVersion is the function version to point the alias at. By default, the
+	// alias is moved to the latest version published before the current one.
+	ToVersion string
+}
+
+type RollbackAppResult struct {
+	Alias string
+
+	// Functions maps the names of the rolled back functions to their
+	// previous and current versions.
+	Functions map[string]RollbackedFunction
+}
+
+type RollbackedFunction struct {
+	From string
+	To   string
+}
+
+// RollbackApp points the alias of the app's deployed lambda functions back at
+// their previous versions. The deployed manifest is downloaded from S3 to find
+// the functions.
+func RollbackApp(c Client, log utils.Logger, params RollbackAppParams) (*RollbackAppResult, error) {
+	key := S3ManifestName(params.AppID, params.Version)
+	data, err := c.GetS3(context.Background(), params.Bucket, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download manifest %s", key)
+	}
+	m, err := apps.DecodeCompatibleManifest(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode manifest %s", key)
+	}
+	if !m.Contains(apps.DeployAWSLambda) {
+		return nil, utils.NewInvalidError("app %s %s is not deployable to AWS Lambda", params.AppID, params.Version)
+	}
+
+	alias := params.Alias
+	if alias == "" {
+		alias = m.AWSLambda.Alias
+	}
+	if alias == "" {
+		return nil, utils.NewInvalidError("app %s %s was deployed without an alias, nothing to roll back", params.AppID, params.Version)
+	}
+
+	out := RollbackAppResult{
+		Alias:     alias,
+		Functions: map[string]RollbackedFunction{},
+	}
+	for _, f := range m.AWSLambda.Functions {
+		name := LambdaName(m.AppID, m.Version, f.Name)
+		current, err := c.GetLambdaAlias(name, alias)
+		if err != nil {
+			return nil, err
+		}
+
+		target := params.ToVersion
+		if target == "" {
+			versions, err := c.ListLambdaVersions(name)
+			if err != nil {
+				return nil, err
+			}
+			target, err = previousLambdaVersion(versions, current)
+			if err != nil {
+				return nil, errors.Wrapf(err, "can't roll back function %s", name)
+			}
+		}
+
+		if err = c.SetLambdaAlias(name, alias, target); err != nil {
+			return nil, err
+		}
+		out.Functions[name] = RollbackedFunction{
+			From: current,
+			To:   target,
+		}
+		log.Infow("rolled back function", "function", name, "alias", alias, "from", current, "to", target)
+	}
+	return &out, nil
+}
+
+// previousLambdaVersion returns the latest of the versions, published before
+// current.
+func previousLambdaVersion(versions []string, current string) (string, error) {
+	currentN, err := strconv.Atoi(current)
+	if err != nil {
+		return "", utils.NewInvalidError("alias points at %q, not a published version", current)
+	}
+	previous := 0
+	for _, v := range versions {
+		n, err := strconv.Atoi(v)
+		if err != nil || n >= currentN || n <= previous {
+			continue
+		}
+		previous = n
+	}
+	if previous == 0 {
+		return "", utils.NewNotFoundError("no version published before %s", current)
+	}
+	return strconv.Itoa(previous), nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upaws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// fakeLambdaClient implements the Client methods used by the rollback, the
+// others panic.
+type fakeLambdaClient struct {
+	Client
+	s3       map[string][]byte
+	versions map[string][]string
+	aliases  map[string]string
+	invoked  []string
+}
+
+func (c *fakeLambdaClient) InvokeLambda(_ context.Context, name, _ string, _ []byte) ([]byte, error) {
+	c.invoked = append(c.invoked, name)
+	return json.Marshal(apps.HTTPCallResponse{
+		StatusCode: http.StatusOK,
+		Body:       `{"type":"ok"}`,
+	})
+}
+
+func (c *fakeLambdaClient) GetS3(_ context.Context, bucket, item string) ([]byte, error) {
+	data, ok := c.s3[bucket+"/"+item]
+	if !ok {
+		return nil, utils.NewNotFoundError(item)
+	}
+	return data, nil
+}
+
+func (c *fakeLambdaClient) ListLambdaVersions(function string) ([]string, error) {
+	return c.versions[function], nil
+}
+
+func (c *fakeLambdaClient) GetLambdaAlias(function, alias string) (string, error) {
+	version, ok := c.aliases[LambdaQualifiedName(function, alias)]
+	if !ok {
+		return "", utils.NewNotFoundError(alias)
+	}
+	return version, nil
+}
+
+func (c *fakeLambdaClient) SetLambdaAlias(function, alias, version string) error {
+	c.aliases[LambdaQualifiedName(function, alias)] = version
+	return nil
+}
+
+func TestRollbackApp(t *testing.T) {
+	m := apps.Manifest{
+		AppID:       "hello",
+		Version:     "v1.0.0",
+		DisplayName: "Hello",
+		HomepageURL: "https://example.org",
+		Deploy: apps.Deploy{
+			AWSLambda: &apps.AWSLambda{
+				Functions: []apps.AWSLambdaFunction{
+					{Path: "/", Name: "main", Handler: "main", Runtime: "go1.x"},
+					{Path: "/other", Name: "other", Handler: "other", Runtime: "go1.x"},
+				},
+				Alias: "prod",
+			},
+		},
+	}
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	mainName := LambdaName(m.AppID, m.Version, "main")
+	otherName := LambdaName(m.AppID, m.Version, "other")
+	c := &fakeLambdaClient{
+		s3: map[string][]byte{
+			"bucket/" + S3ManifestName(m.AppID, m.Version): data,
+		},
+		versions: map[string][]string{
+			mainName:  {"1", "2", "3"},
+			otherName: {"1", "3", "10"},
+		},
+		aliases: map[string]string{
+			mainName + ":prod":  "3",
+			otherName + ":prod": "10",
+		},
+	}
+	params := RollbackAppParams{
+		Bucket:  "bucket",
+		AppID:   m.AppID,
+		Version: m.Version,
+	}
+
+	out, err := RollbackApp(c, utils.NewTestLogger(), params)
+	require.NoError(t, err)
+	require.Equal(t, &RollbackAppResult{
+		Alias: "prod",
+		Functions: map[string]RollbackedFunction{
+			mainName:  {From: "3", To: "2"},
+			otherName: {From: "10", To: "3"},
+		},
+	}, out)
+
+	_, err = RollbackApp(c, utils.NewTestLogger(), params)
+	require.NoError(t, err)
+	_, err = RollbackApp(c, utils.NewTestLogger(), params)
+	require.EqualError(t, err, "can't roll back function "+mainName+": no version published before 1: not found")
+
+	params.ToVersion = "3"
+	out, err = RollbackApp(c, utils.NewTestLogger(), params)
+	require.NoError(t, err)
+	require.Equal(t, RollbackedFunction{From: "1", To: "3"}, out.Functions[mainName])
+
+	t.Run("invoke with alias", func(t *testing.T) {
+		up := &Upstream{awsClient: c}
+		app := apps.App{Manifest: m}
+		_, err := up.Roundtrip(context.Background(), app, apps.CallRequest{Call: *apps.NewCall("/other/submit")}, false)
+		require.NoError(t, err)
+
+		app.Manifest.AWSLambda = &apps.AWSLambda{Functions: m.AWSLambda.Functions}
+		_, err = up.Roundtrip(context.Background(), app, apps.CallRequest{Call: *apps.NewCall("/submit")}, false)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{otherName + ":prod", mainName}, c.invoked)
+	})
+
+	params.Alias = "staging"
+	_, err = RollbackApp(c, utils.NewTestLogger(), params)
+	require.Error(t, err)
+	require.Equal(t, utils.ErrNotFound, errors.Cause(err))
+}
@@ -28,11 +28,11 @@ type Upstream struct {
 
 var _ upstream.Upstream = (*Upstream)(nil)
 
-func MakeUpstream(accessKey, secret, region, staticS3bucket string, log utils.Logger) (*Upstream, error) {
+func MakeUpstream(accessKey, secret, region, staticS3bucket string, endpoints Endpoints, log utils.Logger) (*Upstream, error) {
 	if accessKey == "" && secret == "" {
 		return nil, utils.NewNotFoundError("AWS credentials are not set")
 	}
-	awsClient, err := MakeClient(accessKey, secret, region,
+	awsClient, err := MakeClient(accessKey, secret, region, endpoints,
 		log.With("purpose", "App Proxy"))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize AWS access")
@@ -60,6 +60,7 @@ func (u *Upstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRe
 	if name == "" {
 		return nil, utils.ErrNotFound
 	}
+	name = LambdaQualifiedName(name, app.Manifest.AWSLambda.Alias)
 
 	data, err := u.invokeFunction(ctx, name, async, creq)
 	if err != nil {
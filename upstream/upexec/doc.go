@@ -0,0 +1,18 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// Package upexec implements the upstream for apps that run as local
+// executables, managed by the plugin.
+//
+// Exec apps communicate with Mattermost over their stdin and stdout, using the
+// messages defined in upstream.StreamRequest and upstream.StreamResponse. Each
+// message is a single line of JSON, terminated with "\n". Mattermost writes
+// requests to the app's stdin, the app writes responses to its stdout.
+//
+// Anything the app writes to its stderr is logged by Mattermost. The app must
+// exit when its stdin is closed.
+//
+// A request that the app does not respond to in time fails on its own, the app
+// keeps running. Mattermost then pings the app, and restarts it if it does not
+// respond to the ping either.
+package upexec
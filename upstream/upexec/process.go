@@ -0,0 +1,291 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upexec
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// stopGracePeriod is how long a process is given to exit after its stdin is
+// closed, before it is killed.
+const stopGracePeriod = 5 * time.Second
+
+// errNoResponse is returned by request when the process does not respond in
+// time.
+var errNoResponse = errors.New("no response")
+
+// process is a running app executable. Requests to it are multiplexed over its
+// stdin and stdout.
+type process struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	log     utils.Logger
+	started time.Time
+
+	// pingTimeout is how long the process is given to respond to a ping,
+	// pinging is set while a ping is in progress.
+	pingTimeout time.Duration
+	pinging     int32
+
+	// writes are written to stdin by writeRequests, so that a process that
+	// does not read its stdin blocks only that goroutine.
+	writes chan writeRequest
+
+	mutex   sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan upstream.StreamResponse
+
+	// exitErr and exited are set before done is closed.
+	done    chan struct{}
+	exitErr error
+	exited  time.Time
+}
+
+type writeRequest struct {
+	data []byte
+	errC chan error
+}
+
+func startProcess(dir, command string, args []string, pingTimeout time.Duration, log utils.Logger) (*process, error) {
+	cmd := exec.Command(command, args...) // nolint:gosec // the command is in the admin-configured directory
+	cmd.Dir = dir
+	// Do not pass the plugin's environment, it may contain credentials.
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH")}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "failed to start %s", command)
+	}
+
+	p := &process{
+		cmd:         cmd,
+		stdin:       stdin,
+		log:         log,
+		started:     time.Now(),
+		pingTimeout: pingTimeout,
+		pending:     map[uint64]chan upstream.StreamResponse{},
+		writes:      make(chan writeRequest),
+		done:        make(chan struct{}),
+	}
+	go p.logStderr(stderr)
+	go p.readResponses(stdout)
+	go p.writeRequests()
+
+	log.Debugf("Started %s, pid %v", command, cmd.Process.Pid)
+	return p, nil
+}
+
+// roundtrip sends req to the process, and waits for the response. If the
+// process does not respond within timeout, only this request fails. The process
+// is then pinged, and killed if it does not respond to the ping either.
+func (p *process) roundtrip(ctx context.Context, req upstream.StreamRequest, timeout time.Duration) (upstream.StreamResponse, error) {
+	resp, err := p.request(ctx, req, timeout)
+	if err == errNoResponse {
+		p.log.Warnf("App process did not respond in %s, pinging it.", timeout)
+		go p.ping()
+		return upstream.StreamResponse{}, errors.Errorf("app process did not respond in %s", timeout)
+	}
+	return resp, err
+}
+
+// notify sends req to the process without waiting for a response. Like in
+// roundtrip, the process is pinged if it does not accept req within timeout.
+func (p *process) notify(req upstream.StreamRequest, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	_, err := p.send(context.Background(), timer.C, req)
+	if err == errNoResponse {
+		p.log.Warnf("App process did not read a notification in %s, pinging it.", timeout)
+		go p.ping()
+		return errors.Errorf("app process did not read a notification in %s", timeout)
+	}
+	return err
+}
+
+// ping kills the process if it does not respond to a ping within pingTimeout.
+// Only one ping is sent at a time.
+func (p *process) ping() {
+	if !atomic.CompareAndSwapInt32(&p.pinging, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&p.pinging, 0)
+
+	_, err := p.request(context.Background(), upstream.StreamRequest{
+		Type: upstream.StreamMessageTypePing,
+	}, p.pingTimeout)
+	if err != nil && p.running() {
+		p.log.WithError(err).Warnf("App process did not respond to ping, killing it.")
+		p.kill()
+	}
+}
+
+func (p *process) request(ctx context.Context, req upstream.StreamRequest, timeout time.Duration) (upstream.StreamResponse, error) {
+	respC := make(chan upstream.StreamResponse, 1)
+	p.mutex.Lock()
+	p.nextID++
+	req.ID = p.nextID
+	p.pending[req.ID] = respC
+	p.mutex.Unlock()
+	defer func() {
+		p.mutex.Lock()
+		delete(p.pending, req.ID)
+		p.mutex.Unlock()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	errC, err := p.send(ctx, timer.C, req)
+	if err != nil {
+		return upstream.StreamResponse{}, err
+	}
+
+	select {
+	case resp := <-respC:
+		return resp, nil
+	case err = <-errC:
+		return upstream.StreamResponse{}, err
+	case <-p.done:
+		return upstream.StreamResponse{}, errors.Wrap(p.exitErr, "app process exited")
+	case <-ctx.Done():
+		return upstream.StreamResponse{}, ctx.Err()
+	case <-timer.C:
+		return upstream.StreamResponse{}, errNoResponse
+	}
+}
+
+// send hands req to writeRequests. It returns errNoResponse if the process
+// does not accept it before timeout fires. Write errors are sent to the
+// returned channel.
+func (p *process) send(ctx context.Context, timeout <-chan time.Time, req upstream.StreamRequest) (<-chan error, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	w := writeRequest{
+		data: append(data, '\n'),
+		errC: make(chan error, 1),
+	}
+
+	select {
+	case p.writes <- w:
+		return w.errC, nil
+	case <-p.done:
+		return nil, errors.Wrap(p.exitErr, "app process exited")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timeout:
+		return nil, errNoResponse
+	}
+}
+
+// writeRequests writes the requests to the process' stdin, one at a time,
+// until the process exits.
+func (p *process) writeRequests() {
+	for {
+		select {
+		case w := <-p.writes:
+			if _, err := p.stdin.Write(w.data); err != nil {
+				w.errC <- errors.Wrap(err, "failed to write to app process")
+			}
+		case <-p.done:
+			return
+		}
+	}
+}
+
+func (p *process) readResponses(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), upstream.MaxStreamMessageSize)
+	for scanner.Scan() {
+		resp := upstream.StreamResponse{}
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			p.log.WithError(err).Warnf("Ignored invalid message from app process.")
+			continue
+		}
+		p.mutex.Lock()
+		respC := p.pending[resp.ID]
+		p.mutex.Unlock()
+		if respC == nil {
+			p.log.Debugf("Ignored response to unknown request %v from app process.", resp.ID)
+			continue
+		}
+		select {
+		case respC <- resp:
+		default:
+			// A duplicate response, the request already has one.
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		p.log.WithError(err).Warnf("Failed to read from app process, killing it.")
+		p.kill()
+	}
+	err := p.cmd.Wait()
+	if err == nil {
+		err = errors.New("exited")
+	}
+	p.exitErr = err
+	p.exited = time.Now()
+	p.log.Debugf("App process exited: %v", err)
+	close(p.done)
+}
+
+func (p *process) logStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		p.log.Debugf("App process: %s", scanner.Text())
+	}
+}
+
+func (p *process) running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop closes the process' stdin, and kills it if it does not exit within
+// stopGracePeriod. It does not wait for pending writes, closing stdin unblocks
+// them.
+func (p *process) stop() {
+	_ = p.stdin.Close()
+
+	go func() {
+		select {
+		case <-p.done:
+		case <-time.After(stopGracePeriod):
+			p.kill()
+		}
+	}()
+}
+
+func (p *process) kill() {
+	_ = p.cmd.Process.Kill()
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upexec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// EnvExecDir is the environment variable that enables exec apps. It must be
+// set to the absolute path of the directory that contains the apps'
+// executables. Only the server administrator can set it, apps can not run any
+// executables outside of it.
+const EnvExecDir = "MM_APPS_EXEC_DIR"
+
+const (
+	// A process that exits sooner than minUptime after it was started is
+	// considered crashing, and is restarted with an exponential backoff, up to
+	// maxRestartDelay.
+	minUptime       = 10 * time.Second
+	maxRestartDelay = time.Minute
+
+	// defaultPingTimeout is how long a process that failed to respond to a
+	// request is given to respond to a ping, before it is restarted.
+	defaultPingTimeout = 5 * time.Second
+)
+
+type Upstream struct {
+	dir          string
+	log          utils.Logger
+	restartDelay time.Duration
+	pingTimeout  time.Duration
+
+	mutex sync.Mutex
+	apps  map[apps.AppID]*appProcess
+}
+
+// appProcess tracks the process of an app across restarts.
+type appProcess struct {
+	key      string
+	proc     *process
+	failures int
+}
+
+var _ upstream.Upstream = (*Upstream)(nil)
+var _ upstream.AppStopper = (*Upstream)(nil)
+
+func MakeUpstream(log utils.Logger) (*Upstream, error) {
+	dir := os.Getenv(EnvExecDir)
+	if dir == "" {
+		return nil, utils.NewNotFoundError(EnvExecDir + " environment variable must be defined")
+	}
+	return NewUpstream(dir, log)
+}
+
+func NewUpstream(dir string, log utils.Logger) (*Upstream, error) {
+	if !filepath.IsAbs(dir) {
+		return nil, utils.NewInvalidError("exec apps directory must be an absolute path: %s", dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid exec apps directory")
+	}
+	if !info.IsDir() {
+		return nil, utils.NewInvalidError("exec apps directory is not a directory: %s", dir)
+	}
+	return &Upstream{
+		dir:          dir,
+		log:          log,
+		restartDelay: time.Second,
+		pingTimeout:  defaultPingTimeout,
+		apps:         map[apps.AppID]*appProcess{},
+	}, nil
+}
+
+func (u *Upstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
+	proc, err := u.getProcess(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if async {
+		go func() {
+			err := proc.notify(upstream.StreamRequest{
+				Type:        upstream.StreamMessageTypeNotify,
+				CallRequest: &creq,
+			}, app.Manifest.Exec.Timeout())
+			if err != nil {
+				u.log.WithError(err).Debugf("Failed to notify exec app %s.", app.AppID)
+			}
+		}()
+		return nil, nil
+	}
+
+	resp, err := proc.roundtrip(ctx, upstream.StreamRequest{
+		Type:        upstream.StreamMessageTypeCall,
+		CallRequest: &creq,
+	}, upstream.CallTimeout(ctx, app.Manifest.Exec.Timeout()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to invoke exec app")
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return io.NopCloser(bytes.NewReader(resp.CallResponse)), nil
+}
+
+func (u *Upstream) GetStatic(ctx context.Context, app apps.App, assetPath string) (io.ReadCloser, int, error) {
+	proc, err := u.getProcess(app)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	resp, err := proc.roundtrip(ctx, upstream.StreamRequest{
+		Type: upstream.StreamMessageTypeStatic,
+		Path: assetPath,
+	}, app.Manifest.Exec.Timeout())
+	switch {
+	case err != nil:
+		return nil, http.StatusBadGateway, errors.Wrapf(err, "failed to fetch: %s", assetPath)
+	case resp.NotFound:
+		return nil, http.StatusNotFound, utils.NewNotFoundError(assetPath)
+	case resp.Error != "":
+		return nil, http.StatusBadGateway, errors.Errorf("failed to fetch: %s: %s", assetPath, resp.Error)
+	}
+	return io.NopCloser(bytes.NewReader(resp.Data)), http.StatusOK, nil
+}
+
+// StopApp stops the app's process, if it is running.
+func (u *Upstream) StopApp(appID apps.AppID) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if ap := u.apps[appID]; ap != nil && ap.proc != nil {
+		ap.proc.stop()
+	}
+	delete(u.apps, appID)
+}
+
+// getProcess returns the running process of the app, starting it if needed.
+// The process is restarted if the app's version or command changed.
+func (u *Upstream) getProcess(app apps.App) (*process, error) {
+	if !app.Manifest.Contains(apps.DeployExec) {
+		return nil, errors.New("failed to start: no exec section in manifest.json")
+	}
+	e := app.Manifest.Exec
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	command := filepath.Join(u.dir, e.Command)
+	key := strings.Join(append([]string{string(app.Version), command}, e.Args...), "\x00")
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	ap := u.apps[app.AppID]
+	if ap == nil {
+		ap = &appProcess{}
+		u.apps[app.AppID] = ap
+	}
+
+	if ap.proc != nil {
+		switch {
+		case ap.key != key:
+			ap.proc.stop()
+			ap.failures = 0
+
+		case ap.proc.running():
+			return ap.proc, nil
+
+		default:
+			// The process exited on its own, or was killed.
+			failures := 0
+			if ap.proc.exited.Sub(ap.proc.started) < minUptime {
+				failures = ap.failures + 1
+			}
+			if wait := u.backoff(failures) - time.Since(ap.proc.exited); wait > 0 {
+				return nil, errors.Errorf("app process exited (%v), restarting in %s",
+					ap.proc.exitErr, wait.Round(time.Millisecond))
+			}
+			ap.failures = failures
+		}
+	}
+
+	info, err := os.Stat(command)
+	if err != nil {
+		return nil, utils.NewNotFoundError("executable %s", e.Command)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, utils.NewInvalidError("%s is not a regular file", e.Command)
+	}
+
+	proc, err := startProcess(u.dir, command, e.Args, u.pingTimeout, u.log.With("app_id", app.AppID))
+	if err != nil {
+		return nil, err
+	}
+	ap.key = key
+	ap.proc = proc
+	return proc, nil
+}
+
+func (u *Upstream) backoff(failures int) time.Duration {
+	if failures == 0 {
+		return 0
+	}
+	delay := u.restartDelay
+	for i := 1; i < failures && delay < maxRestartDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRestartDelay {
+		delay = maxRestartDelay
+	}
+	return delay
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upexec
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const testAppArg = "exec-test-app"
+
+// TestMain runs the test binary as an exec app, when it is invoked with
+// testAppArg.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == testAppArg {
+		runTestApp()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runTestApp() {
+	out := json.NewEncoder(os.Stdout)
+	lastNotified := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		req := upstream.StreamRequest{}
+		_ = json.Unmarshal(scanner.Bytes(), &req)
+		switch req.Type {
+		case upstream.StreamMessageTypeNotify:
+			lastNotified = req.CallRequest.Path
+
+		case upstream.StreamMessageTypePing:
+			_ = out.Encode(upstream.StreamResponse{ID: req.ID})
+
+		case upstream.StreamMessageTypeCall:
+			switch req.CallRequest.Path {
+			case "/crash":
+				os.Exit(1)
+			case "/hang":
+				continue
+			case "/freeze":
+				select {}
+			case "/notified":
+				data, _ := json.Marshal(apps.NewTextResponse(lastNotified))
+				_ = out.Encode(upstream.StreamResponse{ID: req.ID, CallResponse: data})
+			default:
+				data, _ := json.Marshal(apps.NewTextResponse("called %s", req.CallRequest.Path))
+				_ = out.Encode(upstream.StreamResponse{ID: req.ID, CallResponse: data})
+			}
+
+		case upstream.StreamMessageTypeStatic:
+			if req.Path == "icon.png" {
+				_ = out.Encode(upstream.StreamResponse{ID: req.ID, Data: []byte("icon")})
+			} else {
+				_ = out.Encode(upstream.StreamResponse{ID: req.ID, NotFound: true})
+			}
+		}
+	}
+}
+
+func TestUpstream(t *testing.T) {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	up, err := NewUpstream(filepath.Dir(executable), utils.NewTestLogger())
+	require.NoError(t, err)
+	up.restartDelay = 100 * time.Millisecond
+	up.pingTimeout = 200 * time.Millisecond
+	defer up.StopApp("test")
+
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID:   "test",
+			Version: "v1.0.0",
+			Deploy: apps.Deploy{
+				Exec: &apps.Exec{
+					Command:        filepath.Base(executable),
+					Args:           []string{testAppArg},
+					TimeoutSeconds: 1,
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	call := func(path string) (apps.CallResponse, error) {
+		return upstream.Call(ctx, up, app, apps.CallRequest{Call: *apps.NewCall(path)})
+	}
+
+	t.Run("call", func(t *testing.T) {
+		cresp, err := call("/hello")
+		require.NoError(t, err)
+		require.Equal(t, "called /hello", cresp.Text)
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		err := upstream.Notify(ctx, up, app, apps.CallRequest{Call: *apps.NewCall("/notify")})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			cresp, _ := call("/notified")
+			return cresp.Text == "/notify"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		body, code, err := up.GetStatic(ctx, app, "icon.png")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		data, _ := io.ReadAll(body)
+		require.Equal(t, "icon", string(data))
+
+		_, code, err = up.GetStatic(ctx, app, "missing.png")
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("restart on crash", func(t *testing.T) {
+		_, err := call("/crash")
+		require.Error(t, err)
+
+		// Restarted after the backoff.
+		require.Eventually(t, func() bool {
+			cresp, _ := call("/hello")
+			return cresp.Text == "called /hello"
+		}, 2*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("timeout fails only the call", func(t *testing.T) {
+		proc, err := up.getProcess(app)
+		require.NoError(t, err)
+
+		_, err = call("/hang")
+		require.EqualError(t, err, "failed to invoke exec app: app process did not respond in 1s")
+
+		// The process responds to the ping, and is kept running.
+		time.Sleep(2 * up.pingTimeout)
+		require.True(t, proc.running())
+		cresp, err := call("/hello")
+		require.NoError(t, err)
+		require.Equal(t, "called /hello", cresp.Text)
+	})
+
+	t.Run("restart when ping fails", func(t *testing.T) {
+		proc, err := up.getProcess(app)
+		require.NoError(t, err)
+
+		_, err = call("/freeze")
+		require.EqualError(t, err, "failed to invoke exec app: app process did not respond in 1s")
+
+		require.Eventually(t, func() bool {
+			return !proc.running()
+		}, time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool {
+			cresp, _ := call("/hello")
+			return cresp.Text == "called /hello"
+		}, 2*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		badApp := app
+		badApp.AppID = "bad"
+		badApp.Deploy = apps.Deploy{
+			Exec: &apps.Exec{
+				Command: "../sh",
+			},
+		}
+		_, err := up.Roundtrip(ctx, badApp, apps.CallRequest{}, false)
+		require.Error(t, err)
+	})
+}
+
+func TestProcessBlockedWrite(t *testing.T) {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	p, err := startProcess(filepath.Dir(executable), executable, []string{testAppArg}, time.Minute, utils.NewTestLogger())
+	require.NoError(t, err)
+	defer p.kill()
+	ctx := context.Background()
+
+	// The app stops reading its stdin.
+	_, err = p.request(ctx, upstream.StreamRequest{
+		Type:        upstream.StreamMessageTypeCall,
+		CallRequest: &apps.CallRequest{Call: *apps.NewCall("/freeze")},
+	}, 100*time.Millisecond)
+	require.Equal(t, errNoResponse, err)
+
+	// A request larger than the pipe buffer blocks the write, the request
+	// still times out.
+	start := time.Now()
+	_, err = p.request(ctx, upstream.StreamRequest{
+		Type: upstream.StreamMessageTypeCall,
+		CallRequest: &apps.CallRequest{
+			Call:   *apps.NewCall("/hello"),
+			Values: map[string]interface{}{"data": strings.Repeat("x", 1024*1024)},
+		},
+	}, 100*time.Millisecond)
+	require.Equal(t, errNoResponse, err)
+	require.Less(t, time.Since(start), time.Second)
+
+	// The next request times out before it is written.
+	_, err = p.request(ctx, upstream.StreamRequest{Type: upstream.StreamMessageTypePing}, 100*time.Millisecond)
+	require.Equal(t, errNoResponse, err)
+
+	// stop does not wait for the blocked write.
+	stopped := make(chan struct{})
+	go func() {
+		p.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		require.Fail(t, "stop blocked on a pending write")
+	}
+}
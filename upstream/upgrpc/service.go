@@ -0,0 +1,205 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// The client and server bindings below are the equivalent of the code
+// generated by protoc-gen-go-grpc from app.proto. The service only uses
+// well-known message types, so no generated message code is needed.
+
+const (
+	ServiceName = "mattermost.apps.v1.App"
+
+	CallMethod      = "/" + ServiceName + "/Call"
+	NotifyMethod    = "/" + ServiceName + "/Notify"
+	GetStaticMethod = "/" + ServiceName + "/GetStatic"
+
+	// AuthMetadataKey is the request metadata key that carries the JWT for
+	// apps with UseToken set. Its value is "Bearer {token}".
+	AuthMetadataKey = "mattermost-app-authorization"
+)
+
+// AppClient is the client API for the mattermost.apps.v1.App service.
+type AppClient interface {
+	Call(ctx context.Context, in *wrapperspb.BytesValue, opts ...grpc.CallOption) (*wrapperspb.BytesValue, error)
+	Notify(ctx context.Context, in *wrapperspb.BytesValue, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetStatic(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (GetStaticClient, error)
+}
+
+// GetStaticClient receives the chunks of a static asset.
+type GetStaticClient interface {
+	Recv() (*wrapperspb.BytesValue, error)
+	grpc.ClientStream
+}
+
+type appClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAppClient(cc grpc.ClientConnInterface) AppClient {
+	return &appClient{cc}
+}
+
+func (c *appClient) Call(ctx context.Context, in *wrapperspb.BytesValue, opts ...grpc.CallOption) (*wrapperspb.BytesValue, error) {
+	out := new(wrapperspb.BytesValue)
+	err := c.cc.Invoke(ctx, CallMethod, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appClient) Notify(ctx context.Context, in *wrapperspb.BytesValue, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, NotifyMethod, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appClient) GetStatic(ctx context.Context, in *wrapperspb.StringValue, opts ...grpc.CallOption) (GetStaticClient, error) {
+	stream, err := c.cc.NewStream(ctx, &appServiceDesc.Streams[0], GetStaticMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &getStaticClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type getStaticClient struct {
+	grpc.ClientStream
+}
+
+func (x *getStaticClient) Recv() (*wrapperspb.BytesValue, error) {
+	m := new(wrapperspb.BytesValue)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AppServer is the server API for the mattermost.apps.v1.App service, to be
+// implemented by gRPC apps written in Go.
+type AppServer interface {
+	Call(context.Context, *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error)
+	Notify(context.Context, *wrapperspb.BytesValue) (*emptypb.Empty, error)
+	GetStatic(*wrapperspb.StringValue, GetStaticServer) error
+}
+
+// GetStaticServer sends the chunks of a static asset.
+type GetStaticServer interface {
+	Send(*wrapperspb.BytesValue) error
+	grpc.ServerStream
+}
+
+// UnimplementedAppServer can be embedded in AppServer implementations that do
+// not serve all methods.
+type UnimplementedAppServer struct{}
+
+func (UnimplementedAppServer) Call(context.Context, *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Call not implemented")
+}
+
+func (UnimplementedAppServer) Notify(context.Context, *wrapperspb.BytesValue) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Notify not implemented")
+}
+
+func (UnimplementedAppServer) GetStatic(*wrapperspb.StringValue, GetStaticServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetStatic not implemented")
+}
+
+func RegisterAppServer(s grpc.ServiceRegistrar, srv AppServer) {
+	s.RegisterService(&appServiceDesc, srv)
+}
+
+func callHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrapperspb.BytesValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CallMethod,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServer).Call(ctx, req.(*wrapperspb.BytesValue))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func notifyHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrapperspb.BytesValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServer).Notify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotifyMethod,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServer).Notify(ctx, req.(*wrapperspb.BytesValue))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func getStaticHandler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(wrapperspb.StringValue)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AppServer).GetStatic(m, &getStaticServer{stream})
+}
+
+type getStaticServer struct {
+	grpc.ServerStream
+}
+
+func (x *getStaticServer) Send(m *wrapperspb.BytesValue) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var appServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AppServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Call",
+			Handler:    callHandler,
+		},
+		{
+			MethodName: "Notify",
+			Handler:    notifyHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetStatic",
+			Handler:       getStaticHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "upstream/upgrpc/app.proto",
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upgrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// NotifyTimeout limits how long an asynchronous notification may take, the
+// same as the plugin's default request timeout.
+const NotifyTimeout = 30 * time.Second
+
+type Upstream struct {
+	dialOptions []grpc.DialOption
+
+	mutex sync.Mutex
+	conns map[apps.AppID]*appConn
+}
+
+// appConn is the cached connection to an app, along with the manifest
+// settings it was made with, to reconnect when they change.
+type appConn struct {
+	grpc apps.GRPC
+	conn *grpc.ClientConn
+}
+
+var _ upstream.Upstream = (*Upstream)(nil)
+var _ upstream.AppStopper = (*Upstream)(nil)
+
+// NewUpstream returns an upstream for gRPC apps. A connection to each app is
+// established on first use, and reused until the app is stopped. dialOptions
+// are added to the ones derived from the app's manifest.
+func NewUpstream(dialOptions ...grpc.DialOption) *Upstream {
+	return &Upstream{
+		dialOptions: dialOptions,
+		conns:       map[apps.AppID]*appConn{},
+	}
+}
+
+func (u *Upstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
+	if async {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), NotifyTimeout)
+			defer cancel()
+			_ = u.notify(ctx, creq.Context.ExpandedContext.BotUserID, app, creq)
+		}()
+		return nil, nil
+	}
+
+	var actingUserID string
+	if creq.Context.ExpandedContext.ActingUser != nil {
+		actingUserID = creq.Context.ExpandedContext.ActingUser.Id
+	}
+
+	data, err := u.call(ctx, actingUserID, app, creq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to invoke via gRPC")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (u *Upstream) call(ctx context.Context, fromMattermostUserID string, app apps.App, creq apps.CallRequest) ([]byte, error) {
+	data, err := json.Marshal(creq)
+	if err != nil {
+		return nil, err
+	}
+	conn, ctx, err := u.dial(ctx, fromMattermostUserID, app)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := NewAppClient(conn).Call(ctx, wrapperspb.Bytes(data))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return out.GetValue(), nil
+}
+
+func (u *Upstream) notify(ctx context.Context, fromMattermostUserID string, app apps.App, creq apps.CallRequest) error {
+	data, err := json.Marshal(creq)
+	if err != nil {
+		return err
+	}
+	conn, ctx, err := u.dial(ctx, fromMattermostUserID, app)
+	if err != nil {
+		return err
+	}
+
+	_, err = NewAppClient(conn).Notify(ctx, wrapperspb.Bytes(data))
+	return statusError(err)
+}
+
+func (u *Upstream) GetStatic(ctx context.Context, app apps.App, assetPath string) (io.ReadCloser, int, error) {
+	if !app.Manifest.Contains(apps.DeployGRPC) {
+		return nil, http.StatusInternalServerError, errors.New("app is not available as type grpc")
+	}
+	conn, ctx, err := u.dial(ctx, "", app)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := NewAppClient(conn).GetStatic(ctx, wrapperspb.String(assetPath))
+	if err != nil {
+		cancel()
+		return nil, http.StatusBadGateway, errors.Wrapf(err, "failed to fetch: %s", assetPath)
+	}
+
+	// Receive the first chunk before returning, so that a missing asset is
+	// reported with the right status.
+	first, err := stream.Recv()
+	switch {
+	case err == io.EOF:
+		cancel()
+		return io.NopCloser(&bytes.Buffer{}), http.StatusOK, nil
+
+	case status.Code(err) == codes.NotFound:
+		cancel()
+		return nil, http.StatusNotFound, utils.NewNotFoundError(assetPath)
+
+	case err != nil:
+		cancel()
+		return nil, http.StatusBadGateway, errors.Wrapf(statusError(err), "failed to fetch: %s", assetPath)
+	}
+
+	return &staticReader{
+		cancel: cancel,
+		stream: stream,
+		buf:    first.GetValue(),
+	}, http.StatusOK, nil
+}
+
+// StopApp closes the cached connection to the app, if any.
+func (u *Upstream) StopApp(appID apps.AppID) {
+	u.mutex.Lock()
+	c := u.conns[appID]
+	delete(u.conns, appID)
+	u.mutex.Unlock()
+
+	if c != nil {
+		_ = c.conn.Close()
+	}
+}
+
+// dial returns the connection to the app, and the context to make requests
+// with. The context carries the JWT if the app requires one.
+func (u *Upstream) dial(ctx context.Context, fromMattermostUserID string, app apps.App) (*grpc.ClientConn, context.Context, error) {
+	if !app.Manifest.Contains(apps.DeployGRPC) {
+		return nil, nil, errors.New("failed to connect: no grpc section in manifest.json")
+	}
+	g := app.Manifest.GRPC
+
+	if g.UseToken {
+		token, err := createJWT(fromMattermostUserID, app.Secret)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, AuthMetadataKey, "Bearer "+token)
+	}
+
+	conn, err := u.getConn(app.AppID, *g)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, ctx, nil
+}
+
+// getConn returns the cached connection to the app, or makes a new one if
+// there is none, or the app's gRPC settings have changed. The connection is
+// not established until it is used.
+func (u *Upstream) getConn(appID apps.AppID, g apps.GRPC) (*grpc.ClientConn, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if c := u.conns[appID]; c != nil {
+		if c.grpc == g {
+			return c.conn, nil
+		}
+		_ = c.conn.Close()
+		delete(u.conns, appID)
+	}
+
+	opts := []grpc.DialOption{}
+	if g.TLS {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			ServerName: g.TLSServerName,
+			MinVersion: tls.VersionTLS12,
+		})))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	opts = append(opts, u.dialOptions...)
+
+	conn, err := grpc.Dial(g.Address, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to %s", g.Address)
+	}
+	u.conns[appID] = &appConn{
+		grpc: g,
+		conn: conn,
+	}
+	return conn, nil
+}
+
+// statusError converts gRPC status codes to the errors used in the rest of the
+// plugin.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.NotFound:
+		return utils.NewNotFoundError("%s", st.Message())
+	case codes.InvalidArgument:
+		return utils.NewInvalidError("%s", st.Message())
+	case codes.PermissionDenied:
+		return utils.NewForbiddenError("%s", st.Message())
+	case codes.Unauthenticated:
+		return utils.NewUnauthorizedError("%s", st.Message())
+	default:
+		return errors.Errorf("%s: %s", st.Code(), st.Message())
+	}
+}
+
+// staticReader reads the remaining chunks of a static asset from the stream,
+// and cancels the stream when closed.
+type staticReader struct {
+	cancel context.CancelFunc
+	stream GetStaticClient
+	buf    []byte
+	err    error
+}
+
+func (r *staticReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			if err != io.EOF {
+				err = statusError(err)
+			}
+			r.err = err
+			continue
+		}
+		r.buf = chunk.GetValue()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *staticReader) Close() error {
+	r.cancel()
+	return nil
+}
+
+func createJWT(actingUserID, secret string) (string, error) {
+	claims := apps.JWTClaims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(time.Minute * 15).Unix(),
+		},
+		ActingUserID: actingUserID,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+)
+
+type testServer struct {
+	UnimplementedAppServer
+
+	notified chan apps.CallRequest
+	token    string
+}
+
+func (s *testServer) Call(ctx context.Context, in *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(AuthMetadataKey); len(v) > 0 {
+		s.token = strings.TrimPrefix(v[0], "Bearer ")
+	}
+
+	creq := apps.CallRequest{}
+	if err := json.Unmarshal(in.GetValue(), &creq); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if creq.Path == "/missing" {
+		return nil, status.Error(codes.NotFound, "no such call")
+	}
+	data, _ := json.Marshal(apps.NewTextResponse("called %s", creq.Path))
+	return wrapperspb.Bytes(data), nil
+}
+
+func (s *testServer) Notify(_ context.Context, in *wrapperspb.BytesValue) (*emptypb.Empty, error) {
+	creq := apps.CallRequest{}
+	_ = json.Unmarshal(in.GetValue(), &creq)
+	s.notified <- creq
+	return &emptypb.Empty{}, nil
+}
+
+func (s *testServer) GetStatic(in *wrapperspb.StringValue, stream GetStaticServer) error {
+	if in.GetValue() != "icon.png" {
+		return status.Error(codes.NotFound, in.GetValue())
+	}
+	for _, chunk := range []string{"chunk1", "chunk2", "chunk3"} {
+		if err := stream.Send(wrapperspb.Bytes([]byte(chunk))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestUpstream(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := &testServer{
+		notified: make(chan apps.CallRequest, 1),
+	}
+	s := grpc.NewServer()
+	RegisterAppServer(s, srv)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	var dialed int32
+	up := NewUpstream(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		atomic.AddInt32(&dialed, 1)
+		return lis.DialContext(ctx)
+	}))
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "test",
+			Deploy: apps.Deploy{
+				GRPC: &apps.GRPC{
+					Address:  "app.test:9000",
+					UseToken: true,
+				},
+			},
+		},
+		Secret: "1234",
+	}
+	ctx := context.Background()
+
+	t.Run("call", func(t *testing.T) {
+		creq := apps.CallRequest{
+			Call: *apps.NewCall("/hello"),
+		}
+		creq.Context.ExpandedContext.ActingUser = &model.User{Id: "user-id"}
+
+		cresp, err := upstream.Call(ctx, up, app, creq)
+		require.NoError(t, err)
+		require.Equal(t, "called /hello", cresp.Text)
+
+		claims := apps.JWTClaims{}
+		_, err = jwt.ParseWithClaims(srv.token, &claims, func(*jwt.Token) (interface{}, error) {
+			return []byte("1234"), nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "user-id", claims.ActingUserID)
+	})
+
+	t.Run("call not found", func(t *testing.T) {
+		_, err := up.Roundtrip(ctx, app, apps.CallRequest{Call: *apps.NewCall("/missing")}, false)
+		require.EqualError(t, err, "failed to invoke via gRPC: no such call: not found")
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		err := upstream.Notify(ctx, up, app, apps.CallRequest{Call: *apps.NewCall("/notify")})
+		require.NoError(t, err)
+		creq := <-srv.notified
+		require.Equal(t, "/notify", creq.Path)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		body, code, err := up.GetStatic(ctx, app, "icon.png")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.NoError(t, body.Close())
+		require.Equal(t, "chunk1chunk2chunk3", string(data))
+	})
+
+	t.Run("static not found", func(t *testing.T) {
+		_, code, err := up.GetStatic(ctx, app, "missing.png")
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("connection is reused until stopped", func(t *testing.T) {
+		require.Equal(t, int32(1), atomic.LoadInt32(&dialed))
+		conn := up.conns[app.AppID].conn
+
+		up.StopApp(app.AppID)
+		require.Empty(t, up.conns)
+		require.Equal(t, connectivity.Shutdown, conn.GetState())
+
+		_, err := upstream.Call(ctx, up, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), atomic.LoadInt32(&dialed))
+	})
+}
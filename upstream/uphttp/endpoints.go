@@ -0,0 +1,215 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package uphttp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+)
+
+// DefaultEjectDuration is how long a root URL that failed is skipped for.
+const DefaultEjectDuration = 30 * time.Second
+
+// Endpoints keeps the health of the root URLs of the HTTP apps that have
+// several, and selects the root URLs to send the requests to. It is local to
+// the Mattermost server node, and is shared by the upstream instances so that
+// the health survives re-configuration.
+type Endpoints struct {
+	mutex sync.Mutex
+	apps  map[apps.AppID]*appEndpoints
+
+	ejectDuration time.Duration
+	now           func() time.Time
+}
+
+type appEndpoints struct {
+	// next is the round-robin counter.
+	next   int
+	status map[string]*upstream.EndpointStatus
+}
+
+func NewEndpoints() *Endpoints {
+	return &Endpoints{
+		apps:          map[apps.AppID]*appEndpoints{},
+		ejectDuration: DefaultEjectDuration,
+		now:           time.Now,
+	}
+}
+
+// hasFailover returns true if the app has more than one root URL.
+func hasFailover(app apps.App) bool {
+	return app.Manifest.Contains(apps.DeployHTTP) && len(app.Manifest.HTTP.RootURLs) > 0
+}
+
+// Order returns the app's root URLs in the order they should be tried. The
+// healthy root URLs come first, ordered by the app's RootURLStrategy, followed
+// by the ejected ones as the last resort.
+func (e *Endpoints) Order(app apps.App) []string {
+	all := app.Manifest.HTTP.AllRootURLs()
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	ae := e.getLocked(app.AppID)
+	now := e.now()
+
+	var healthy, ejected []string
+	for _, rootURL := range all {
+		if s := ae.status[rootURL]; s != nil && now.Before(s.EjectedUntil) {
+			ejected = append(ejected, rootURL)
+		} else {
+			healthy = append(healthy, rootURL)
+		}
+	}
+
+	if len(healthy) > 1 && app.Manifest.HTTP.RootURLStrategy != apps.RootURLStrategyPrimary {
+		start := ae.next % len(healthy)
+		ae.next++
+		healthy = append(healthy[start:], healthy[:start]...)
+	}
+	return append(healthy, ejected...)
+}
+
+// Record updates the health of the app's root URL with the outcome of a
+// request to it. A nil error marks the root URL healthy, any other ejects it.
+func (e *Endpoints) Record(appID apps.AppID, rootURL string, err error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	ae := e.getLocked(appID)
+	s := ae.status[rootURL]
+	if s == nil {
+		s = &upstream.EndpointStatus{URL: rootURL}
+		ae.status[rootURL] = s
+	}
+
+	if err == nil {
+		*s = upstream.EndpointStatus{URL: rootURL}
+		return
+	}
+	s.Failures++
+	s.LastError = err.Error()
+	s.EjectedUntil = e.now().Add(e.ejectDuration)
+}
+
+// Status returns the health of each of the app's root URLs, nil if the app has
+// a single root URL.
+func (e *Endpoints) Status(app apps.App) []upstream.EndpointStatus {
+	if !hasFailover(app) {
+		return nil
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	ae := e.getLocked(app.AppID)
+	now := e.now()
+
+	var out []upstream.EndpointStatus
+	for _, rootURL := range app.Manifest.HTTP.AllRootURLs() {
+		s := upstream.EndpointStatus{URL: rootURL}
+		if recorded := ae.status[rootURL]; recorded != nil {
+			s = *recorded
+		}
+		s.Healthy = !now.Before(s.EjectedUntil)
+		if s.Healthy {
+			s.EjectedUntil = time.Time{}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// Reset forgets the health of the app's root URLs.
+func (e *Endpoints) Reset(appID apps.AppID) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.apps, appID)
+}
+
+func (e *Endpoints) getLocked(appID apps.AppID) *appEndpoints {
+	ae := e.apps[appID]
+	if ae == nil {
+		ae = &appEndpoints{
+			status: map[string]*upstream.EndpointStatus{},
+		}
+		e.apps[appID] = ae
+	}
+	return ae
+}
+
+// rootURLs returns the root URLs to try for the request, in order.
+func (u *Upstream) rootURLs(app apps.App, path string) ([]string, error) {
+	if u.endpoints != nil && hasFailover(app) {
+		return u.endpoints.Order(app), nil
+	}
+	rootURL, err := u.appRootURL(app, path)
+	if err != nil {
+		return nil, err
+	}
+	return []string{rootURL}, nil
+}
+
+// recordEndpoint updates the health of the root URL that served (or failed)
+// the request. Transport errors and 5xx responses count as failures, other
+// responses indicate that the root URL is up.
+func (u *Upstream) recordEndpoint(ctx context.Context, app apps.App, rootURL string, resp *http.Response, err error) {
+	if u.endpoints == nil || !hasFailover(app) {
+		return
+	}
+	switch {
+	case err != nil:
+		if ctx.Err() != nil {
+			// The request was canceled by the caller, not the root URL's fault.
+			return
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		err = errors.Errorf("%s: %s", rootURL, resp.Status)
+	}
+	u.endpoints.Record(app.AppID, rootURL, err)
+}
+
+// shouldFailover returns true if the request can safely be retried with
+// another root URL, that is if it has not reached the app, or the app refused
+// to serve it.
+func shouldFailover(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	return resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// PingEndpoints pings each of the app's root URLs, and returns their health.
+// It returns nil if the app has a single root URL.
+func (u *Upstream) PingEndpoints(ctx context.Context, app apps.App) []upstream.EndpointStatus {
+	if u.endpoints == nil || !hasFailover(app) {
+		return nil
+	}
+
+	creq := apps.CallRequest{Call: apps.DefaultPing}
+	wg := sync.WaitGroup{}
+	for _, rootURL := range app.Manifest.HTTP.AllRootURLs() {
+		wg.Add(1)
+		go func(rootURL string) {
+			defer wg.Done()
+			resp, _, err := u.invokeRootURL(ctx, rootURL, "", app, creq)
+			u.recordEndpoint(ctx, app, rootURL, resp, err)
+			if resp != nil {
+				resp.Body.Close()
+			}
+		}(rootURL)
+	}
+	wg.Wait()
+
+	return u.endpoints.Status(app)
+}
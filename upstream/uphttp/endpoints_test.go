@@ -0,0 +1,133 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package uphttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/httpout"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+)
+
+func newFailoverTestApp(strategy string, rootURLs ...string) apps.App {
+	return apps.App{
+		Manifest: apps.Manifest{
+			AppID: "test",
+			Deploy: apps.Deploy{
+				HTTP: &apps.HTTP{
+					RootURL:         rootURLs[0],
+					RootURLs:        rootURLs[1:],
+					RootURLStrategy: strategy,
+				},
+			},
+		},
+	}
+}
+
+func TestEndpointsOrder(t *testing.T) {
+	now := time.Now()
+	e := NewEndpoints()
+	e.now = func() time.Time { return now }
+
+	t.Run("round robin", func(t *testing.T) {
+		app := newFailoverTestApp("", "a", "b", "c")
+		require.Equal(t, []string{"a", "b", "c"}, e.Order(app))
+		require.Equal(t, []string{"b", "c", "a"}, e.Order(app))
+		require.Equal(t, []string{"c", "a", "b"}, e.Order(app))
+	})
+
+	t.Run("primary", func(t *testing.T) {
+		app := newFailoverTestApp(apps.RootURLStrategyPrimary, "a", "b", "c")
+		require.Equal(t, []string{"a", "b", "c"}, e.Order(app))
+		require.Equal(t, []string{"a", "b", "c"}, e.Order(app))
+	})
+
+	t.Run("ejected last, then restored", func(t *testing.T) {
+		app := newFailoverTestApp(apps.RootURLStrategyPrimary, "a", "b", "c")
+		e.Record(app.AppID, "a", context.DeadlineExceeded)
+		require.Equal(t, []string{"b", "c", "a"}, e.Order(app))
+
+		status := e.Status(app)
+		require.False(t, status[0].Healthy)
+		require.Equal(t, 1, status[0].Failures)
+		require.Equal(t, now.Add(DefaultEjectDuration), status[0].EjectedUntil)
+		require.True(t, status[1].Healthy)
+
+		now = now.Add(DefaultEjectDuration)
+		require.Equal(t, []string{"a", "b", "c"}, e.Order(app))
+
+		e.Record(app.AppID, "a", context.DeadlineExceeded)
+		e.Record(app.AppID, "a", nil)
+		require.Equal(t, []string{"a", "b", "c"}, e.Order(app))
+		require.Equal(t, upstream.EndpointStatus{URL: "a", Healthy: true}, e.Status(app)[0])
+	})
+
+	t.Run("single root URL", func(t *testing.T) {
+		require.Nil(t, e.Status(newFailoverTestApp("", "a")))
+	})
+}
+
+func TestFailover(t *testing.T) {
+	var mutex sync.Mutex
+	var served []string
+	newServer := func(name string, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			served = append(served, name)
+			mutex.Unlock()
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(apps.NewTextResponse(name))
+		}))
+	}
+
+	down := newServer("down", http.StatusOK)
+	down.Close()
+	unavailable := newServer("unavailable", http.StatusServiceUnavailable)
+	defer unavailable.Close()
+	up := newServer("up", http.StatusOK)
+	defer up.Close()
+
+	conf := config.NewTestConfigService(nil).WithMattermostConfig(model.Config{})
+	endpoints := NewEndpoints()
+	u := NewUpstream(httpout.NewService(conf), true, nil).WithEndpoints(endpoints)
+	app := newFailoverTestApp(apps.RootURLStrategyPrimary, down.URL, unavailable.URL, up.URL)
+
+	cresp, err := upstream.Call(context.Background(), u, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+	require.NoError(t, err)
+	require.Equal(t, "up", cresp.Text)
+	require.Equal(t, []string{"unavailable", "up"}, served)
+	require.Equal(t, []string{up.URL, down.URL, unavailable.URL}, endpoints.Order(app))
+
+	// The ejected root URLs are still pinged.
+	served = nil
+	status := u.PingEndpoints(context.Background(), app)
+	require.Len(t, status, 3)
+	require.False(t, status[0].Healthy)
+	require.False(t, status[1].Healthy)
+	require.Equal(t, 2, status[1].Failures)
+	require.True(t, status[2].Healthy)
+	require.ElementsMatch(t, []string{"unavailable", "up"}, served)
+
+	t.Run("no failover without Endpoints", func(t *testing.T) {
+		u := NewUpstream(httpout.NewService(conf), true, nil)
+		_, err := upstream.Call(context.Background(), u, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+		require.Error(t, err)
+		require.Nil(t, u.PingEndpoints(context.Background(), app))
+	})
+}
@@ -13,31 +13,70 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	"github.com/mattermost/mattermost-plugin-apps/apps/path"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
+// StaticPath is passed to the root URL function of the upstream to find the
+// root URL that serves the app's static assets.
+const StaticPath = "/" + path.StaticFolder
+
 func (u *Upstream) GetStatic(ctx context.Context, app apps.App, urlPath string) (io.ReadCloser, int, error) {
-	if !app.Manifest.Contains(apps.DeployHTTP) {
-		return nil, http.StatusInternalServerError, errors.New("app is not available as type http")
-	}
-	rootURL, err := u.appRootURL(app, "/")
+	rootURLs, err := u.rootURLs(app, StaticPath)
 	if err != nil {
 		return nil, http.StatusNotFound, err
 	}
-	url, err := utils.CleanURL(fmt.Sprintf("%s/%s/%s", rootURL, path.StaticFolder, urlPath))
+
+	var resp *http.Response
+	var url string
+	for i, rootURL := range rootURLs {
+		var status int
+		resp, url, status, err = u.getStaticRootURL(ctx, rootURL, app, urlPath)
+		if err != nil && status != http.StatusBadGateway {
+			return nil, status, err
+		}
+		u.recordEndpoint(ctx, app, rootURL, resp, err)
+		if i == len(rootURLs)-1 || !shouldFailover(ctx, resp, err) {
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
 	if err != nil {
-		return nil, http.StatusBadRequest, err
+		return nil, http.StatusBadGateway, errors.Wrapf(err, "failed to fetch: %s, error: %v", url, err)
+	}
+	return upstream.NewStaticAsset(resp.Body, resp.Header), resp.StatusCode, nil
+}
+
+// getStaticRootURL requests the static asset from the app at rootURL. The
+// returned status code is set when the request fails.
+func (u *Upstream) getStaticRootURL(ctx context.Context, rootURL string, app apps.App, urlPath string) (*http.Response, string, int, error) {
+	url, err := utils.CleanURL(fmt.Sprintf("%s/%s/%s", rootURL, path.StaticFolder, utils.EscapePath(urlPath)))
+	if err != nil {
+		return nil, "", http.StatusBadRequest, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, http.StatusInternalServerError, err
+		return nil, url, http.StatusInternalServerError, err
+	}
+	if u.authorize != nil {
+		if err = u.authorize(req, app); err != nil {
+			return nil, url, http.StatusInternalServerError, err
+		}
 	}
+	upstream.SetConditionalHeaders(ctx, req)
+	tracing.Inject(ctx, req.Header)
 
-	client := u.httpOut.MakeClient(u.devMode)
+	client, err := u.makeClient(app)
+	if err != nil {
+		return nil, url, http.StatusInternalServerError, err
+	}
 	resp, err := client.Do(req) // nolint:bodyclose,gosec // Ignore gosec G107
 	if err != nil {
-		return nil, http.StatusBadGateway, errors.Wrapf(err, "failed to fetch: %s, error: %v", url, err)
+		return nil, url, http.StatusBadGateway, err
 	}
-	return resp.Body, resp.StatusCode, nil
+	return resp, url, 0, nil
 }
@@ -0,0 +1,80 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package uphttp
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// ClientKeys provides the private keys of the apps' TLS client certificates,
+// see apps.TLSConfig.ClientKey.
+type ClientKeys interface {
+	GetAppClientKey(apps.AppID) (string, error)
+}
+
+// tlsConfigCache keeps the parsed TLS configurations of the apps, so that the
+// certificates are not parsed for every request.
+type tlsConfigCache struct {
+	mutex   sync.Mutex
+	configs map[apps.AppID]cachedTLSConfig
+}
+
+type cachedTLSConfig struct {
+	source apps.TLSConfig
+	parsed *tls.Config
+}
+
+// get returns the parsed TLS configuration of the app. The client key is
+// loaded from clientKeys only when the configuration changes, a new key always
+// comes with a new certificate.
+func (c *tlsConfigCache) get(app apps.App, clientKeys ClientKeys) (*tls.Config, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if cached, ok := c.configs[app.AppID]; ok && cached.source == *app.TLS {
+		return cached.parsed, nil
+	}
+
+	withKey := *app.TLS
+	if withKey.ClientCertificate != "" && withKey.ClientKey == "" && clientKeys != nil {
+		key, err := clientKeys.GetAppClientKey(app.AppID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the TLS client key for app %s", app.AppID)
+		}
+		withKey.ClientKey = key
+	}
+	parsed, err := withKey.ClientTLSConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid TLS configuration for app %s", app.AppID)
+	}
+	if c.configs == nil {
+		c.configs = map[apps.AppID]cachedTLSConfig{}
+	}
+	c.configs[app.AppID] = cachedTLSConfig{
+		source: *app.TLS,
+		parsed: parsed,
+	}
+	return parsed, nil
+}
+
+// makeClient returns the HTTP client to access the app with, using the app's
+// TLS configuration if it has one.
+func (u *Upstream) makeClient(app apps.App) (*http.Client, error) {
+	if u.allowHost != nil {
+		return u.httpOut.MakeInternalClient(u.allowHost), nil
+	}
+	if app.TLS.IsEmpty() {
+		return u.httpOut.MakeClient(u.devMode), nil
+	}
+	tlsConfig, err := u.tlsConfigs.get(app, u.clientKeys)
+	if err != nil {
+		return nil, err
+	}
+	return u.httpOut.MakeTLSClient(u.devMode, tlsConfig), nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package uphttp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/httpout"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type testClientKeys map[apps.AppID]string
+
+func (k testClientKeys) GetAppClientKey(appID apps.AppID) (string, error) {
+	key, ok := k[appID]
+	if !ok {
+		return "", utils.NewNotFoundError("no TLS client key for %s", appID)
+	}
+	return key, nil
+}
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM string
+	keyPEM  string
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	serverCert := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "app.internal"},
+		DNSNames:    []string{"app.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	clientCert := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "mattermost"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(w).Encode(apps.NewTextResponse("hello %s", req.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	serverTLSCert, err := tls.X509KeyPair([]byte(serverCert.certPEM), []byte(serverCert.keyPEM))
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverTLSCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	conf := config.NewTestConfigService(nil).WithMattermostConfig(model.Config{})
+	up := NewUpstream(httpout.NewService(conf), true, nil)
+
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "test",
+			Deploy: apps.Deploy{
+				HTTP: &apps.HTTP{
+					RootURL: server.URL,
+				},
+			},
+		},
+	}
+	call := func(tlsConfig *apps.TLSConfig) (apps.CallResponse, error) {
+		app.TLS = tlsConfig
+		return upstream.Call(context.Background(), up, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+	}
+
+	t.Run("no TLS configuration", func(t *testing.T) {
+		_, err := call(nil)
+		require.Error(t, err)
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		_, err := call(&apps.TLSConfig{
+			RootCAs:    ca.certPEM,
+			ServerName: "app.internal",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("wrong server name", func(t *testing.T) {
+		_, err := call(&apps.TLSConfig{
+			ClientCertificate: clientCert.certPEM,
+			ClientKey:         clientCert.keyPEM,
+			RootCAs:           ca.certPEM,
+			ServerName:        "other.internal",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "certificate is valid for app.internal, not other.internal")
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		cresp, err := call(&apps.TLSConfig{
+			ClientCertificate: clientCert.certPEM,
+			ClientKey:         clientCert.keyPEM,
+			RootCAs:           ca.certPEM,
+			ServerName:        "app.internal",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "hello mattermost", cresp.Text)
+	})
+
+	t.Run("mutual TLS with the stored client key", func(t *testing.T) {
+		up.WithClientKeys(testClientKeys{})
+		defer up.WithClientKeys(nil)
+		_, err := call(&apps.TLSConfig{
+			ClientCertificate: clientCert.certPEM,
+			RootCAs:           ca.certPEM,
+			ServerName:        "app.internal",
+		})
+		require.EqualError(t, err, "failed to invoke via HTTP: failed to load the TLS client key for app test: no TLS client key for test: not found")
+
+		up.WithClientKeys(testClientKeys{"test": clientCert.keyPEM})
+		cresp, err := call(&apps.TLSConfig{
+			ClientCertificate: clientCert.certPEM,
+			RootCAs:           ca.certPEM,
+			ServerName:        "app.internal",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "hello mattermost", cresp.Text)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := call(&apps.TLSConfig{
+			ClientCertificate: clientCert.certPEM,
+		})
+		require.EqualError(t, err, "failed to invoke via HTTP: invalid TLS configuration for app test: client_certificate and client_key must be set together: invalid input")
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package uphttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/config"
+	"github.com/mattermost/mattermost-plugin-apps/server/httpout"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
+)
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(prev)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		traceparent = req.Header.Get("traceparent")
+		_ = json.NewEncoder(w).Encode(apps.NewTextResponse("hello"))
+	}))
+	defer server.Close()
+
+	conf := config.NewTestConfigService(nil).WithMattermostConfig(model.Config{})
+	up := upstream.Chain(NewUpstream(httpout.NewService(conf), true, nil), upstream.WithTracing())
+	app := apps.App{
+		DeployType: apps.DeployHTTP,
+		Manifest: apps.Manifest{
+			AppID: "test",
+			Deploy: apps.Deploy{
+				HTTP: &apps.HTTP{
+					RootURL: server.URL,
+				},
+			},
+		},
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	_, err := upstream.Call(ctx, up, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	roundtrip := spans[0]
+	require.Equal(t, "Upstream.Roundtrip", roundtrip.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), roundtrip.Parent().SpanID())
+	require.Contains(t, roundtrip.Attributes(), tracing.AppIDKey.String("test"))
+	require.Contains(t, roundtrip.Attributes(), tracing.CallPathKey.String("/hello"))
+
+	require.Equal(t, "00-"+roundtrip.SpanContext().TraceID().String()+"-"+roundtrip.SpanContext().SpanID().String()+"-01", traceparent)
+}
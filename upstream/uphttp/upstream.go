@@ -19,15 +19,31 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 type Upstream struct {
 	httpOut    httpout.Service
 	appRootURL func(_ apps.App, path string) (string, error)
+	authorize  func(*http.Request, apps.App) error
+	signer     JWTSigner
+	clientKeys ClientKeys
+	tlsConfigs tlsConfigCache
+	endpoints  *Endpoints
 	devMode    bool
+	allowHost  func(host string) bool
+}
+
+// JWTSigner signs the outgoing JWTs with the keys held by the Mattermost
+// server, for the apps that do not use their secret, see
+// apps.HTTP.JWTSigningMethod. The signer sets the issuer of the claims, their
+// audience must be set to the app.
+type JWTSigner interface {
+	SignJWT(alg string, claims apps.JWTClaims) (string, error)
 }
 
 var _ upstream.Upstream = (*Upstream)(nil)
+var _ upstream.EndpointPinger = (*Upstream)(nil)
 
 func NewUpstream(httpOut httpout.Service, devMode bool, appRootURL func(apps.App, string) (string, error)) *Upstream {
 	if appRootURL == nil {
@@ -40,6 +56,41 @@ func NewUpstream(httpOut httpout.Service, devMode bool, appRootURL func(apps.App
 	}
 }
 
+// WithAuthorization sets authorize to be invoked on every outgoing request to
+// the app, to add the credentials.
+func (u *Upstream) WithAuthorization(authorize func(*http.Request, apps.App) error) *Upstream {
+	u.authorize = authorize
+	return u
+}
+
+// WithJWTSigner sets the signer of the JWTs that are not signed with the app's
+// secret.
+func (u *Upstream) WithJWTSigner(signer JWTSigner) *Upstream {
+	u.signer = signer
+	return u
+}
+
+// WithClientKeys sets the source of the private keys of the apps' TLS client
+// certificates, that are not included in apps.App.
+func (u *Upstream) WithClientKeys(clientKeys ClientKeys) *Upstream {
+	u.clientKeys = clientKeys
+	return u
+}
+
+// WithAllowedHosts restricts the requests to the apps to the internal hosts
+// accepted by allowHost, and the public addresses, regardless of devMode.
+func (u *Upstream) WithAllowedHosts(allowHost func(host string) bool) *Upstream {
+	u.allowHost = allowHost
+	return u
+}
+
+// WithEndpoints enables the failover between the root URLs of the apps that
+// have several, keeping their health in endpoints.
+func (u *Upstream) WithEndpoints(endpoints *Endpoints) *Upstream {
+	u.endpoints = endpoints
+	return u
+}
+
 func AppRootURL(app apps.App, _ string) (string, error) {
 	if !app.Manifest.Contains(apps.DeployHTTP) {
 		return "", errors.New("failed to get root URL: no http section in manifest.json")
@@ -71,62 +122,119 @@ func (u *Upstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRe
 }
 
 func (u *Upstream) invoke(ctx context.Context, fromMattermostUserID string, app apps.App, creq apps.CallRequest) (*http.Response, error) {
-	rootURL, err := u.appRootURL(app, creq.Path)
+	rootURLs, err := u.rootURLs(app, creq.Path)
 	if err != nil {
 		return nil, err
 	}
+
+	var resp *http.Response
+	var callURL string
+	for i, rootURL := range rootURLs {
+		resp, callURL, err = u.invokeRootURL(ctx, rootURL, fromMattermostUserID, app, creq)
+		u.recordEndpoint(ctx, app, rootURL, resp, err)
+		if i == len(rootURLs)-1 || !shouldFailover(ctx, resp, err) {
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+
+	switch {
+	case err != nil:
+		return nil, err
+
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, utils.NewNotFoundError(callURL)
+
+	case resp.StatusCode != http.StatusOK:
+		bb, _ := httputils.ReadAndClose(resp.Body)
+		return nil, statusError(resp.StatusCode, string(bb))
+	}
+
+	return resp, nil
+}
+
+// statusError returns the error for an app's response status other than 200
+// and 404. The app's rejections of the request (4xx) are returned as the typed
+// errors, so that they are not retried, and do not open the circuit breaker.
+func statusError(status int, message string) error {
+	switch {
+	case status == http.StatusUnauthorized:
+		return utils.NewUnauthorizedError("%s", message)
+	case status == http.StatusForbidden:
+		return utils.NewForbiddenError("%s", message)
+	case status >= 400 && status < 500:
+		return utils.NewInvalidError("%s", message)
+	default:
+		return errors.New(message)
+	}
+}
+
+// invokeRootURL sends the call request to the app at rootURL, and returns the
+// response as is.
+func (u *Upstream) invokeRootURL(ctx context.Context, rootURL, fromMattermostUserID string, app apps.App, creq apps.CallRequest) (*http.Response, string, error) {
 	callURL, err := utils.CleanURL(rootURL + "/" + creq.Path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	data, err := json.Marshal(creq)
 	if err != nil {
-		return nil, err
+		return nil, callURL, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callURL, bytes.NewReader(data))
 	if err != nil {
-		return nil, err
+		return nil, callURL, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, req.Header)
 
 	// TODO: find a better way to control the use of JWT that both OpenFaaS and
 	// HTTP can share. For now, hard-limit the use of JWT to the HTTP gateway
 	// itself.
 	if app.Manifest.Contains(apps.DeployHTTP) && app.Manifest.HTTP.UseJWT {
 		jwtoken := ""
-		jwtoken, err = createJWT(fromMattermostUserID, app.Secret)
+		jwtoken, err = u.createJWT(fromMattermostUserID, app)
 		if err != nil {
-			return nil, err
+			return nil, callURL, err
 		}
 		req.Header.Set(apps.OutgoingAuthHeader, "Bearer "+jwtoken)
 	}
+	if u.authorize != nil {
+		if err = u.authorize(req, app); err != nil {
+			return nil, callURL, err
+		}
+	}
 
 	// Execute the request.
-	resp, err := u.httpOut.MakeClient(u.devMode).Do(req)
-	switch {
-	case err != nil:
-		return nil, err
-
-	case resp.StatusCode == http.StatusNotFound:
-		return nil, utils.NewNotFoundError(callURL)
-
-	case resp.StatusCode != http.StatusOK:
-		bb, _ := httputils.ReadAndClose(resp.Body)
-		return nil, errors.New(string(bb))
+	client, err := u.makeClient(app)
+	if err != nil {
+		return nil, callURL, err
 	}
-
-	return resp, nil
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, callURL, err
+	}
+	return resp, callURL, nil
 }
 
-func createJWT(actingUserID, secret string) (string, error) {
+func (u *Upstream) createJWT(actingUserID string, app apps.App) (string, error) {
 	claims := apps.JWTClaims{
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(time.Minute * 15).Unix(),
+			Audience:  string(app.AppID),
 		},
 		ActingUserID: actingUserID,
 	}
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if app.Manifest.HTTP.UsesAppSecret() {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(app.Secret))
+	}
+	if u.signer == nil {
+		return "", errors.Errorf("%s JWT signing is not available", app.Manifest.HTTP.JWTSigningMethod)
+	}
+	return u.signer.SignJWT(app.Manifest.HTTP.JWTSigningMethod, claims)
 }
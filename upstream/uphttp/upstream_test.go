@@ -0,0 +1,76 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package uphttp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+type testSigner struct {
+	claims apps.JWTClaims
+}
+
+func (s *testSigner) SignJWT(_ string, claims apps.JWTClaims) (string, error) {
+	s.claims = claims
+	return "signed", nil
+}
+
+func TestCreateJWT(t *testing.T) {
+	signer := &testSigner{}
+	up := NewUpstream(nil, false, nil).WithJWTSigner(signer)
+
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "app1",
+			Deploy: apps.Deploy{
+				HTTP: &apps.HTTP{UseJWT: true, JWTSigningMethod: apps.JWTSigningMethodEdDSA},
+			},
+		},
+	}
+	token, err := up.createJWT("user1", app)
+	require.NoError(t, err)
+	require.Equal(t, "signed", token)
+	require.Equal(t, "app1", signer.claims.Audience)
+	require.Equal(t, "user1", signer.claims.ActingUserID)
+
+	app.Secret = "secret"
+	app.HTTP.JWTSigningMethod = ""
+	token, err = up.createJWT("user1", app)
+	require.NoError(t, err)
+	claims := apps.JWTClaims{}
+	_, err = jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "app1", claims.Audience)
+}
+
+func TestStatusError(t *testing.T) {
+	for status, expected := range map[int]error{
+		http.StatusBadRequest:          utils.ErrInvalid,
+		http.StatusUnauthorized:        utils.ErrUnauthorized,
+		http.StatusForbidden:           utils.ErrForbidden,
+		http.StatusConflict:            utils.ErrInvalid,
+		http.StatusInternalServerError: nil,
+		http.StatusBadGateway:          nil,
+	} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			err := statusError(status, "message")
+			require.Contains(t, err.Error(), "message")
+			if expected == nil {
+				require.EqualError(t, err, "message")
+			} else {
+				require.Equal(t, expected, errors.Cause(err))
+			}
+		})
+	}
+}
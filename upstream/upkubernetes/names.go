@@ -0,0 +1,44 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upkubernetes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+// maxNameLength is the maximum length of a Kubernetes service name (RFC 1035
+// label).
+const maxNameLength = 63
+
+// ServiceName returns the name of the Kubernetes service for an app's
+// function. Names that are too long are truncated, with a hash suffix to keep
+// them unique.
+func ServiceName(appID apps.AppID, version apps.AppVersion, name string) string {
+	full := sanitize(string(appID)) + "-" + sanitize(string(version)) + "-" + sanitize(name)
+	if full[0] >= '0' && full[0] <= '9' {
+		// Service names must start with a letter.
+		full = "app-" + full
+	}
+	if len(full) <= maxNameLength {
+		return full
+	}
+	sum := sha256.Sum256([]byte(full))
+	suffix := hex.EncodeToString(sum[:4])
+	return strings.TrimRight(full[:maxNameLength-len(suffix)-1], "-") + "-" + suffix
+}
+
+func sanitize(s string) string {
+	s = strings.ToLower(s)
+	s = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, s)
+	return strings.Trim(s, "-")
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upkubernetes
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// The minimal subset of the Kubernetes objects needed to deploy a function.
+type object struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   metadata    `yaml:"metadata"`
+	Spec       interface{} `yaml:"spec"`
+}
+
+type metadata struct {
+	Name      string            `yaml:"name,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type deploymentSpec struct {
+	Replicas int `yaml:"replicas"`
+	Selector struct {
+		MatchLabels map[string]string `yaml:"matchLabels"`
+	} `yaml:"selector"`
+	Template struct {
+		Metadata metadata `yaml:"metadata"`
+		Spec     struct {
+			Containers []container `yaml:"containers"`
+		} `yaml:"spec"`
+	} `yaml:"template"`
+}
+
+type container struct {
+	Name  string   `yaml:"name"`
+	Image string   `yaml:"image"`
+	Ports []port   `yaml:"ports"`
+	Env   []envVar `yaml:"env"`
+}
+
+type port struct {
+	Name          string `yaml:"name,omitempty"`
+	ContainerPort int32  `yaml:"containerPort,omitempty"`
+	Port          int32  `yaml:"port,omitempty"`
+	TargetPort    int32  `yaml:"targetPort,omitempty"`
+}
+
+type envVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type serviceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []port            `yaml:"ports"`
+}
+
+// GenerateManifests returns the Kubernetes manifests (a Deployment and a
+// Service for each function) for an app, as a multi-document YAML. namespace
+// overrides the one in the app's manifest; if neither is set, the objects are
+// created in kubectl's current namespace. imagePrefix, usually a docker
+// registry, is prepended to the functions' images.
+func GenerateManifests(m apps.Manifest, namespace, imagePrefix string) ([]byte, error) {
+	if !m.Contains(apps.DeployKubernetes) {
+		return nil, errors.New("manifest.json contains no kubernetes data")
+	}
+	if namespace == "" {
+		namespace = m.Kubernetes.Namespace
+	}
+	if imagePrefix != "" && !strings.HasSuffix(imagePrefix, "/") {
+		imagePrefix += "/"
+	}
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	for _, f := range m.Kubernetes.Functions {
+		if f.Image == "" {
+			return nil, utils.NewInvalidError("function %q has no image", f.Name)
+		}
+		name := ServiceName(m.AppID, m.Version, f.Name)
+		labels := map[string]string{
+			"app.kubernetes.io/name":       name,
+			"app.kubernetes.io/part-of":    string(m.AppID),
+			"app.kubernetes.io/version":    string(m.Version),
+			"app.kubernetes.io/managed-by": "appsctl",
+		}
+		selector := map[string]string{
+			"app.kubernetes.io/name": name,
+		}
+		meta := metadata{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		}
+
+		deployment := deploymentSpec{Replicas: 1}
+		deployment.Selector.MatchLabels = selector
+		deployment.Template.Metadata.Labels = labels
+		deployment.Template.Spec.Containers = []container{{
+			Name:  "function",
+			Image: imagePrefix + f.Image,
+			Ports: []port{{Name: "http", ContainerPort: f.ServicePort()}},
+			Env:   []envVar{{Name: "PORT", Value: strconv.Itoa(int(f.ServicePort()))}},
+		}}
+		service := serviceSpec{
+			Selector: selector,
+			Ports:    []port{{Name: "http", Port: f.ServicePort(), TargetPort: f.ServicePort()}},
+		}
+
+		for _, obj := range []object{
+			{APIVersion: "apps/v1", Kind: "Deployment", Metadata: meta, Spec: deployment},
+			{APIVersion: "v1", Kind: "Service", Metadata: meta, Spec: service},
+		} {
+			if err := enc.Encode(obj); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DeployApp generates the Kubernetes manifests for an app bundle, and applies
+// them to the cluster using kubectl.
+func DeployApp(bundlePath string, log utils.Logger, namespace, imagePrefix string) (*apps.Manifest, error) {
+	m, dir, err := upstream.GetAppBundle(bundlePath, log)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	data, err := GenerateManifests(*m, namespace, imagePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	kubectlPath, err := exec.LookPath("kubectl")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find kubectl command. Please follow the steps from https://kubernetes.io/docs/tasks/tools/")
+	}
+	cmd := exec.Cmd{
+		Path:   kubectlPath,
+		Args:   []string{kubectlPath, "apply", "-f", "-"},
+		Stdin:  bytes.NewReader(data),
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+	log.Debugf("Run %s\n", cmd.String())
+	err = cmd.Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run kubectl command")
+	}
+
+	return m, nil
+}
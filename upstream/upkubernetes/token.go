@@ -0,0 +1,180 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upkubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+)
+
+const (
+	// AppTokenExpirationSeconds is the requested lifetime of the tokens sent to
+	// the apps. The tokens are refreshed at half their lifetime.
+	AppTokenExpirationSeconds = 600
+
+	serviceAccountSubjectPrefix = "system:serviceaccount:"
+)
+
+// Audience returns the audience of the tokens Mattermost sends to the app. The
+// app must verify it with the TokenReview API, so that a token sent to another
+// app can not be used to call it.
+func Audience(appID apps.AppID) string {
+	return "mattermost-app:" + string(appID)
+}
+
+type appTokens struct {
+	mutex  sync.Mutex
+	client *http.Client
+	tokens map[apps.AppID]appToken
+}
+
+type appToken struct {
+	token   string
+	refresh time.Time
+}
+
+type tokenRequest struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Spec       tokenRequestSpec `json:"spec"`
+	Status     struct {
+		Token               string    `json:"token"`
+		ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+	} `json:"status"`
+}
+
+type tokenRequestSpec struct {
+	Audiences         []string `json:"audiences"`
+	ExpirationSeconds int64    `json:"expirationSeconds"`
+}
+
+// appToken returns a token of Mattermost's service account, bound to the
+// app's audience. The tokens are requested from the TokenRequest API, and
+// cached until half of their lifetime has passed.
+func (u *Upstream) appToken(ctx context.Context, appID apps.AppID) (string, error) {
+	u.appTokens.mutex.Lock()
+	defer u.appTokens.mutex.Unlock()
+	if t, ok := u.appTokens.tokens[appID]; ok && time.Now().Before(t.refresh) {
+		return t.token, nil
+	}
+
+	saToken, err := u.serviceAccountToken()
+	if err != nil {
+		return "", err
+	}
+	namespace, name, err := serviceAccount(saToken)
+	if err != nil {
+		return "", err
+	}
+	client, err := u.apiServerClient()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(tokenRequest{
+		APIVersion: "authentication.k8s.io/v1",
+		Kind:       "TokenRequest",
+		Spec: tokenRequestSpec{
+			Audiences:         []string{Audience(appID)},
+			ExpirationSeconds: AppTokenExpirationSeconds,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("%s/api/v1/namespaces/%s/serviceaccounts/%s/token", u.apiServerURL, namespace, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+saToken)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to request a Kubernetes token for %s", appID)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to request a Kubernetes token for %s: %s", appID, resp.Status)
+	}
+
+	tr := tokenRequest{}
+	err = json.NewDecoder(resp.Body).Decode(&tr)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to decode the Kubernetes token for %s", appID)
+	}
+	if tr.Status.Token == "" {
+		return "", errors.Errorf("failed to request a Kubernetes token for %s: empty token", appID)
+	}
+
+	now := time.Now()
+	lifetime := AppTokenExpirationSeconds * time.Second
+	if !tr.Status.ExpirationTimestamp.IsZero() {
+		lifetime = tr.Status.ExpirationTimestamp.Sub(now)
+	}
+	if u.appTokens.tokens == nil {
+		u.appTokens.tokens = map[apps.AppID]appToken{}
+	}
+	u.appTokens.tokens[appID] = appToken{
+		token:   tr.Status.Token,
+		refresh: now.Add(lifetime / 2),
+	}
+	return tr.Status.Token, nil
+}
+
+// apiServerClient returns the client for the API server, which trusts the
+// cluster's CA.
+func (u *Upstream) apiServerClient() (*http.Client, error) {
+	if u.appTokens.client != nil {
+		return u.appTokens.client, nil
+	}
+	ca, err := os.ReadFile(filepath.Join(u.serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the Kubernetes CA certificate")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("failed to parse the Kubernetes CA certificate")
+	}
+	u.appTokens.client = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+	return u.appTokens.client, nil
+}
+
+// serviceAccount returns the namespace and the name of the service account
+// the token belongs to, from its "sub" claim.
+func serviceAccount(token string) (namespace, name string, err error) {
+	claims := jwt.StandardClaims{}
+	_, _, err = new(jwt.Parser).ParseUnverified(token, &claims)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to parse the Kubernetes service account token")
+	}
+	parts := strings.Split(strings.TrimPrefix(claims.Subject, serviceAccountSubjectPrefix), ":")
+	if !strings.HasPrefix(claims.Subject, serviceAccountSubjectPrefix) || len(parts) != 2 {
+		return "", "", errors.Errorf("unexpected Kubernetes service account token subject %q", claims.Subject)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upkubernetes
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/server/httpout"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/uphttp"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const (
+	// EnvServiceHost is set by Kubernetes in every container, it is used to
+	// detect that Mattermost runs in a cluster. With EnvServicePort, it is the
+	// address of the cluster's API server.
+	EnvServiceHost = "KUBERNETES_SERVICE_HOST"
+	EnvServicePort = "KUBERNETES_SERVICE_PORT"
+
+	// EnvClusterDomain can be used to override the cluster's DNS domain.
+	EnvClusterDomain = "MM_APPS_KUBERNETES_CLUSTER_DOMAIN"
+
+	DefaultClusterDomain = "cluster.local"
+
+	// ServiceAccountDir is where Kubernetes mounts the service account
+	// credentials of the pod.
+	ServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// The service account token is rotated by Kubernetes, it is re-read from
+	// the file at most every tokenRefreshInterval. It is only sent to the API
+	// server, never to the apps.
+	tokenRefreshInterval = time.Minute
+)
+
+type Upstream struct {
+	uphttp.Upstream
+
+	// Namespace is the default namespace of the apps' services, the one
+	// Mattermost runs in.
+	Namespace     string
+	ClusterDomain string
+
+	serviceAccountDir string
+	apiServerURL      string
+
+	tokenMutex    sync.Mutex
+	token         string
+	tokenReadTime time.Time
+
+	appTokens appTokens
+}
+
+var _ upstream.Upstream = (*Upstream)(nil)
+
+func MakeUpstream(httpOut httpout.Service) (*Upstream, error) {
+	if os.Getenv(EnvServiceHost) == "" {
+		return nil, utils.NewNotFoundError("not running in a Kubernetes cluster, %s environment variable is not defined", EnvServiceHost)
+	}
+	domain := os.Getenv(EnvClusterDomain)
+	if domain == "" {
+		domain = DefaultClusterDomain
+	}
+	apiServerURL := "https://" + net.JoinHostPort(os.Getenv(EnvServiceHost), os.Getenv(EnvServicePort))
+	return NewUpstream(httpOut, ServiceAccountDir, domain, apiServerURL), nil
+}
+
+func NewUpstream(httpOut httpout.Service, serviceAccountDir, clusterDomain, apiServerURL string) *Upstream {
+	namespace := "default"
+	if data, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace")); err == nil {
+		namespace = strings.TrimSpace(string(data))
+	}
+	up := &Upstream{
+		Namespace:         namespace,
+		ClusterDomain:     clusterDomain,
+		serviceAccountDir: serviceAccountDir,
+		apiServerURL:      apiServerURL,
+	}
+	// The requests are only allowed to the internal addresses of the services
+	// in the cluster.
+	up.Upstream = *uphttp.NewUpstream(httpOut, false, up.appRootURL).
+		WithAuthorization(up.authorize).
+		WithAllowedHosts(up.isServiceHost)
+	return up
+}
+
+// isServiceHost returns true if host is the DNS name of a service in the
+// cluster.
+func (u *Upstream) isServiceHost(host string) bool {
+	return strings.HasSuffix(host, ".svc."+u.ClusterDomain)
+}
+
+func (u *Upstream) appRootURL(app apps.App, path string) (string, error) {
+	return RootURL(app.Manifest, u.Namespace, u.ClusterDomain, path)
+}
+
+// RootURL returns the URL of the app's service that serves path, found by the
+// longest prefix match of the functions' paths. The static assets
+// (uphttp.StaticPath) are served by the app's first function, unless a function
+// matches their path.
+func RootURL(m apps.Manifest, defaultNamespace, clusterDomain, path string) (string, error) {
+	if !m.Contains(apps.DeployKubernetes) {
+		return "", errors.Errorf("failed to get root URL: app %s has no kubernetes section in manifest.json", m.AppID)
+	}
+
+	matchedPath := ""
+	var matched apps.KubernetesFunction
+	for _, f := range m.Kubernetes.Functions {
+		if strings.HasPrefix(path, f.Path) && len(f.Path) > len(matchedPath) {
+			matched = f
+			matchedPath = f.Path
+		}
+	}
+	if matchedPath == "" {
+		if path != uphttp.StaticPath || len(m.Kubernetes.Functions) == 0 {
+			return "", utils.NewNotFoundError("no function matched %q", path)
+		}
+		matched = m.Kubernetes.Functions[0]
+	}
+
+	namespace := m.Kubernetes.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return fmt.Sprintf("http://%s.%s.svc.%s:%v",
+		ServiceName(m.AppID, m.Version, matched.Name), namespace, clusterDomain, matched.ServicePort()), nil
+}
+
+func (u *Upstream) authorize(req *http.Request, app apps.App) error {
+	if !app.Manifest.Contains(apps.DeployKubernetes) || !app.Manifest.Kubernetes.UseServiceAccountToken {
+		return nil
+	}
+	token, err := u.appToken(req.Context(), app.AppID)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (u *Upstream) serviceAccountToken() (string, error) {
+	u.tokenMutex.Lock()
+	defer u.tokenMutex.Unlock()
+	if u.token != "" && time.Since(u.tokenReadTime) < tokenRefreshInterval {
+		return u.token, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(u.serviceAccountDir, "token"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the Kubernetes service account token")
+	}
+	u.token = strings.TrimSpace(string(data))
+	u.tokenReadTime = time.Now()
+	return u.token, nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upkubernetes
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream/uphttp"
+)
+
+func testManifest() apps.Manifest {
+	return apps.Manifest{
+		AppID:   "hello.world",
+		Version: "v1.2.0",
+		Deploy: apps.Deploy{
+			Kubernetes: &apps.Kubernetes{
+				UseServiceAccountToken: true,
+				Functions: []apps.KubernetesFunction{
+					{Path: "/", Name: "main", Image: "hello:latest"},
+					{Path: "/send", Name: "Send_It", Image: "hello-send:latest", Port: 9000},
+				},
+			},
+		},
+	}
+}
+
+func TestRootURL(t *testing.T) {
+	m := testManifest()
+	for path, expected := range map[string]string{
+		"/":         "http://hello-world-v1-2-0-main.apps.svc.cluster.local:8080",
+		"/install":  "http://hello-world-v1-2-0-main.apps.svc.cluster.local:8080",
+		"/send":     "http://hello-world-v1-2-0-send-it.apps.svc.cluster.local:9000",
+		"/send/now": "http://hello-world-v1-2-0-send-it.apps.svc.cluster.local:9000",
+	} {
+		t.Run(path, func(t *testing.T) {
+			url, err := RootURL(m, "apps", "cluster.local", path)
+			require.NoError(t, err)
+			require.Equal(t, expected, url)
+		})
+	}
+
+	m.Kubernetes.Namespace = "other"
+	url, err := RootURL(m, "apps", "cluster.local", "/send")
+	require.NoError(t, err)
+	require.Equal(t, "http://hello-world-v1-2-0-send-it.other.svc.cluster.local:9000", url)
+
+	url, err = RootURL(m, "apps", "cluster.local", uphttp.StaticPath)
+	require.NoError(t, err)
+	require.Equal(t, "http://hello-world-v1-2-0-main.other.svc.cluster.local:8080", url)
+
+	m.Kubernetes.Functions = m.Kubernetes.Functions[1:]
+	_, err = RootURL(m, "apps", "cluster.local", "/install")
+	require.EqualError(t, err, `no function matched "/install": not found`)
+
+	// The static assets are served by the first function.
+	url, err = RootURL(m, "apps", "cluster.local", uphttp.StaticPath)
+	require.NoError(t, err)
+	require.Equal(t, "http://hello-world-v1-2-0-send-it.other.svc.cluster.local:9000", url)
+}
+
+func TestServiceName(t *testing.T) {
+	require.Equal(t, "hello-v1-0-0-main", ServiceName("hello", "v1.0.0", "main"))
+	require.Equal(t, "app-123-v1-main", ServiceName("123", "v1", "main"))
+
+	long := ServiceName("a-very-long-app-id-for-testing-1", "v1.0.0-beta.12345", "a-long-function-name")
+	require.Len(t, long, maxNameLength)
+	require.NotEqual(t, long, ServiceName("a-very-long-app-id-for-testing-1", "v1.0.0-beta.12345", "a-long-function-name-2"))
+}
+
+func TestAuthorize(t *testing.T) {
+	var requests []tokenRequest
+	var paths []string
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer "+testServiceAccountToken(t) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		tr := tokenRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&tr)
+		requests = append(requests, tr)
+		tr.Status.Token = "token-for-" + tr.Spec.Audiences[0]
+		tr.Status.ExpirationTimestamp = time.Now().Add(time.Duration(tr.Spec.ExpirationSeconds) * time.Second)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(tr)
+	}))
+	defer apiServer.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "namespace"), []byte("mattermost\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte(testServiceAccountToken(t)+"\n"), 0600))
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: apiServer.Certificate().Raw})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), ca, 0600))
+
+	up := NewUpstream(nil, dir, DefaultClusterDomain, apiServer.URL)
+	require.Equal(t, "mattermost", up.Namespace)
+
+	app := apps.App{Manifest: testManifest()}
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodPost, "http://test", nil)
+		require.NoError(t, up.authorize(req, app))
+		require.Equal(t, "Bearer token-for-mattermost-app:hello.world", req.Header.Get("Authorization"))
+	}
+	require.Len(t, requests, 1, "the token must be cached")
+	require.Equal(t, []string{"/api/v1/namespaces/mattermost/serviceaccounts/mattermost-apps/token"}, paths)
+	require.Equal(t, []string{"mattermost-app:hello.world"}, requests[0].Spec.Audiences)
+	require.EqualValues(t, AppTokenExpirationSeconds, requests[0].Spec.ExpirationSeconds)
+
+	other := apps.App{Manifest: testManifest()}
+	other.AppID = "other"
+	req, _ := http.NewRequest(http.MethodPost, "http://test", nil)
+	require.NoError(t, up.authorize(req, other))
+	require.Equal(t, "Bearer token-for-mattermost-app:other", req.Header.Get("Authorization"))
+	require.Len(t, requests, 2)
+
+	app.Kubernetes.UseServiceAccountToken = false
+	req, _ = http.NewRequest(http.MethodPost, "http://test", nil)
+	require.NoError(t, up.authorize(req, app))
+	require.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestIsServiceHost(t *testing.T) {
+	up := NewUpstream(nil, t.TempDir(), DefaultClusterDomain, "")
+	require.True(t, up.isServiceHost("hello-world-v1-2-0-main.apps.svc.cluster.local"))
+	require.False(t, up.isServiceHost("example.com"))
+	require.False(t, up.isServiceHost("svc.cluster.local.example.com"))
+}
+
+func testServiceAccountToken(t *testing.T) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
+		Subject: "system:serviceaccount:mattermost:mattermost-apps",
+	}).SignedString([]byte("test"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestGenerateManifests(t *testing.T) {
+	data, err := GenerateManifests(testManifest(), "apps", "registry.example.com")
+	require.NoError(t, err)
+	docs := strings.Split(string(data), "---\n")
+	require.Len(t, docs, 4)
+
+	require.Contains(t, docs[0], "kind: Deployment")
+	require.Contains(t, docs[0], "name: hello-world-v1-2-0-main\n")
+	require.Contains(t, docs[0], "namespace: apps\n")
+	require.Contains(t, docs[0], "image: registry.example.com/hello:latest\n")
+	require.Contains(t, docs[1], "kind: Service")
+	require.Contains(t, docs[3], "port: 9000\n")
+
+	m := testManifest()
+	m.Kubernetes.Functions[0].Image = ""
+	_, err = GenerateManifests(m, "", "")
+	require.EqualError(t, err, `function "main" has no image: invalid input`)
+}
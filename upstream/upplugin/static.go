@@ -13,6 +13,9 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-apps/apps"
 	appspath "github.com/mattermost/mattermost-plugin-apps/apps/path"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 type StaticUpstream struct {
@@ -29,17 +32,19 @@ func (u *StaticUpstream) GetStatic(ctx context.Context, app apps.App, assetPath
 	if !app.Contains(apps.DeployPlugin) {
 		return nil, http.StatusInternalServerError, errors.New("app is not available as type plugin")
 	}
-	url := path.Join("/"+app.Manifest.Plugin.PluginID, apps.PluginAppPath, appspath.StaticFolder, assetPath)
+	url := path.Join("/"+app.Manifest.Plugin.PluginID, apps.PluginAppPath, appspath.StaticFolder, utils.EscapePath(assetPath))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
+	upstream.SetConditionalHeaders(ctx, req)
+	tracing.Inject(ctx, req.Header)
 
 	resp, err := u.httpClient.Do(req) // nolint:bodyclose,gosec // Ignore gosec G107
 	if err != nil {
 		return nil, http.StatusBadGateway, errors.Wrapf(err, "failed to fetch: %s, error: %v", url, err)
 	}
 
-	return resp.Body, resp.StatusCode, nil
+	return upstream.NewStaticAsset(resp.Body, resp.Header), resp.StatusCode, nil
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-apps/upstream"
 	"github.com/mattermost/mattermost-plugin-apps/utils"
 	"github.com/mattermost/mattermost-plugin-apps/utils/httputils"
+	"github.com/mattermost/mattermost-plugin-apps/utils/tracing"
 )
 
 type Upstream struct {
@@ -73,6 +74,7 @@ func (u *Upstream) post(ctx context.Context, url string, msg interface{}) (*http
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, req.Header)
 
 	resp, err := u.httpClient.Do(req)
 	switch {
@@ -15,3 +15,10 @@ type Upstream interface {
 	Roundtrip(ctx context.Context, _ apps.App, _ apps.CallRequest, async bool) (io.ReadCloser, error)
 	GetStatic(ctx context.Context, _ apps.App, path string) (io.ReadCloser, int, error)
 }
+
+// AppStopper is implemented by upstreams that keep resources for each app, such
+// as running processes. StopApp releases them when the app is disabled, or
+// uninstalled.
+type AppStopper interface {
+	StopApp(apps.AppID)
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// Package upwasm implements the upstream for apps that are WebAssembly
+// modules, run in-process by the plugin.
+//
+// A wasm app is a WASI command module (e.g. built with GOOS=wasip1
+// GOARCH=wasm). A new instance of the module is started for every request.
+// Mattermost writes a single upstream.StreamRequest as JSON to the instance's
+// stdin, and closes it. The instance must write a single
+// upstream.StreamResponse as JSON to its stdout, and exit. The ID fields are
+// not used. The instance must not respond to notifications.
+//
+// Anything the module writes to its stderr is logged by Mattermost. The module
+// has no access to the network, nor to the file system; it has access to the
+// real time clocks and a secure random source.
+//
+// Each request is limited to the memory and the run time set in the app's
+// manifest, an instance that exceeds the time limit is terminated.
+package upwasm
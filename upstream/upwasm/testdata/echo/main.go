@@ -0,0 +1,70 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// echo is a wasm app used in the upwasm tests. It does not import the apps
+// packages, so that it builds quickly. Build with:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -o echo.wasm
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type request struct {
+	Type        string `json:"type"`
+	CallRequest struct {
+		Path string `json:"path"`
+	} `json:"call_request"`
+	Path string `json:"path"`
+}
+
+type response struct {
+	CallResponse interface{} `json:"call_response,omitempty"`
+	Data         []byte      `json:"data,omitempty"`
+	NotFound     bool        `json:"not_found,omitempty"`
+}
+
+var sink [][]byte
+
+func main() {
+	req := request{}
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	resp := response{}
+	switch req.Type {
+	case "notify":
+		return
+
+	case "call":
+		switch req.CallRequest.Path {
+		case "/crash":
+			fmt.Fprintln(os.Stderr, "crashing")
+			os.Exit(2)
+		case "/hang":
+			for {
+			}
+		case "/alloc":
+			for {
+				sink = append(sink, make([]byte, 1024*1024))
+			}
+		}
+		resp.CallResponse = map[string]string{
+			"type": "ok",
+			"text": "called " + req.CallRequest.Path,
+		}
+
+	case "static":
+		if req.Path == "icon.png" {
+			resp.Data = []byte("icon")
+		} else {
+			resp.NotFound = true
+		}
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(resp)
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upwasm
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const (
+	// DefaultMaxMemoryMB and DefaultMaxConcurrentInstances are the defaults of
+	// Limits.
+	DefaultMaxMemoryMB            = 64
+	DefaultMaxConcurrentInstances = 4
+
+	// maxStderrSize is the maximum size of an instance's stderr output that is
+	// logged.
+	maxStderrSize = 64 * 1024
+
+	wasmPageSize = 64 * 1024
+)
+
+// ModuleStore provides the WebAssembly modules of the apps' versions.
+type ModuleStore interface {
+	// ModuleHash returns the content hash of the app version's module. It is
+	// checked on every request, so that a module replaced on another server in
+	// the cluster is reloaded.
+	ModuleHash(apps.AppID, apps.AppVersion) (string, error)
+
+	// LoadModule returns the app version's module, and its content hash.
+	LoadModule(apps.AppID, apps.AppVersion) (data []byte, hash string, err error)
+}
+
+// Limits are the administrator's limits on the resources used by the apps'
+// modules. They override the limits the apps declare in their manifests.
+type Limits struct {
+	// MaxTimeout caps the timeout of each request, including the
+	// notifications. 0 means no cap.
+	MaxTimeout time.Duration
+
+	// MaxMemoryMB caps the memory limit of each instance,
+	// DefaultMaxMemoryMB if 0.
+	MaxMemoryMB int
+
+	// MaxConcurrentInstances limits the number of instances of an app's
+	// module that can run at the same time, each using up to the app's memory
+	// limit. DefaultMaxConcurrentInstances if 0.
+	MaxConcurrentInstances int
+}
+
+type Upstream struct {
+	modules ModuleStore
+	log     utils.Logger
+	cache   wazero.CompilationCache
+
+	// loading compiles each app's module once, outside of mutex, for the
+	// concurrent requests that need it.
+	loading singleflight.Group
+
+	mutex  sync.Mutex
+	apps   map[apps.AppID]*appModule
+	limits Limits
+}
+
+// appModule is the compiled module of an app's version, with the runtime it
+// was compiled for.
+type appModule struct {
+	version     apps.AppVersion
+	hash        string
+	memoryLimit int
+	instances   int
+
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	sem      chan struct{}
+}
+
+var _ upstream.Upstream = (*Upstream)(nil)
+var _ upstream.AppStopper = (*Upstream)(nil)
+
+func NewUpstream(modules ModuleStore, log utils.Logger) *Upstream {
+	u := &Upstream{
+		modules: modules,
+		log:     log,
+		cache:   wazero.NewCompilationCache(),
+		apps:    map[apps.AppID]*appModule{},
+	}
+	return u.WithLimits(Limits{})
+}
+
+func (u *Upstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
+	m, err := u.getModule(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if async {
+		go func() {
+			_, err := u.run(context.Background(), app, m, upstream.StreamRequest{
+				Type:        upstream.StreamMessageTypeNotify,
+				CallRequest: &creq,
+			})
+			if err != nil {
+				u.log.WithError(err).Debugf("Failed to notify wasm app %s.", app.AppID)
+			}
+		}()
+		return nil, nil
+	}
+
+	resp, err := u.run(ctx, app, m, upstream.StreamRequest{
+		Type:        upstream.StreamMessageTypeCall,
+		CallRequest: &creq,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to invoke wasm app")
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return io.NopCloser(bytes.NewReader(resp.CallResponse)), nil
+}
+
+func (u *Upstream) GetStatic(ctx context.Context, app apps.App, assetPath string) (io.ReadCloser, int, error) {
+	m, err := u.getModule(app)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	resp, err := u.run(ctx, app, m, upstream.StreamRequest{
+		Type: upstream.StreamMessageTypeStatic,
+		Path: assetPath,
+	})
+	switch {
+	case err != nil:
+		return nil, http.StatusBadGateway, errors.Wrapf(err, "failed to fetch: %s", assetPath)
+	case resp.NotFound:
+		return nil, http.StatusNotFound, utils.NewNotFoundError(assetPath)
+	case resp.Error != "":
+		return nil, http.StatusBadGateway, errors.Errorf("failed to fetch: %s: %s", assetPath, resp.Error)
+	}
+	return io.NopCloser(bytes.NewReader(resp.Data)), http.StatusOK, nil
+}
+
+// WithLimits sets the limits of the requests that follow. The modules loaded
+// with a different memory limit or number of instances are reloaded.
+func (u *Upstream) WithLimits(limits Limits) *Upstream {
+	if limits.MaxMemoryMB <= 0 {
+		limits.MaxMemoryMB = DefaultMaxMemoryMB
+	}
+	if limits.MaxConcurrentInstances <= 0 {
+		limits.MaxConcurrentInstances = DefaultMaxConcurrentInstances
+	}
+	u.mutex.Lock()
+	u.limits = limits
+	u.mutex.Unlock()
+	return u
+}
+
+func (u *Upstream) getLimits() Limits {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	return u.limits
+}
+
+// StopApp unloads the app's module. Instances that are running are terminated.
+func (u *Upstream) StopApp(appID apps.AppID) {
+	u.mutex.Lock()
+	m := u.apps[appID]
+	delete(u.apps, appID)
+	u.mutex.Unlock()
+
+	if m != nil {
+		_ = m.runtime.Close(context.Background())
+	}
+}
+
+// run starts a new instance of the module, and processes req with it.
+func (u *Upstream) run(ctx context.Context, app apps.App, m *appModule, req upstream.StreamRequest) (*upstream.StreamResponse, error) {
+	timeout := upstream.CallTimeout(ctx, app.Manifest.Wasm.Timeout())
+	if max := u.getLimits().MaxTimeout; max > 0 && timeout > max {
+		timeout = max
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case m.sem <- struct{}{}:
+		defer func() { <-m.sem }()
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "too many concurrent requests")
+	}
+
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	stdout := &limitedBuffer{limit: upstream.MaxStreamMessageSize}
+	stderr := &limitedBuffer{limit: maxStderrSize}
+	config := wazero.NewModuleConfig().
+		// An anonymous instance, so that many can run at the same time.
+		WithName("").
+		WithArgs(string(app.AppID)).
+		WithStdin(bytes.NewReader(in)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+
+	mod, err := m.runtime.InstantiateModule(ctx, m.compiled, config)
+	if mod != nil {
+		_ = mod.Close(context.Background())
+	}
+	if stderr.Len() > 0 {
+		u.log.Debugw("wasm app stderr", "app_id", app.AppID, "output", stderr.String())
+	}
+
+	var exitErr *sys.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 0:
+	case ctx.Err() == context.DeadlineExceeded:
+		return nil, errors.Errorf("app module did not respond in %s", timeout)
+	case err != nil:
+		return nil, errors.Wrap(err, "app module failed")
+	}
+
+	if req.Type == upstream.StreamMessageTypeNotify {
+		return nil, nil
+	}
+	if stdout.overflow {
+		return nil, errors.Errorf("app module response is too large, max %v bytes", upstream.MaxStreamMessageSize)
+	}
+	resp := upstream.StreamResponse{}
+	if err = json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, errors.Wrap(err, "invalid response from app module")
+	}
+	return &resp, nil
+}
+
+// getModule returns the compiled module of the app's version, loading and
+// compiling it if needed, or if it was replaced.
+func (u *Upstream) getModule(app apps.App) (*appModule, error) {
+	if !app.Manifest.Contains(apps.DeployWasm) {
+		return nil, errors.New("no wasm section in manifest.json")
+	}
+	limits := u.getLimits()
+	memoryLimit := app.Manifest.Wasm.MemoryLimit()
+	if memoryLimit > limits.MaxMemoryMB {
+		memoryLimit = limits.MaxMemoryMB
+	}
+	instances := limits.MaxConcurrentInstances
+
+	hash, err := u.modules.ModuleHash(app.AppID, app.Version)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load app module")
+	}
+	u.mutex.Lock()
+	m := u.apps[app.AppID]
+	u.mutex.Unlock()
+	if m != nil && m.version == app.Version && m.hash == hash &&
+		m.memoryLimit == memoryLimit && m.instances == instances {
+		return m, nil
+	}
+
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%v\x00%v", app.AppID, app.Version, hash, memoryLimit, instances)
+	v, err, _ := u.loading.Do(key, func() (interface{}, error) {
+		return u.loadModule(app, memoryLimit, instances)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*appModule), nil
+}
+
+func (u *Upstream) loadModule(app apps.App, memoryLimit, instances int) (*appModule, error) {
+	data, hash, err := u.modules.LoadModule(app.AppID, app.Version)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load app module")
+	}
+
+	// Not the request's context, the module is shared by the concurrent
+	// requests.
+	ctx := context.Background()
+
+	// The memory limit is set on the runtime, so each app has its own.
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(memoryLimit*1024*1024/wasmPageSize)).
+		WithCloseOnContextDone(true).
+		WithCompilationCache(u.cache))
+	if _, err = wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, errors.Wrap(err, "failed to initialize WASI")
+	}
+	compiled, err := runtime.CompileModule(ctx, data)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, utils.NewInvalidError(err, "failed to compile app module")
+	}
+
+	m := &appModule{
+		version:     app.Version,
+		hash:        hash,
+		memoryLimit: memoryLimit,
+		instances:   instances,
+		runtime:     runtime,
+		compiled:    compiled,
+		sem:         make(chan struct{}, instances),
+	}
+	u.mutex.Lock()
+	prev := u.apps[app.AppID]
+	u.apps[app.AppID] = m
+	u.mutex.Unlock()
+	if prev != nil {
+		// Instances that are still running on the previous version are
+		// terminated.
+		_ = prev.runtime.Close(context.Background())
+	}
+	u.log.Debugf("Loaded wasm app %s %s, sha256 %s.", app.AppID, app.Version, hash)
+	return m, nil
+}
+
+// limitedBuffer is a bytes.Buffer that drops the writes past limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		b.overflow = true
+		return 0, errors.New("output is too large")
+	}
+	return b.Buffer.Write(p)
+}
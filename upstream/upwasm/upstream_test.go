@@ -0,0 +1,202 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upwasm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+func buildTestApp(t *testing.T) []byte {
+	out := filepath.Join(t.TempDir(), "echo.wasm")
+	cmd := exec.Command("go", "build", "-o", out, ".")
+	cmd.Dir = filepath.Join("testdata", "echo")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("failed to build the test app: %v: %s", err, output)
+	}
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	return data
+}
+
+type testModuleStore struct {
+	mutex  sync.Mutex
+	module []byte
+	hash   string
+	loads  int
+}
+
+func (s *testModuleStore) ModuleHash(appID apps.AppID, version apps.AppVersion) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if version != "v1.0.0" {
+		return "", utils.NewNotFoundError("no module for %s %s", appID, version)
+	}
+	return s.hash, nil
+}
+
+func (s *testModuleStore) LoadModule(appID apps.AppID, version apps.AppVersion) ([]byte, string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.loads++
+	if version != "v1.0.0" {
+		return nil, "", utils.NewNotFoundError("no module for %s %s", appID, version)
+	}
+	return s.module, s.hash, nil
+}
+
+func (s *testModuleStore) getLoads() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.loads
+}
+
+func TestUpstream(t *testing.T) {
+	modules := &testModuleStore{
+		module: buildTestApp(t),
+		hash:   "hash1",
+	}
+	up := NewUpstream(modules, utils.NewTestLogger())
+	defer up.StopApp("test")
+
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID:   "test",
+			Version: "v1.0.0",
+			Deploy: apps.Deploy{
+				Wasm: &apps.Wasm{
+					Module:         "echo.wasm",
+					MemoryLimitMB:  32,
+					TimeoutSeconds: 1,
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	call := func(path string) (apps.CallResponse, error) {
+		return upstream.Call(ctx, up, app, apps.CallRequest{Call: *apps.NewCall(path)})
+	}
+
+	t.Run("call", func(t *testing.T) {
+		cresp, err := call("/hello")
+		require.NoError(t, err)
+		require.Equal(t, "called /hello", cresp.Text)
+
+		cresp, err = call("/again")
+		require.NoError(t, err)
+		require.Equal(t, "called /again", cresp.Text)
+		require.Equal(t, 1, modules.getLoads())
+	})
+
+	t.Run("concurrent calls load once", func(t *testing.T) {
+		up.StopApp("test")
+		before := modules.getLoads()
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := call("/hello")
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, before+1, modules.getLoads())
+	})
+
+	t.Run("reload when the hash changes", func(t *testing.T) {
+		before := modules.getLoads()
+		modules.mutex.Lock()
+		modules.hash = "hash2"
+		modules.mutex.Unlock()
+
+		cresp, err := call("/hello")
+		require.NoError(t, err)
+		require.Equal(t, "called /hello", cresp.Text)
+		require.Equal(t, before+1, modules.getLoads())
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		err := upstream.Notify(ctx, up, app, apps.CallRequest{Call: *apps.NewCall("/notify")})
+		require.NoError(t, err)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		body, code, err := up.GetStatic(ctx, app, "icon.png")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+		data, _ := io.ReadAll(body)
+		require.Equal(t, "icon", string(data))
+
+		_, code, err = up.GetStatic(ctx, app, "missing.png")
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		_, err := call("/crash")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "app module failed")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := call("/hang")
+		require.EqualError(t, err, "failed to invoke wasm app: app module did not respond in 1s")
+		require.Less(t, time.Since(start), 3*time.Second)
+
+		cresp, err := call("/hello")
+		require.NoError(t, err)
+		require.Equal(t, "called /hello", cresp.Text)
+	})
+
+	t.Run("memory limit", func(t *testing.T) {
+		_, err := call("/alloc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "app module failed")
+	})
+
+	t.Run("admin limits", func(t *testing.T) {
+		defer up.WithLimits(Limits{})
+		before := modules.getLoads()
+		up.WithLimits(Limits{
+			MaxTimeout:             500 * time.Millisecond,
+			MaxMemoryMB:            16,
+			MaxConcurrentInstances: 2,
+		})
+
+		_, err := call("/hang")
+		require.EqualError(t, err, "failed to invoke wasm app: app module did not respond in 500ms")
+
+		// Reloaded with the lower limits.
+		require.Equal(t, before+1, modules.getLoads())
+		m, err := up.getModule(app)
+		require.NoError(t, err)
+		require.Equal(t, 16, m.memoryLimit)
+		require.Equal(t, 2, cap(m.sem))
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		v2 := app
+		v2.Version = "v2.0.0"
+		_, err := upstream.Call(ctx, up, v2, apps.CallRequest{Call: *apps.NewCall("/hello")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to load app module")
+	})
+}
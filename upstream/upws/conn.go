@@ -0,0 +1,181 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const (
+	// Mattermost pings the app every pingInterval, and drops the connection if
+	// nothing is received from the app within pongWait.
+	pingInterval = 30 * time.Second
+	pongWait     = 60 * time.Second
+
+	writeWait = 10 * time.Second
+)
+
+// conn multiplexes requests to an app over its WebSocket connection.
+type conn struct {
+	ws  *websocket.Conn
+	log utils.Logger
+
+	writeMutex sync.Mutex
+
+	mutex   sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan upstream.StreamResponse
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+func newConn(ws *websocket.Conn, log utils.Logger) *conn {
+	return &conn{
+		ws:      ws,
+		log:     log,
+		pending: map[uint64]chan upstream.StreamResponse{},
+		done:    make(chan struct{}),
+	}
+}
+
+// serve reads the responses from the app, and keeps the connection alive until
+// it is closed. It returns the reason the connection was closed.
+func (c *conn) serve() error {
+	go c.keepAlive()
+
+	c.ws.SetReadLimit(upstream.MaxStreamMessageSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.close(err)
+			break
+		}
+		_ = c.ws.SetReadDeadline(time.Now().Add(pongWait))
+
+		resp := upstream.StreamResponse{}
+		if err = json.Unmarshal(data, &resp); err != nil {
+			c.log.WithError(err).Warnf("Ignored invalid message from app.")
+			continue
+		}
+		c.mutex.Lock()
+		respC := c.pending[resp.ID]
+		c.mutex.Unlock()
+		if respC == nil {
+			c.log.Debugf("Ignored response to unknown request %v from app.", resp.ID)
+			continue
+		}
+		select {
+		case respC <- resp:
+		default:
+			// A duplicate response, the request already has one.
+		}
+	}
+
+	<-c.done
+	return c.err
+}
+
+func (c *conn) keepAlive() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.writeMutex.Lock()
+			err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+			c.writeMutex.Unlock()
+			if err != nil {
+				c.close(errors.Wrap(err, "failed to ping"))
+				return
+			}
+		}
+	}
+}
+
+// roundtrip sends req to the app, and waits for the response.
+func (c *conn) roundtrip(ctx context.Context, req upstream.StreamRequest, timeout time.Duration) (upstream.StreamResponse, error) {
+	respC := make(chan upstream.StreamResponse, 1)
+	c.mutex.Lock()
+	c.nextID++
+	req.ID = c.nextID
+	c.pending[req.ID] = respC
+	c.mutex.Unlock()
+	defer func() {
+		c.mutex.Lock()
+		delete(c.pending, req.ID)
+		c.mutex.Unlock()
+	}()
+
+	if err := c.send(req); err != nil {
+		return upstream.StreamResponse{}, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case resp := <-respC:
+		return resp, nil
+	case <-c.done:
+		return upstream.StreamResponse{}, errors.Wrap(c.err, "app disconnected")
+	case <-ctx.Done():
+		return upstream.StreamResponse{}, ctx.Err()
+	case <-timer.C:
+		return upstream.StreamResponse{}, errors.Errorf("app did not respond in %s", timeout)
+	}
+}
+
+func (c *conn) send(req upstream.StreamRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	select {
+	case <-c.done:
+		return errors.Wrap(c.err, "app disconnected")
+	default:
+	}
+	_ = c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	err = c.ws.WriteMessage(websocket.TextMessage, data)
+	if err != nil {
+		go c.close(errors.Wrap(err, "failed to write"))
+		return errors.Wrap(err, "failed to write to app")
+	}
+	return nil
+}
+
+// close closes the connection, err is the reason. Only the first call has any
+// effect.
+func (c *conn) close(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+
+		c.writeMutex.Lock()
+		_ = c.ws.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+		c.writeMutex.Unlock()
+		_ = c.ws.Close()
+	})
+}
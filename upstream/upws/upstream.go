@@ -0,0 +1,354 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upws
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const (
+	// The status of a connected app is saved in the StatusStore every
+	// sharedStatusInterval, and expires after sharedStatusTTL, so that a
+	// server that goes away does not keep reporting the app as connected.
+	sharedStatusInterval = time.Minute
+	sharedStatusTTL      = 3 * time.Minute
+)
+
+// Upstream sends requests to apps over the WebSocket connections they open to
+// Mattermost. Connections are local to the Mattermost server node they are
+// made to. With a StatusStore, the status of the connections is shared with
+// the other servers in the cluster, so that they can report it. With a
+// Forwarder, the requests to the apps connected elsewhere are forwarded to
+// the server they are connected to, otherwise they fail with a clear error.
+type Upstream struct {
+	log         utils.Logger
+	node        string
+	statusStore StatusStore
+	forwarder   Forwarder
+
+	mutex  sync.RWMutex
+	conns  map[apps.AppID]*conn
+	status map[apps.AppID]*Status
+}
+
+// StatusStore shares the status of the apps' connections across the
+// Mattermost servers in a cluster.
+type StatusStore interface {
+	// Get returns the most recently saved status of the app's connection, or
+	// utils.ErrNotFound.
+	Get(apps.AppID) (*Status, error)
+	Save(_ apps.AppID, _ Status, ttl time.Duration) error
+}
+
+// Forwarder sends requests to the app connected to another Mattermost server in
+// the cluster, identified by node. The server handles them with
+// HandleForwarded. Notifications are sent without waiting for a response.
+type Forwarder interface {
+	Forward(_ context.Context, node string, _ apps.AppID, _ upstream.StreamRequest, timeout time.Duration) (upstream.StreamResponse, error)
+}
+
+// Status is the state of an app's connection.
+type Status struct {
+	Connected bool `json:"connected"`
+
+	// Node identifies the Mattermost server the app is, or was connected to.
+	Node string `json:"node,omitempty"`
+
+	// Since is the time the app connected, or disconnected if Connected is
+	// false.
+	Since      time.Time `json:"since"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+
+	// Connects is the number of times the app has connected.
+	Connects int `json:"connects"`
+
+	// LastError is the reason of the last disconnect.
+	LastError string `json:"last_error,omitempty"`
+}
+
+var _ upstream.Upstream = (*Upstream)(nil)
+var _ upstream.AppStopper = (*Upstream)(nil)
+
+func NewUpstream(log utils.Logger) *Upstream {
+	return &Upstream{
+		log:    log,
+		conns:  map[apps.AppID]*conn{},
+		status: map[apps.AppID]*Status{},
+	}
+}
+
+// WithStatusStore shares the status of the connections made to this server,
+// identified by node, with the other servers in the cluster.
+func (u *Upstream) WithStatusStore(statusStore StatusStore, node string) *Upstream {
+	u.statusStore = statusStore
+	u.node = node
+	return u
+}
+
+// WithForwarder forwards the requests to the apps connected to the other
+// servers in the cluster. It requires a StatusStore to find the servers.
+func (u *Upstream) WithForwarder(forwarder Forwarder) *Upstream {
+	u.forwarder = forwarder
+	return u
+}
+
+// Serve handles the app's connection until it is closed. An existing
+// connection of the app is closed, and replaced with the new one.
+func (u *Upstream) Serve(appID apps.AppID, ws *websocket.Conn) {
+	c := newConn(ws, u.log.With("app_id", appID))
+
+	u.mutex.Lock()
+	prev := u.conns[appID]
+	u.conns[appID] = c
+	s := u.status[appID]
+	if s == nil {
+		s = &Status{}
+		u.status[appID] = s
+	}
+	s.Connected = true
+	s.Node = u.node
+	s.Since = time.Now()
+	s.RemoteAddr = ws.RemoteAddr().String()
+	s.Connects++
+	u.mutex.Unlock()
+
+	if prev != nil {
+		prev.close(errors.New("replaced by a new connection"))
+	}
+	u.log.Debugf("App %s connected from %s.", appID, ws.RemoteAddr())
+	go u.shareStatus(appID, c)
+
+	err := c.serve()
+
+	u.mutex.Lock()
+	current := u.conns[appID] == c
+	if current {
+		delete(u.conns, appID)
+		s.Connected = false
+		s.Since = time.Now()
+		s.LastError = err.Error()
+	}
+	u.mutex.Unlock()
+	if current {
+		u.saveSharedStatus(appID)
+	}
+	u.log.Debugf("App %s disconnected: %v", appID, err)
+}
+
+// shareStatus saves the app's status in the StatusStore periodically, while c
+// is open.
+func (u *Upstream) shareStatus(appID apps.AppID, c *conn) {
+	if u.statusStore == nil {
+		return
+	}
+	u.saveSharedStatus(appID)
+	ticker := time.NewTicker(sharedStatusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			u.saveSharedStatus(appID)
+		}
+	}
+}
+
+func (u *Upstream) saveSharedStatus(appID apps.AppID) {
+	if u.statusStore == nil {
+		return
+	}
+	s := u.localStatus(appID)
+	if s == nil {
+		return
+	}
+	if err := u.statusStore.Save(appID, *s, sharedStatusTTL); err != nil {
+		u.log.WithError(err).Warnf("Failed to save the connection status of app %s.", appID)
+	}
+}
+
+func (u *Upstream) sharedStatus(appID apps.AppID) *Status {
+	if u.statusStore == nil {
+		return nil
+	}
+	s, err := u.statusStore.Get(appID)
+	if err != nil {
+		if errors.Cause(err) != utils.ErrNotFound {
+			u.log.WithError(err).Debugf("Failed to get the connection status of app %s.", appID)
+		}
+		return nil
+	}
+	return s
+}
+
+func (u *Upstream) localStatus(appID apps.AppID) *Status {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	s := u.status[appID]
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	return &clone
+}
+
+// Status returns the state of the app's connection. If the app is not
+// connected to this server, the status shared by the other servers is
+// returned, if it is more recent. Status returns nil if the app has not
+// connected to any server.
+func (u *Upstream) Status(appID apps.AppID) *Status {
+	local := u.localStatus(appID)
+	if local != nil && local.Connected {
+		return local
+	}
+	shared := u.sharedStatus(appID)
+	if shared != nil && (local == nil || shared.Since.After(local.Since)) {
+		return shared
+	}
+	return local
+}
+
+// StopApp closes the app's connection, if it is connected.
+func (u *Upstream) StopApp(appID apps.AppID) {
+	u.mutex.Lock()
+	c := u.conns[appID]
+	delete(u.conns, appID)
+	delete(u.status, appID)
+	u.mutex.Unlock()
+
+	if c != nil {
+		c.close(errors.New("app stopped"))
+		if u.statusStore != nil {
+			err := u.statusStore.Save(appID, Status{
+				Node:      u.node,
+				Since:     time.Now(),
+				LastError: "app stopped",
+			}, sharedStatusTTL)
+			if err != nil {
+				u.log.WithError(err).Warnf("Failed to save the connection status of app %s.", appID)
+			}
+		}
+	}
+}
+
+func (u *Upstream) Roundtrip(ctx context.Context, app apps.App, creq apps.CallRequest, async bool) (io.ReadCloser, error) {
+	c, node, err := u.getConn(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if async {
+		go func() {
+			_, err := u.request(context.Background(), c, node, app.AppID, upstream.StreamRequest{
+				Type:        upstream.StreamMessageTypeNotify,
+				CallRequest: &creq,
+			}, app.Manifest.WebSocket.Timeout())
+			if err != nil {
+				u.log.WithError(err).Debugf("Failed to notify app %s.", app.AppID)
+			}
+		}()
+		return nil, nil
+	}
+
+	resp, err := u.request(ctx, c, node, app.AppID, upstream.StreamRequest{
+		Type:        upstream.StreamMessageTypeCall,
+		CallRequest: &creq,
+	}, upstream.CallTimeout(ctx, app.Manifest.WebSocket.Timeout()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to invoke via WebSocket")
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return io.NopCloser(bytes.NewReader(resp.CallResponse)), nil
+}
+
+func (u *Upstream) GetStatic(ctx context.Context, app apps.App, assetPath string) (io.ReadCloser, int, error) {
+	c, node, err := u.getConn(app)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+
+	resp, err := u.request(ctx, c, node, app.AppID, upstream.StreamRequest{
+		Type: upstream.StreamMessageTypeStatic,
+		Path: assetPath,
+	}, app.Manifest.WebSocket.Timeout())
+	switch {
+	case err != nil:
+		return nil, http.StatusBadGateway, errors.Wrapf(err, "failed to fetch: %s", assetPath)
+	case resp.NotFound:
+		return nil, http.StatusNotFound, utils.NewNotFoundError(assetPath)
+	case resp.Error != "":
+		return nil, http.StatusBadGateway, errors.Errorf("failed to fetch: %s: %s", assetPath, resp.Error)
+	}
+	return io.NopCloser(bytes.NewReader(resp.Data)), http.StatusOK, nil
+}
+
+// HandleForwarded handles a request forwarded by a Forwarder from another
+// server in the cluster, to the app connected to this server.
+func (u *Upstream) HandleForwarded(ctx context.Context, appID apps.AppID, req upstream.StreamRequest, timeout time.Duration) (upstream.StreamResponse, error) {
+	u.mutex.RLock()
+	c := u.conns[appID]
+	u.mutex.RUnlock()
+	if c == nil {
+		return upstream.StreamResponse{}, errors.Errorf("app %s is not connected to this Mattermost server", appID)
+	}
+	return u.request(ctx, c, "", appID, req, timeout)
+}
+
+// request sends req to the app over c, or forwards it to node if c is nil.
+// Notifications are sent without waiting for a response.
+func (u *Upstream) request(ctx context.Context, c *conn, node string, appID apps.AppID, req upstream.StreamRequest, timeout time.Duration) (upstream.StreamResponse, error) {
+	switch {
+	case c == nil:
+		return u.forwarder.Forward(ctx, node, appID, req, timeout)
+	case req.Type == upstream.StreamMessageTypeNotify:
+		return upstream.StreamResponse{}, c.send(req)
+	default:
+		return c.roundtrip(ctx, req, timeout)
+	}
+}
+
+// getConn returns the app's connection to this server. If the app is
+// connected to another server, and there is a Forwarder, getConn returns the
+// server's node instead.
+func (u *Upstream) getConn(app apps.App) (*conn, string, error) {
+	if !app.Manifest.Contains(apps.DeployWebSocket) {
+		return nil, "", errors.New("no websocket section in manifest.json")
+	}
+
+	u.mutex.RLock()
+	c := u.conns[app.AppID]
+	u.mutex.RUnlock()
+	if c != nil {
+		return c, "", nil
+	}
+
+	// Not a NotFound error, so that the app is reported as unreachable.
+	s := u.Status(app.AppID)
+	switch {
+	case s == nil:
+		return nil, "", errors.Errorf("app %s is not connected", app.AppID)
+	case s.Connected && s.Node != u.node && u.forwarder != nil:
+		return nil, s.Node, nil
+	case s.Connected:
+		return nil, "", errors.Errorf("app %s is connected to another Mattermost server (%s), it can only be reached from that server",
+			app.AppID, s.Node)
+	default:
+		return nil, "", errors.Errorf("app %s is not connected, disconnected at %s: %s",
+			app.AppID, s.Since.Format(time.RFC3339), s.LastError)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/upstream"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// runTestApp connects to url, and serves requests until the connection is
+// closed.
+func runTestApp(t *testing.T, url string) *websocket.Conn {
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			req := upstream.StreamRequest{}
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+			switch req.Type {
+			case upstream.StreamMessageTypeCall:
+				data, _ := json.Marshal(apps.NewTextResponse("called %s", req.CallRequest.Path))
+				_ = ws.WriteJSON(upstream.StreamResponse{ID: req.ID, CallResponse: data})
+			case upstream.StreamMessageTypeStatic:
+				_ = ws.WriteJSON(upstream.StreamResponse{ID: req.ID, NotFound: req.Path != "icon.png", Data: []byte("icon")})
+			}
+		}
+	}()
+	return ws
+}
+
+func TestUpstream(t *testing.T) {
+	up := NewUpstream(utils.NewTestLogger())
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		require.NoError(t, err)
+		up.Serve("test", ws)
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "test",
+			Deploy: apps.Deploy{
+				WebSocket: &apps.WebSocket{},
+			},
+		},
+	}
+	ctx := context.Background()
+	call := func() (apps.CallResponse, error) {
+		return upstream.Call(ctx, up, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+	}
+	connected := func() bool {
+		s := up.Status("test")
+		return s != nil && s.Connected
+	}
+
+	_, err := call()
+	require.EqualError(t, err, "app test is not connected")
+
+	ws := runTestApp(t, url)
+	require.Eventually(t, connected, time.Second, 10*time.Millisecond)
+
+	cresp, err := call()
+	require.NoError(t, err)
+	require.Equal(t, "called /hello", cresp.Text)
+
+	body, code, err := up.GetStatic(ctx, app, "icon.png")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+	data, _ := io.ReadAll(body)
+	require.Equal(t, "icon", string(data))
+
+	_, code, err = up.GetStatic(ctx, app, "missing.png")
+	require.Error(t, err)
+	require.Equal(t, http.StatusNotFound, code)
+
+	// Disconnect.
+	ws.Close()
+	require.Eventually(t, func() bool { return !connected() }, time.Second, 10*time.Millisecond)
+	_, err = call()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "app test is not connected, disconnected at")
+
+	// Reconnect, then connect again, replacing the connection.
+	_ = runTestApp(t, url)
+	require.Eventually(t, connected, time.Second, 10*time.Millisecond)
+	ws = runTestApp(t, url)
+	require.Eventually(t, func() bool {
+		s := up.Status("test")
+		return s != nil && s.Connects == 3 && s.Connected
+	}, time.Second, 10*time.Millisecond)
+	cresp, err = call()
+	require.NoError(t, err)
+	require.Equal(t, "called /hello", cresp.Text)
+
+	up.StopApp("test")
+	require.Nil(t, up.Status("test"))
+	ws.Close()
+}
+
+type testStatusStore struct {
+	mutex  sync.Mutex
+	status map[apps.AppID]Status
+}
+
+func (s *testStatusStore) Get(appID apps.AppID) (*Status, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	status, ok := s.status[appID]
+	if !ok {
+		return nil, utils.NewNotFoundError(appID)
+	}
+	return &status, nil
+}
+
+func (s *testStatusStore) Save(appID apps.AppID, status Status, _ time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status[appID] = status
+	return nil
+}
+
+// testForwarder forwards the requests to the Upstreams of the other nodes.
+type testForwarder map[string]*Upstream
+
+func (f testForwarder) Forward(ctx context.Context, node string, appID apps.AppID, req upstream.StreamRequest, timeout time.Duration) (upstream.StreamResponse, error) {
+	return f[node].HandleForwarded(ctx, appID, req, timeout)
+}
+
+func TestSharedStatus(t *testing.T) {
+	statusStore := &testStatusStore{status: map[apps.AppID]Status{}}
+	up1 := NewUpstream(utils.NewTestLogger()).WithStatusStore(statusStore, "node1")
+	up2 := NewUpstream(utils.NewTestLogger()).WithStatusStore(statusStore, "node2")
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		require.NoError(t, err)
+		up1.Serve("test", ws)
+	}))
+	defer server.Close()
+
+	app := apps.App{
+		Manifest: apps.Manifest{
+			AppID: "test",
+			Deploy: apps.Deploy{
+				WebSocket: &apps.WebSocket{},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	ws := runTestApp(t, "ws"+strings.TrimPrefix(server.URL, "http"))
+	require.Eventually(t, func() bool {
+		s := up2.Status("test")
+		return s != nil && s.Connected
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, "node1", up2.Status("test").Node)
+
+	_, err := upstream.Call(ctx, up1, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+	require.NoError(t, err)
+	_, err = upstream.Call(ctx, up2, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+	require.EqualError(t, err, "app test is connected to another Mattermost server (node1), it can only be reached from that server")
+
+	// With a forwarder, the request is forwarded to node1.
+	up2.WithForwarder(testForwarder{"node1": up1})
+	cresp, err := upstream.Call(ctx, up2, app, apps.CallRequest{Call: *apps.NewCall("/hello")})
+	require.NoError(t, err)
+	require.Equal(t, "called /hello", cresp.Text)
+	_, code, err := up2.GetStatic(ctx, app, "icon.png")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+
+	ws.Close()
+	require.Eventually(t, func() bool {
+		s := up2.Status("test")
+		return s != nil && !s.Connected
+	}, time.Second, 10*time.Millisecond)
+}
@@ -7,7 +7,10 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/pkg/errors"
+
 	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
 )
 
 func Notify(ctx context.Context, u Upstream, app apps.App, creq apps.CallRequest) error {
@@ -32,3 +35,51 @@ func Call(ctx context.Context, u Upstream, app apps.App, creq apps.CallRequest)
 	}
 	return cr, nil
 }
+
+// Middleware wraps an Upstream, adding behavior to its requests, e.g. retries.
+type Middleware func(Upstream) Upstream
+
+// Chain wraps up with middleware. The first middleware is the outermost, it
+// sees the requests first.
+func Chain(up Upstream, middleware ...Middleware) Upstream {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			up = middleware[i](up)
+		}
+	}
+	return up
+}
+
+// IsIdempotent returns true if the call can be safely repeated: bindings,
+// lookups, and ping. Form refreshes may have side effects in the app, and are
+// not retried. They are told apart from lookups by the query, so the lookups
+// with an empty query are not retried either.
+func IsIdempotent(app apps.App, creq apps.CallRequest) bool {
+	bindingsPath := apps.DefaultBindings.Path
+	if app.Bindings != nil {
+		bindingsPath = app.Bindings.Path
+	}
+	return creq.Path == bindingsPath ||
+		creq.Path == apps.DefaultPing.Path ||
+		isLookup(creq)
+}
+
+func isLookup(creq apps.CallRequest) bool {
+	return creq.SelectedField != "" && creq.Query != ""
+}
+
+// isFailure returns true if err indicates that the app failed, rather than
+// rejected the request, or the request was canceled by the caller.
+func isFailure(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+	switch errors.Cause(err) {
+	case utils.ErrNotFound, utils.ErrInvalid, utils.ErrForbidden, utils.ErrUnauthorized, ErrCircuitOpen:
+		return false
+	}
+	return true
+}
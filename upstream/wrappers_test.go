@@ -0,0 +1,179 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package upstream
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/apps"
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// testUpstream fails the first `failures` requests with err.
+type testUpstream struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (u *testUpstream) Roundtrip(_ context.Context, _ apps.App, _ apps.CallRequest, _ bool) (io.ReadCloser, error) {
+	u.calls++
+	if u.calls <= u.failures {
+		return nil, u.err
+	}
+	return io.NopCloser(strings.NewReader(`{"type":"ok"}`)), nil
+}
+
+func (u *testUpstream) GetStatic(_ context.Context, _ apps.App, _ string) (io.ReadCloser, int, error) {
+	u.calls++
+	if u.calls <= u.failures {
+		return io.NopCloser(strings.NewReader("error")), http.StatusBadGateway, nil
+	}
+	return io.NopCloser(strings.NewReader("icon")), http.StatusOK, nil
+}
+
+func TestRetry(t *testing.T) {
+	app := apps.App{Manifest: apps.Manifest{AppID: "test"}}
+	ctx := context.Background()
+	policy := RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}
+
+	for name, tc := range map[string]struct {
+		creq          apps.CallRequest
+		failures      int
+		err           error
+		expectedCalls int
+		expectedError string
+	}{
+		"bindings retried": {
+			creq:          apps.CallRequest{Call: apps.DefaultBindings},
+			failures:      2,
+			err:           errors.New("connection refused"),
+			expectedCalls: 3,
+		},
+		"lookup retried, too many failures": {
+			creq:          apps.CallRequest{Call: *apps.NewCall("/lookup"), SelectedField: "f", Query: "q"},
+			failures:      3,
+			err:           errors.New("connection refused"),
+			expectedCalls: 3,
+			expectedError: "connection refused",
+		},
+		"form refresh not retried": {
+			creq:          apps.CallRequest{Call: *apps.NewCall("/form"), SelectedField: "f"},
+			failures:      1,
+			err:           errors.New("connection refused"),
+			expectedCalls: 1,
+			expectedError: "connection refused",
+		},
+		"submit not retried": {
+			creq:          apps.CallRequest{Call: *apps.NewCall("/submit")},
+			failures:      1,
+			err:           errors.New("connection refused"),
+			expectedCalls: 1,
+			expectedError: "connection refused",
+		},
+		"not found not retried": {
+			creq:          apps.CallRequest{Call: apps.DefaultBindings},
+			failures:      1,
+			err:           utils.NewNotFoundError("/bindings"),
+			expectedCalls: 1,
+			expectedError: "/bindings: not found",
+		},
+		"rejected by the app not retried": {
+			creq:          apps.CallRequest{Call: apps.DefaultBindings},
+			failures:      1,
+			err:           utils.NewForbiddenError("no access"),
+			expectedCalls: 1,
+			expectedError: "no access: forbidden",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			test := &testUpstream{failures: tc.failures, err: tc.err}
+			up := Chain(test, WithRetry(policy))
+			_, err := Call(ctx, up, app, tc.creq)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedCalls, test.calls)
+		})
+	}
+
+	t.Run("static", func(t *testing.T) {
+		test := &testUpstream{failures: 1}
+		up := Chain(test, WithRetry(policy))
+		r, status, err := up.GetStatic(ctx, app, "icon.png")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+		data, _ := io.ReadAll(r)
+		require.Equal(t, "icon", string(data))
+		require.Equal(t, 2, test.calls)
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	app := apps.App{Manifest: apps.Manifest{AppID: "test"}}
+	ctx := context.Background()
+	now := time.Now()
+	breakers := NewBreakers()
+	breakers.now = func() time.Time { return now }
+	policy := BreakerPolicy{FailureThreshold: 3, Cooldown: 10 * time.Second}
+
+	test := &testUpstream{failures: 4, err: errors.New("connection refused")}
+	up := Chain(test, WithCircuitBreaker(breakers, policy))
+	call := func() error {
+		_, err := Call(ctx, up, app, apps.CallRequest{Call: *apps.NewCall("/submit")})
+		return err
+	}
+
+	// Opens after 3 failures, then fails fast.
+	for i := 0; i < 3; i++ {
+		require.EqualError(t, call(), "connection refused")
+	}
+	require.Equal(t, BreakerOpen, breakers.Status("test").State)
+	require.Equal(t, 3, breakers.Status("test").Failures)
+	err := call()
+	require.Equal(t, ErrCircuitOpen, errors.Cause(err))
+	require.Equal(t, 3, test.calls)
+
+	// Notifications are rejected too.
+	err = Notify(ctx, up, app, apps.CallRequest{Call: *apps.NewCall("/notify")})
+	require.Equal(t, ErrCircuitOpen, errors.Cause(err))
+
+	// After the cooldown, the failed trial opens it again.
+	now = now.Add(11 * time.Second)
+	require.EqualError(t, call(), "connection refused")
+	require.Equal(t, BreakerOpen, breakers.Status("test").State)
+	require.Equal(t, ErrCircuitOpen, errors.Cause(call()))
+
+	// A successful trial closes it.
+	now = now.Add(11 * time.Second)
+	require.NoError(t, call())
+	require.Equal(t, BreakerStatus{State: BreakerClosed}, breakers.Status("test"))
+	require.NoError(t, call())
+
+	// Not found is not a failure.
+	test = &testUpstream{failures: 10, err: utils.NewNotFoundError("/submit")}
+	up = Chain(test, WithCircuitBreaker(breakers, policy))
+	for i := 0; i < 5; i++ {
+		require.Error(t, call())
+	}
+	require.Equal(t, BreakerClosed, breakers.Status("test").State)
+
+	// Neither are the app's rejections of the request.
+	test = &testUpstream{failures: 10, err: utils.NewInvalidError("bad request")}
+	up = Chain(test, WithCircuitBreaker(breakers, policy))
+	for i := 0; i < 5; i++ {
+		require.Error(t, call())
+	}
+	require.Equal(t, BreakerClosed, breakers.Status("test").State)
+}
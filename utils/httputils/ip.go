@@ -0,0 +1,91 @@
+package httputils
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const DefaultClientIPHeader = "X-Forwarded-For"
+
+// ParseCIDRs parses a list of CIDR ranges. Plain IP addresses are accepted as
+// single-address ranges.
+func ParseCIDRs(in []string) ([]*net.IPNet, error) {
+	out := []*net.IPNet{}
+	for _, s := range in {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, errors.Errorf("invalid IP address %q", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = 8 * net.IPv4len
+			}
+			out = append(out, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipnet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid CIDR %q", s)
+		}
+		out = append(out, ipnet)
+	}
+	return out, nil
+}
+
+// ContainsIP returns true if ip is in any of the ranges.
+func ContainsIP(ranges []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipnet := range ranges {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP address of the client that sent req. If the request
+// comes from one of the trusted proxies, header (X-Forwarded-For by default)
+// is inspected right to left, and the first address that is not a trusted
+// proxy is returned.
+func ClientIP(req *http.Request, trustedProxies []*net.IPNet, header string) net.IP {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ContainsIP(trustedProxies, ip) {
+		return ip
+	}
+
+	if header == "" {
+		header = DefaultClientIPHeader
+	}
+	var hops []string
+	for _, v := range req.Header.Values(header) {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// A malformed entry can not be trusted, neither can anything
+			// to the left of it.
+			return ip
+		}
+		ip = hop
+		if !ContainsIP(trustedProxies, hop) {
+			return hop
+		}
+	}
+	return ip
+}
@@ -0,0 +1,83 @@
+package httputils
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	ranges, err := ParseCIDRs([]string{"10.0.0.0/8", " 192.168.1.1 ", "", "2001:db8::/32"})
+	require.NoError(t, err)
+	require.Len(t, ranges, 3)
+	require.Equal(t, "192.168.1.1/32", ranges[1].String())
+
+	_, err = ParseCIDRs([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+	_, err = ParseCIDRs([]string{"not-an-ip"})
+	require.Error(t, err)
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		remoteAddr string
+		header     string
+		xff        []string
+		expected   string
+	}{
+		{
+			name:       "direct",
+			remoteAddr: "203.0.113.5:1234",
+			expected:   "203.0.113.5",
+		},
+		{
+			name:       "untrusted peer, header ignored",
+			remoteAddr: "203.0.113.5:1234",
+			xff:        []string{"198.51.100.1"},
+			expected:   "203.0.113.5",
+		},
+		{
+			name:       "trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"198.51.100.1"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "chain of trusted proxies, spoofed leftmost",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"1.1.1.1, 198.51.100.1", "10.0.0.2"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "custom header",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "X-Real-Ip",
+			xff:        []string{"198.51.100.1"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "trusted proxy, no header",
+			remoteAddr: "10.0.0.1:1234",
+			expected:   "10.0.0.1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/", nil)
+			require.NoError(t, err)
+			req.RemoteAddr = tc.remoteAddr
+			h := tc.header
+			if h == "" {
+				h = DefaultClientIPHeader
+			}
+			for _, v := range tc.xff {
+				req.Header.Add(h, v)
+			}
+			require.Equal(t, tc.expected, ClientIP(req, trusted, tc.header).String())
+		})
+	}
+}
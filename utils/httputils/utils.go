@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"path"
@@ -108,6 +109,25 @@ func DoHandleJSON(v interface{}) http.HandlerFunc {
 	}
 }
 
+// PassiveContentType returns ct, or "text/plain" if browsers may render ct as
+// an active document (HTML, XML) with scripts. It is used for the content that
+// apps serve from the Mattermost origin.
+func PassiveContentType(ct string) string {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return "text/plain"
+	}
+	switch {
+	case mediaType == "text/html",
+		mediaType == "text/xml",
+		mediaType == "text/xsl",
+		mediaType == "application/xml",
+		mediaType == "application/xhtml+xml":
+		return "text/plain"
+	}
+	return ct
+}
+
 const InLimit = 10 * (1 << 20)
 
 func ReadAndClose(in io.ReadCloser) ([]byte, error) {
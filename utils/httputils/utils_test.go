@@ -51,3 +51,21 @@ func TestNormalizeRemoteBaseURL(t *testing.T) {
 		})
 	}
 }
+
+func TestPassiveContentType(t *testing.T) {
+	for in, expected := range map[string]string{
+		"image/png":                 "image/png",
+		"image/svg+xml":             "image/svg+xml",
+		"application/json":          "application/json",
+		"text/plain; charset=utf-8": "text/plain; charset=utf-8",
+		"text/html":                 "text/plain",
+		"TEXT/HTML; charset=utf-8":  "text/plain",
+		"application/xhtml+xml":     "text/plain",
+		"text/xml":                  "text/plain",
+		"invalid/;;":                "text/plain",
+	} {
+		t.Run(in, func(t *testing.T) {
+			require.Equal(t, expected, PassiveContentType(in))
+		})
+	}
+}
@@ -35,7 +35,7 @@ func CleanPath(p string) (string, error) {
 	}
 
 	cleanPath := path.Clean(p)
-	if cleanPath == "." || strings.HasPrefix(cleanPath, "../") {
+	if cleanPath == "." || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
 		return "", NewInvalidError("bad path: %q", p)
 	}
 
@@ -63,6 +63,31 @@ func cleanURLPath(got string) (unescaped string, err error) {
 	return cleanPath, nil
 }
 
+// CleanStaticPath cleans the path of a static asset, which may be nested, e.g.
+// "img/logo.png". The returned path is relative to the app's static folder.
+// Paths that escape the static folder are rejected.
+func CleanStaticPath(got string) (string, error) {
+	cleanPath, err := cleanURLPath(got)
+	if err != nil {
+		return "", err
+	}
+	cleanPath = strings.TrimPrefix(cleanPath, "/")
+	if cleanPath == "" {
+		return "", NewInvalidError("bad static asset path: %q", got)
+	}
+	return cleanPath, nil
+}
+
+// EscapePath escapes each of the segments of a slash-separated path, so that it
+// can be used in a URL.
+func EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
 func CleanStaticURL(got string) (unescaped string, err error) {
 	u, err := url.Parse(got)
 	if err != nil {
@@ -68,3 +68,61 @@ func TestCleanStaticURL(t *testing.T) {
 		})
 	}
 }
+
+func TestCleanStaticPath(t *testing.T) {
+	for _, tc := range []struct {
+		p             string
+		expectedError string
+		expected      string
+	}{
+		{
+			p:        `logo.png`,
+			expected: `logo.png`,
+		}, {
+			p:        `img/logo.png`,
+			expected: `img/logo.png`,
+		}, {
+			p:        `/web//js/./app.js`,
+			expected: `web/js/app.js`,
+		}, {
+			p:        `img/../logo.png`,
+			expected: `logo.png`,
+		}, {
+			p:        `img%2Flogo%20big.png`,
+			expected: `img/logo big.png`,
+		}, {
+			p:        `/../manifest.json`,
+			expected: `manifest.json`,
+		}, {
+			p:             `img/../../manifest.json`,
+			expectedError: `bad path: "img/../../manifest.json": invalid input`,
+		}, {
+			p:             `..`,
+			expectedError: `bad path: "..": invalid input`,
+		}, {
+			p:             `img%252F..%252F..%252Fmanifest.json`,
+			expectedError: `bad path: "img/../../manifest.json": invalid input`,
+		}, {
+			p:             `/`,
+			expectedError: `bad static asset path: "/": invalid input`,
+		}, {
+			p:             ``,
+			expectedError: `empty path: invalid input`,
+		},
+	} {
+		t.Run(tc.p, func(t *testing.T) {
+			c, err := CleanStaticPath(tc.p)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, c)
+			}
+		})
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	require.Equal(t, "img/logo%20big.png", EscapePath("img/logo big.png"))
+	require.Equal(t, "a%3Fb/c%23d", EscapePath("a?b/c#d"))
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+const (
+	// ExporterOTLP exports the spans with the OTLP/HTTP protocol.
+	ExporterOTLP = "otlp"
+
+	// ExporterFile appends the spans as JSON to a local file.
+	ExporterFile = "file"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Options configure the export of the spans.
+type Options struct {
+	// Exporter is ExporterOTLP, ExporterFile, or empty to disable tracing.
+	Exporter string
+
+	// OTLPEndpoint is the URL of the OTLP/HTTP collector, e.g.
+	// http://localhost:4318. The default path is /v1/traces.
+	OTLPEndpoint string
+
+	// File is the path of the file to append the spans to.
+	File string
+
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Provider installs the global OpenTelemetry tracer provider, and replaces it
+// when the options change.
+type Provider struct {
+	mutex    sync.Mutex
+	options  Options
+	provider *sdktrace.TracerProvider
+	file     *os.File
+}
+
+func NewProvider() *Provider {
+	otel.SetTextMapPropagator(propagator)
+	return &Provider{}
+}
+
+// Configure applies the options, if they changed since the last call.
+func (p *Provider) Configure(opts Options, log utils.Logger) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if opts == p.options {
+		return nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var file *os.File
+	var err error
+	switch opts.Exporter {
+	case "":
+	case ExporterOTLP:
+		exporter, err = newOTLPExporter(opts.OTLPEndpoint)
+	case ExporterFile:
+		if opts.File == "" {
+			return utils.NewInvalidError("tracing file must be set")
+		}
+		file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err == nil {
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		}
+	default:
+		return utils.NewInvalidError("invalid tracing exporter %q, must be %s or %s", opts.Exporter, ExporterOTLP, ExporterFile)
+	}
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return errors.Wrapf(err, "failed to create %s tracing exporter", opts.Exporter)
+	}
+
+	p.shutdownLocked(log)
+	p.options = opts
+	if exporter == nil {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		log.Debugf("disabled tracing")
+		return nil
+	}
+
+	p.file = file
+	p.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(sdkresource.NewSchemaless(
+			semconv.ServiceName(opts.ServiceName),
+			semconv.ServiceVersion(opts.ServiceVersion),
+		)),
+	)
+	otel.SetTracerProvider(p.provider)
+	log.Debugw("enabled tracing", "exporter", opts.Exporter)
+	return nil
+}
+
+// Shutdown flushes the pending spans, and disables tracing.
+func (p *Provider) Shutdown(log utils.Logger) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.shutdownLocked(log)
+	p.options = Options{}
+	otel.SetTracerProvider(trace.NewNoopTracerProvider())
+}
+
+func (p *Provider) shutdownLocked(log utils.Logger) {
+	if p.provider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := p.provider.Shutdown(ctx); err != nil {
+			log.WithError(err).Warnf("failed to flush the traces")
+		}
+		p.provider = nil
+	}
+	if p.file != nil {
+		p.file.Close()
+		p.file = nil
+	}
+}
+
+func newOTLPExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, utils.NewInvalidError("invalid OTLP endpoint %q, must be a URL", endpoint)
+	}
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+	}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+	return otlptracehttp.New(context.Background(), opts...)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-apps/utils"
+)
+
+// exportedSpan is the part of the stdouttrace JSON output checked by the tests.
+type exportedSpan struct {
+	Name        string
+	SpanContext struct{ TraceID string }
+	Parent      struct{ SpanID string }
+	Status      struct{ Code string }
+}
+
+func TestProviderFileExporter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.json")
+	p := NewProvider()
+	log := utils.NewTestLogger()
+	err := p.Configure(Options{
+		Exporter:    ExporterFile,
+		File:        file,
+		ServiceName: "test",
+	}, log)
+	require.NoError(t, err)
+
+	ctx, parent := Start(context.Background(), "parent", AppIDKey.String("app1"))
+	_, child := Start(ctx, "child")
+	End(child, utils.NewNotFoundError("test"))
+	End(parent, nil)
+
+	// Shutdown flushes the spans.
+	p.Shutdown(log)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var spans []exportedSpan
+	for dec.More() {
+		var span exportedSpan
+		require.NoError(t, dec.Decode(&span))
+		spans = append(spans, span)
+	}
+	require.Len(t, spans, 2)
+	require.Equal(t, "child", spans[0].Name)
+	require.Equal(t, "Error", spans[0].Status.Code)
+	require.Equal(t, "parent", spans[1].Name)
+	require.Equal(t, spans[0].SpanContext.TraceID, spans[1].SpanContext.TraceID)
+	require.Equal(t, parent.SpanContext().SpanID().String(), spans[0].Parent.SpanID)
+}
+
+func TestProviderOptions(t *testing.T) {
+	p := NewProvider()
+	log := utils.NewTestLogger()
+	defer p.Shutdown(log)
+
+	require.Error(t, p.Configure(Options{Exporter: "zipkin"}, log))
+	require.Error(t, p.Configure(Options{Exporter: ExporterFile}, log))
+	require.Error(t, p.Configure(Options{Exporter: ExporterOTLP, OTLPEndpoint: "localhost"}, log))
+	require.NoError(t, p.Configure(Options{Exporter: ExporterOTLP, OTLPEndpoint: "http://localhost:4318"}, log))
+	require.NoError(t, p.Configure(Options{}, log))
+
+	// Without an exporter, spans are no-ops, and nothing is propagated.
+	ctx, span := Start(context.Background(), "noop")
+	require.False(t, span.SpanContext().IsValid())
+	header := http.Header{}
+	Inject(ctx, header)
+	require.Empty(t, header.Get("traceparent"))
+}
+
+func TestPropagation(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := Extract(context.Background(), header)
+
+	out := http.Header{}
+	Inject(ctx, out)
+	require.Equal(t, header.Get("traceparent"), out.Get("traceparent"))
+}
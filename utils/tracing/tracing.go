@@ -0,0 +1,58 @@
+// Copyright (c) 2023-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// Package tracing instruments the Apps framework with OpenTelemetry spans, and
+// propagates the trace context to the apps in the W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies the spans created by the Apps framework.
+const InstrumentationName = "github.com/mattermost/mattermost-plugin-apps"
+
+// Span attribute keys.
+const (
+	AppIDKey      = attribute.Key("apps.app_id")
+	AsyncKey      = attribute.Key("apps.call.async")
+	CallPathKey   = attribute.Key("apps.call.path")
+	DeployTypeKey = attribute.Key("apps.deploy_type")
+	RequestIDKey  = attribute.Key("apps.request_id")
+	StaticPathKey = attribute.Key("apps.static.path")
+)
+
+var propagator = propagation.TraceContext{}
+
+// Start creates a span, and a context containing it. If tracing is not
+// configured, the span is a no-op.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording err if it is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject sets the traceparent header for the span in ctx, if any.
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract returns a context with the remote span from the traceparent header,
+// if any.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}